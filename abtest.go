@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// rankArmA and rankArmB name the two arms a RankExperiment splits search
+// traffic between: the service's default ranking configuration, and the
+// RankExperiment.ArmB alternate being evaluated against it.
+const (
+	rankArmA = "a"
+	rankArmB = "b"
+)
+
+// abArmCounts accumulates one arm's assignment count and its outcomes
+// (e.g. "click", "positive_feedback"), keyed by an outcome name a caller
+// chooses when reporting it.
+type abArmCounts struct {
+	Assignments int
+	Outcomes    map[string]int
+}
+
+// abExperiment assigns each search sticky (by API key) to a ranking arm
+// and tracks how many searches and outcomes each arm has seen, so a
+// ranking change can be evaluated with real traffic before it replaces
+// the default for everyone. Assignment and outcome counts are in-memory
+// only and reset on restart, and aren't part of the /admin/export state
+// bundle, matching usageTracker's precedent that this kind of activity
+// history is informational rather than state a restart should restore.
+type abExperiment struct {
+	mu      sync.Mutex
+	enabled bool
+	armB    rankConfig
+	counts  map[string]*abArmCounts
+}
+
+// newABExperiment returns an abExperiment with no arm configured yet;
+// Reconfigure must be called (as newSearcher does) before Assign is
+// meaningful.
+func newABExperiment() *abExperiment {
+	return &abExperiment{counts: map[string]*abArmCounts{
+		rankArmA: {Outcomes: map[string]int{}},
+		rankArmB: {Outcomes: map[string]int{}},
+	}}
+}
+
+// Reconfigure recompiles arm b's ranking rules from cfg.RankExperiment,
+// called on every config reload; existing assignment and outcome counts
+// are kept.
+func (e *abExperiment) Reconfigure(cfg Config) error {
+	armB, err := compileRankConfig(Config{
+		RecencyHalfLife: cfg.RankExperiment.ArmB.RecencyHalfLife,
+		PathPenalties:   cfg.RankExperiment.ArmB.PathPenalties,
+	})
+	if err != nil {
+		return fmt.Errorf("rank_experiment arm_b: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enabled = cfg.RankExperiment.Enabled
+	e.armB = armB
+	return nil
+}
+
+// Assign deterministically and stickily maps apiKey to rankArmA or
+// rankArmB, so repeated searches from the same caller always land in
+// the same arm; unlabeled callers (apiKey == "") are likewise split by
+// the same hash, so they still land in a consistent (if not
+// individually identifiable) arm. Always returns rankArmA when the
+// experiment is disabled.
+func (e *abExperiment) Assign(apiKey string) string {
+	e.mu.Lock()
+	enabled := e.enabled
+	e.mu.Unlock()
+	if !enabled {
+		return rankArmA
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(apiKey))
+	if h.Sum32()%2 == 0 {
+		return rankArmA
+	}
+	return rankArmB
+}
+
+// RankConfigFor returns the rankConfig arm should score results with.
+// defaultRank is the caller's compiled top-level ranking configuration,
+// used for rankArmA.
+func (e *abExperiment) RankConfigFor(arm string, defaultRank rankConfig) rankConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if arm == rankArmB {
+		return e.armB
+	}
+	return defaultRank
+}
+
+// RecordAssignment counts one search as served by arm.
+func (e *abExperiment) RecordAssignment(arm string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.counts[arm].Assignments++
+}
+
+// RecordOutcome counts one outcome (e.g. a click-through or piece of
+// feedback) against arm, so /admin/ab_test can compare how each arm's
+// assignments converted. Returns an error if arm isn't rankArmA or
+// rankArmB.
+func (e *abExperiment) RecordOutcome(arm, outcome string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	counts, ok := e.counts[arm]
+	if !ok {
+		return fmt.Errorf("unknown rank experiment arm: %q", arm)
+	}
+	counts.Outcomes[outcome]++
+	return nil
+}
+
+// ABArmReport is one arm's totals in an ABTestReport.
+type ABArmReport struct {
+	Assignments int            `json:"assignments"`
+	Outcomes    map[string]int `json:"outcomes"`
+}
+
+// ABTestReport is the /admin/ab_test response body.
+type ABTestReport struct {
+	Enabled bool                   `json:"enabled"`
+	Arms    map[string]ABArmReport `json:"arms"`
+}
+
+// Report builds an ABTestReport of everything recorded so far.
+func (e *abExperiment) Report() ABTestReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	arms := make(map[string]ABArmReport, len(e.counts))
+	for arm, c := range e.counts {
+		outcomes := make(map[string]int, len(c.Outcomes))
+		for name, n := range c.Outcomes {
+			outcomes[name] = n
+		}
+		arms[arm] = ABArmReport{Assignments: c.Assignments, Outcomes: outcomes}
+	}
+	return ABTestReport{Enabled: e.enabled, Arms: arms}
+}
@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestABExperimentAssignDefaultsToArmAWhenDisabled(t *testing.T) {
+	ab := newABExperiment()
+	if err := ab.Reconfigure(Config{RankExperiment: RankExperiment{Enabled: false}}); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+
+	if arm := ab.Assign("some-key"); arm != rankArmA {
+		t.Errorf("Assign() = %q, want %q when disabled", arm, rankArmA)
+	}
+}
+
+func TestABExperimentAssignIsStickyPerAPIKey(t *testing.T) {
+	ab := newABExperiment()
+	if err := ab.Reconfigure(Config{RankExperiment: RankExperiment{Enabled: true}}); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+
+	first := ab.Assign("caller-a")
+	for i := 0; i < 5; i++ {
+		if got := ab.Assign("caller-a"); got != first {
+			t.Fatalf("Assign(caller-a) = %q on repeat call, want sticky %q", got, first)
+		}
+	}
+}
+
+func TestABExperimentRecordAssignmentAndOutcome(t *testing.T) {
+	ab := newABExperiment()
+	if err := ab.Reconfigure(Config{RankExperiment: RankExperiment{Enabled: true}}); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+
+	ab.RecordAssignment(rankArmA)
+	ab.RecordAssignment(rankArmA)
+	if err := ab.RecordOutcome(rankArmA, "click"); err != nil {
+		t.Fatalf("RecordOutcome: %v", err)
+	}
+
+	report := ab.Report()
+	if !report.Enabled {
+		t.Error("expected report.Enabled to be true")
+	}
+	if got := report.Arms[rankArmA].Assignments; got != 2 {
+		t.Errorf("Arms[a].Assignments = %d, want 2", got)
+	}
+	if got := report.Arms[rankArmA].Outcomes["click"]; got != 1 {
+		t.Errorf("Arms[a].Outcomes[click] = %d, want 1", got)
+	}
+}
+
+func TestABExperimentRecordOutcomeRejectsUnknownArm(t *testing.T) {
+	ab := newABExperiment()
+	if err := ab.RecordOutcome("c", "click"); err == nil {
+		t.Fatal("expected an error for an unknown arm")
+	}
+}
+
+func TestABExperimentReconfigureRejectsInvalidArmBRecencyHalfLife(t *testing.T) {
+	ab := newABExperiment()
+	cfg := Config{RankExperiment: RankExperiment{
+		Enabled: true,
+		ArmB:    RankArmConfig{RecencyHalfLife: "not-a-duration"},
+	}}
+	if err := ab.Reconfigure(cfg); err == nil {
+		t.Fatal("expected an error for an invalid arm_b recency_half_life")
+	}
+}
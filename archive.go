@@ -0,0 +1,117 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// defaultArchiveExtensions lists the archive file extensions indexed by
+// default when Config.ArchiveExtensions is unset.
+var defaultArchiveExtensions = []string{".zip", ".tar", ".tar.gz", ".tgz"}
+
+// defaultArchiveMaxBytes bounds how large an archive member is read
+// into memory for indexing, by default.
+const defaultArchiveMaxBytes = 10 << 20 // 10MiB
+
+// archiveEntry is one file extracted from an archive for indexing.
+type archiveEntry struct {
+	Path string // inner path, joined with the archive's path via "!" for results
+	Data []byte
+}
+
+// isArchive reports whether path has one of the configured archive
+// extensions.
+func isArchive(path string, extensions []string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range extensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractArchive reads every member of the zip or tar(.gz) archive in
+// data, skipping any member larger than maxBytes.
+func extractArchive(path string, data []byte, maxBytes int64) ([]archiveEntry, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return extractZip(data, maxBytes)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		return extractTar(gz, maxBytes)
+	case strings.HasSuffix(lower, ".tar"):
+		return extractTar(bytes.NewReader(data), maxBytes)
+	default:
+		return nil, nil
+	}
+}
+
+func extractZip(data []byte, maxBytes int64) ([]archiveEntry, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []archiveEntry
+	seen := newCaseFolder()
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || int64(f.UncompressedSize64) > maxBytes {
+			continue
+		}
+		safe, err := safeRelPath(f.Name)
+		if err != nil || seen.collides(filepath.ToSlash(safe)) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxBytes))
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, archiveEntry{Path: filepath.ToSlash(safe), Data: content})
+	}
+	return entries, nil
+}
+
+func extractTar(r io.Reader, maxBytes int64) ([]archiveEntry, error) {
+	tr := tar.NewReader(r)
+
+	var entries []archiveEntry
+	seen := newCaseFolder()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > maxBytes {
+			continue
+		}
+		safe, err := safeRelPath(hdr.Name)
+		if err != nil || seen.collides(filepath.ToSlash(safe)) {
+			continue
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, maxBytes))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, archiveEntry{Path: filepath.ToSlash(safe), Data: content})
+	}
+	return entries, nil
+}
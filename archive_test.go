@@ -0,0 +1,46 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchTextInsideZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create("inner/notes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("here is a needle in a haystack")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+	repoDir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "assets.zip"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	results, err := idx.searchText(repo, "needle")
+	if err != nil {
+		t.Fatalf("searchText: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].Path != "assets.zip!inner/notes.txt" {
+		t.Errorf("Path = %q, want assets.zip!inner/notes.txt", results[0].Path)
+	}
+}
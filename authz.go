@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Roles an API key can be assigned via Config.Roles, ordered from least
+// to most privileged. Each tier includes everything the tier below it
+// can do.
+const (
+	roleReader  = "reader"  // GET /search* and the read-only integrations
+	roleCurator = "curator" // + creating short links and REST hook subscriptions
+	roleAdmin   = "admin"   // + every /admin/ endpoint
+)
+
+// roleRank orders roles for a ">=" comparison, so authzMiddleware can
+// check "does this caller's role meet or exceed what this endpoint
+// requires" with a single integer compare. -1 means role isn't one of
+// the three recognized values.
+func roleRank(role string) int {
+	switch role {
+	case roleReader:
+		return 0
+	case roleCurator:
+		return 1
+	case roleAdmin:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// validateRoles rejects a Config.Roles map that assigns an API key to
+// anything other than one of the three recognized roles, at config
+// compile time rather than at the first request that hits it.
+func validateRoles(roles map[string]string) error {
+	for apiKey, role := range roles {
+		if roleRank(role) == -1 {
+			return fmt.Errorf("roles: api key %q has unknown role %q (want reader, curator, or admin)", apiKey, role)
+		}
+	}
+	return nil
+}
+
+// requiredRole reports the minimum role an endpoint needs: admin for
+// the whole /admin/ surface, curator for creating a short link or REST
+// hook subscription (this service's closest thing to "saved search
+// management"), and reader for everything else, including following an
+// existing short link and running a search.
+func requiredRole(method, path string) string {
+	if strings.HasPrefix(path, "/admin/") {
+		return roleAdmin
+	}
+	if method == http.MethodPost && (path == "/r" || path == "/hooks/subscribe") {
+		return roleCurator
+	}
+	return roleReader
+}
+
+// authzMiddleware enforces requiredRole per endpoint against the
+// caller's role, resolved from Config.Roles by API key (see
+// apiKeyFromRequest). loadRoles returning nil or an empty map disables
+// enforcement entirely and lets every request through, matching this
+// service's existing all-or-nothing model — role enforcement is opt-in
+// by configuring at least one entry in roles. A caller with no entry in
+// roles defaults to reader, the least-privileged tier, once enforcement
+// is on.
+func authzMiddleware(loadRoles func() map[string]string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roles := loadRoles()
+		if len(roles) == 0 {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		role, ok := roles[apiKeyFromRequest(r)]
+		if !ok {
+			role = roleReader
+		}
+		if roleRank(role) < roleRank(requiredRole(r.Method, r.URL.Path)) {
+			http.Error(w, "forbidden: this endpoint requires a higher role than your api key has", http.StatusForbidden)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
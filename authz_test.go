@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateRolesAcceptsKnownRoles(t *testing.T) {
+	err := validateRoles(map[string]string{"a": roleReader, "b": roleCurator, "c": roleAdmin})
+	if err != nil {
+		t.Errorf("validateRoles: %v", err)
+	}
+}
+
+func TestValidateRolesRejectsUnknownRole(t *testing.T) {
+	if err := validateRoles(map[string]string{"a": "superuser"}); err == nil {
+		t.Error("expected an error for an unrecognized role")
+	}
+}
+
+func TestRequiredRole(t *testing.T) {
+	cases := []struct {
+		method, path string
+		want         string
+	}{
+		{http.MethodGet, "/search", roleReader},
+		{http.MethodGet, "/admin/usage", roleAdmin},
+		{http.MethodPost, "/admin/purge", roleAdmin},
+		{http.MethodPost, "/r", roleCurator},
+		{http.MethodGet, "/r/abc123", roleReader},
+		{http.MethodPost, "/hooks/subscribe", roleCurator},
+	}
+	for _, c := range cases {
+		if got := requiredRole(c.method, c.path); got != c.want {
+			t.Errorf("requiredRole(%s, %s) = %q, want %q", c.method, c.path, got, c.want)
+		}
+	}
+}
+
+func TestAuthzMiddlewareDisabledWhenNoRolesConfigured(t *testing.T) {
+	handler := authzMiddleware(func() map[string]string { return nil }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/usage", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAuthzMiddlewareBlocksReaderFromAdmin(t *testing.T) {
+	roles := map[string]string{"key1": roleReader}
+	handler := authzMiddleware(func() map[string]string { return roles }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/usage", nil)
+	req.Header.Set(apiKeyHeader, "key1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestAuthzMiddlewareAllowsAdminFromAdminKey(t *testing.T) {
+	roles := map[string]string{"key1": roleAdmin}
+	handler := authzMiddleware(func() map[string]string { return roles }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/usage", nil)
+	req.Header.Set(apiKeyHeader, "key1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAuthzMiddlewareUnknownKeyDefaultsToReader(t *testing.T) {
+	roles := map[string]string{"key1": roleAdmin}
+	handler := authzMiddleware(func() map[string]string { return roles }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d: unlabeled callers should still reach reader-tier endpoints", w.Code, http.StatusOK)
+	}
+}
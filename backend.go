@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// backendGithub, backendGitlab, backendBitbucket, and backendGitea are
+// the supported values for RepoBackend.Kind. Empty defaults to
+// backendGithub.
+const (
+	backendGithub    = "github"
+	backendGitlab    = "gitlab"
+	backendBitbucket = "bitbucket"
+	backendGitea     = "gitea"
+)
+
+// RepoBackend overrides which code search backend, API base URL, and
+// credential a specific repo uses, so a repo hosted somewhere other
+// than github.com (e.g. a self-hosted GitLab) can be searched alongside
+// the rest.
+type RepoBackend struct {
+	Repo string `yaml:"repo"`
+
+	// Kind selects the backend implementation: "github" (the default),
+	// "gitlab", "bitbucket", or "gitea" (also used for Forgejo, which
+	// shares Gitea's API).
+	Kind string `yaml:"kind"`
+
+	// BaseURL overrides the backend's default API endpoint, e.g. for a
+	// GitHub Enterprise or self-hosted GitLab instance.
+	BaseURL string `yaml:"base_url"`
+
+	// Token authenticates requests to this backend, sent as an
+	// Authorization header in the format the backend expects.
+	Token string `yaml:"token"`
+
+	// FallbackToDefault routes searches to this backend first, but falls
+	// back to the default GitHub client for this repo if the request
+	// fails, e.g. when Kind names an internal GitHub mirror that isn't
+	// always reachable.
+	FallbackToDefault bool `yaml:"fallback_to_default"`
+}
+
+// codeSearchClient runs a code search scoped to one repo and returns
+// results in the shared githubSearchItem shape, regardless of which
+// backend actually served the request. page and perPage are forwarded to
+// the backend's own pagination; the returned int is that backend's
+// reported total match count for repo, independent of how many items
+// were returned for this page.
+type codeSearchClient interface {
+	searchCode(term, repo string, page, perPage int) ([]githubSearchItem, int, error)
+}
+
+// multiRepoSearchClient is implemented by backends that can search
+// several repos in a single request via repeated qualifiers (currently
+// only githubClient), letting Search batch many default-backend repos
+// into fewer, larger requests instead of one per repo.
+type multiRepoSearchClient interface {
+	searchCodeMulti(term string, repos []string, page, perPage int) ([]githubSearchItem, int, error)
+}
+
+// searchChunksConcurrently splits repos into query-length-bounded chunks
+// (see chunkReposForQuery) and runs one multi.searchCodeMulti request
+// per chunk concurrently, merging their items and summing their
+// reported total counts. It returns the first error encountered, if
+// any; the other in-flight chunks are still allowed to finish.
+func searchChunksConcurrently(multi multiRepoSearchClient, term string, repos []string, page, perPage int) ([]githubSearchItem, int, error) {
+	chunks := chunkReposForQuery(term, repos)
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		items    []githubSearchItem
+		total    int
+		firstErr error
+	)
+	for _, chunk := range chunks {
+		wg.Add(1)
+		go func(chunk []string) {
+			defer wg.Done()
+			chunkItems, chunkTotal, err := multi.searchCodeMulti(term, chunk, page, perPage)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			items = append(items, chunkItems...)
+			total += chunkTotal
+		}(chunk)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, 0, firstErr
+	}
+	return items, total, nil
+}
+
+// newBackendClient builds the codeSearchClient rb.Kind calls for.
+func newBackendClient(rb RepoBackend) (codeSearchClient, error) {
+	switch rb.Kind {
+	case "", backendGithub:
+		return newGithubClientFor(rb.BaseURL, rb.Token), nil
+	case backendGitlab:
+		return newGitlabClientFor(rb.BaseURL, rb.Token), nil
+	case backendBitbucket:
+		return newBitbucketClientFor(rb.BaseURL, rb.Token), nil
+	case backendGitea:
+		return newGiteaClientFor(rb.BaseURL, rb.Token), nil
+	default:
+		return nil, fmt.Errorf("unsupported repo backend kind: %q", rb.Kind)
+	}
+}
+
+// backendRouter picks the codeSearchClient a repo should be searched
+// with: its RepoBackend override if one is configured, or the default
+// GitHub client otherwise.
+type backendRouter struct {
+	def        codeSearchClient
+	byRepo     map[string]codeSearchClient
+	kindByRepo map[string]string
+}
+
+// newBackendRouter builds a backendRouter whose default client (for
+// repos not named in overrides) queries defaultBaseURL (empty for
+// github.com's public API, e.g. a GHE instance's own endpoint) and
+// authenticates with defaultToken.
+func newBackendRouter(overrides []RepoBackend, defaultBaseURL, defaultToken string) (*backendRouter, error) {
+	router := &backendRouter{
+		def:        newGithubClientFor(defaultBaseURL, defaultToken),
+		byRepo:     map[string]codeSearchClient{},
+		kindByRepo: map[string]string{},
+	}
+	for _, rb := range overrides {
+		client, err := newBackendClient(rb)
+		if err != nil {
+			return nil, fmt.Errorf("repo backend for %s: %w", rb.Repo, err)
+		}
+		if rb.FallbackToDefault {
+			client = &fallbackSearchClient{repo: rb.Repo, primary: client, fallback: router.def}
+		}
+		router.byRepo[rb.Repo] = client
+
+		kind := rb.Kind
+		if kind == "" {
+			kind = backendGithub
+		}
+		router.kindByRepo[rb.Repo] = kind
+	}
+	return router, nil
+}
+
+// clientFor returns the codeSearchClient repo should be searched with.
+func (r *backendRouter) clientFor(repo string) codeSearchClient {
+	if client, ok := r.byRepo[repo]; ok {
+		return client
+	}
+	return r.def
+}
+
+// kindFor returns the provider kind repo is searched with: its
+// RepoBackend override's Kind, or backendGithub for the default client.
+func (r *backendRouter) kindFor(repo string) string {
+	if kind, ok := r.kindByRepo[repo]; ok {
+		return kind
+	}
+	return backendGithub
+}
+
+// fallbackSearchClient searches primary first and, if that fails, retries
+// against fallback, so a repo mirrored onto an internal backend (for
+// lower latency or to stay off github.com rate limits) still gets
+// results if the mirror is unreachable.
+type fallbackSearchClient struct {
+	repo     string
+	primary  codeSearchClient
+	fallback codeSearchClient
+}
+
+func (c *fallbackSearchClient) searchCode(term, repo string, page, perPage int) ([]githubSearchItem, int, error) {
+	items, total, err := c.primary.searchCode(term, repo, page, perPage)
+	if err == nil {
+		return items, total, nil
+	}
+
+	log.Printf("backend: primary search for %s failed, falling back: %v", c.repo, err)
+	return c.fallback.searchCode(term, repo, page, perPage)
+}
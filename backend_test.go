@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+type stubSearchClient struct {
+	items   []githubSearchItem
+	err     error
+	gotTerm string
+}
+
+func (c *stubSearchClient) searchCode(term, repo string, page, perPage int) ([]githubSearchItem, int, error) {
+	c.gotTerm = term
+	return c.items, len(c.items), c.err
+}
+
+type stubMultiSearchClient struct {
+	itemsByChunkSize map[int][]githubSearchItem
+}
+
+func (c *stubMultiSearchClient) searchCodeMulti(term string, repos []string, page, perPage int) ([]githubSearchItem, int, error) {
+	items := c.itemsByChunkSize[len(repos)]
+	return items, len(items), nil
+}
+
+func TestSearchChunksConcurrentlyMergesEveryChunk(t *testing.T) {
+	multi := &stubMultiSearchClient{itemsByChunkSize: map[int][]githubSearchItem{
+		1: {{Name: "solo.go"}},
+	}}
+
+	var repos []string
+	for i := 0; i < 3; i++ {
+		repos = append(repos, "bjatkin/repo-"+string(rune('a'+i)))
+	}
+
+	items, total, err := searchChunksConcurrently(multi, string(make([]byte, githubMaxQueryLen)), repos, 0, 0)
+	if err != nil {
+		t.Fatalf("searchChunksConcurrently: %v", err)
+	}
+	if len(items) != 3 || total != 3 {
+		t.Errorf("items = %+v, total = %d, want 3 items (one per repo's own chunk) and total 3", items, total)
+	}
+}
+
+func TestBackendRouterClientForDefaultsToGithub(t *testing.T) {
+	router, err := newBackendRouter(nil, "", "")
+	if err != nil {
+		t.Fatalf("newBackendRouter: %v", err)
+	}
+
+	client := router.clientFor("bjatkin/golf-engine")
+	if _, ok := client.(*githubClient); !ok {
+		t.Errorf("expected default client to be a *githubClient, got %T", client)
+	}
+}
+
+func TestBackendRouterClientForUsesDefaultBaseURL(t *testing.T) {
+	router, err := newBackendRouter(nil, "https://ghe.example.com/api/v3/search/code", "")
+	if err != nil {
+		t.Fatalf("newBackendRouter: %v", err)
+	}
+
+	client, ok := router.clientFor("bjatkin/golf-engine").(*githubClient)
+	if !ok {
+		t.Fatalf("expected default client to be a *githubClient, got %T", router.clientFor("bjatkin/golf-engine"))
+	}
+	if client.baseURL != "https://ghe.example.com/api/v3/search/code" {
+		t.Errorf("client.baseURL = %q, want the configured GHE base URL", client.baseURL)
+	}
+}
+
+func TestBackendRouterClientForUsesOverride(t *testing.T) {
+	router, err := newBackendRouter([]RepoBackend{
+		{Repo: "bjatkin/self-hosted", Kind: backendGitlab, BaseURL: "https://gitlab.example.com/api/v4"},
+	}, "", "")
+	if err != nil {
+		t.Fatalf("newBackendRouter: %v", err)
+	}
+
+	client := router.clientFor("bjatkin/self-hosted")
+	if _, ok := client.(*gitlabClient); !ok {
+		t.Errorf("expected overridden client to be a *gitlabClient, got %T", client)
+	}
+
+	if _, ok := router.clientFor("bjatkin/golf-engine").(*githubClient); !ok {
+		t.Error("expected repo without an override to keep using the default github client")
+	}
+}
+
+func TestBackendRouterClientForUsesBitbucketOverride(t *testing.T) {
+	router, err := newBackendRouter([]RepoBackend{
+		{Repo: "myteam/myrepo", Kind: backendBitbucket},
+	}, "", "")
+	if err != nil {
+		t.Fatalf("newBackendRouter: %v", err)
+	}
+
+	client := router.clientFor("myteam/myrepo")
+	if _, ok := client.(*bitbucketClient); !ok {
+		t.Errorf("expected overridden client to be a *bitbucketClient, got %T", client)
+	}
+}
+
+func TestBackendRouterClientForUsesGiteaOverride(t *testing.T) {
+	router, err := newBackendRouter([]RepoBackend{
+		{Repo: "myorg/myrepo", Kind: backendGitea, BaseURL: "https://git.example.com/api/v1"},
+	}, "", "")
+	if err != nil {
+		t.Fatalf("newBackendRouter: %v", err)
+	}
+
+	client := router.clientFor("myorg/myrepo")
+	if _, ok := client.(*giteaClient); !ok {
+		t.Errorf("expected overridden client to be a *giteaClient, got %T", client)
+	}
+}
+
+func TestNewBackendRouterRejectsUnsupportedKind(t *testing.T) {
+	_, err := newBackendRouter([]RepoBackend{{Repo: "bjatkin/mystery", Kind: "sourcehut"}}, "", "")
+	if err == nil {
+		t.Fatal("expected an error for an unsupported backend kind")
+	}
+}
+
+func TestFallbackSearchClientUsesPrimaryOnSuccess(t *testing.T) {
+	primary := &stubSearchClient{items: []githubSearchItem{{Name: "primary.go"}}}
+	fallback := &stubSearchClient{items: []githubSearchItem{{Name: "fallback.go"}}}
+	client := &fallbackSearchClient{repo: "bjatkin/golf-engine", primary: primary, fallback: fallback}
+
+	items, _, err := client.searchCode("term", "bjatkin/golf-engine", 0, 0)
+	if err != nil {
+		t.Fatalf("searchCode: %v", err)
+	}
+	if len(items) != 1 || items[0].Name != "primary.go" {
+		t.Errorf("items = %+v, want primary result", items)
+	}
+}
+
+func TestFallbackSearchClientFallsBackOnError(t *testing.T) {
+	primary := &stubSearchClient{err: errors.New("mirror unreachable")}
+	fallback := &stubSearchClient{items: []githubSearchItem{{Name: "fallback.go"}}}
+	client := &fallbackSearchClient{repo: "bjatkin/golf-engine", primary: primary, fallback: fallback}
+
+	items, _, err := client.searchCode("term", "bjatkin/golf-engine", 0, 0)
+	if err != nil {
+		t.Fatalf("searchCode: %v", err)
+	}
+	if len(items) != 1 || items[0].Name != "fallback.go" {
+		t.Errorf("items = %+v, want fallback result", items)
+	}
+}
+
+func TestNewBackendRouterWrapsFallback(t *testing.T) {
+	router, err := newBackendRouter([]RepoBackend{
+		{Repo: "bjatkin/mirrored", Kind: backendGitlab, BaseURL: "https://gitlab.example.com/api/v4", FallbackToDefault: true},
+	}, "", "")
+	if err != nil {
+		t.Fatalf("newBackendRouter: %v", err)
+	}
+
+	if _, ok := router.clientFor("bjatkin/mirrored").(*fallbackSearchClient); !ok {
+		t.Errorf("expected a *fallbackSearchClient, got %T", router.clientFor("bjatkin/mirrored"))
+	}
+}
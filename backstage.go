@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// parseSourceLocation extracts the "owner/repo" and (optional) in-repo
+// path from a Backstage backstage.io/source-location annotation value,
+// e.g. "url:https://github.com/owner/repo/tree/main/service".
+func parseSourceLocation(loc string) (repo, path string, err error) {
+	rest, ok := strings.CutPrefix(loc, "url:")
+	if !ok {
+		return "", "", fmt.Errorf("unsupported source-location type: %q", loc)
+	}
+
+	u, err := url.Parse(rest)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid source-location url: %w", err)
+	}
+	if u.Host != "github.com" {
+		return "", "", fmt.Errorf("unsupported source-location host: %q", u.Host)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("source-location url missing owner/repo: %q", loc)
+	}
+	repo = parts[0] + "/" + parts[1]
+
+	if len(parts) > 4 && parts[2] == "tree" {
+		path = strings.Join(parts[4:], "/")
+	}
+
+	return repo, path, nil
+}
+
+// BackstageEntity is one search result shaped for the Backstage code
+// search plugin.
+type BackstageEntity struct {
+	Title    string `json:"title"`
+	Location string `json:"location"`
+	Text     string `json:"text"`
+}
+
+// buildBackstageEntities converts results into the shape a Backstage
+// plugin expects, filtering to those under path when it's set.
+func buildBackstageEntities(results []Result, path string) []BackstageEntity {
+	var entities []BackstageEntity
+	for _, r := range results {
+		if path != "" && !strings.HasPrefix(r.Path, path) {
+			continue
+		}
+
+		var text strings.Builder
+		for i, line := range r.Lines {
+			if i > 0 {
+				text.WriteString("\n")
+			}
+			text.WriteString(line.Text)
+		}
+
+		entities = append(entities, BackstageEntity{
+			Title:    r.Path,
+			Location: r.URL,
+			Text:     text.String(),
+		})
+	}
+	return entities
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestParseSourceLocation(t *testing.T) {
+	repo, path, err := parseSourceLocation("url:https://github.com/bjatkin/golf-engine/tree/main/service")
+	if err != nil {
+		t.Fatalf("parseSourceLocation: %v", err)
+	}
+	if repo != "bjatkin/golf-engine" {
+		t.Errorf("repo = %q, want bjatkin/golf-engine", repo)
+	}
+	if path != "service" {
+		t.Errorf("path = %q, want service", path)
+	}
+}
+
+func TestParseSourceLocationNoSubpath(t *testing.T) {
+	repo, path, err := parseSourceLocation("url:https://github.com/bjatkin/golf-engine")
+	if err != nil {
+		t.Fatalf("parseSourceLocation: %v", err)
+	}
+	if repo != "bjatkin/golf-engine" || path != "" {
+		t.Errorf("got repo=%q path=%q, want repo=bjatkin/golf-engine path=\"\"", repo, path)
+	}
+}
+
+func TestParseSourceLocationUnsupportedType(t *testing.T) {
+	_, _, err := parseSourceLocation("git:https://github.com/bjatkin/golf-engine")
+	if err == nil {
+		t.Fatal("expected error for unsupported source-location type")
+	}
+}
+
+func TestParseSourceLocationUnsupportedHost(t *testing.T) {
+	_, _, err := parseSourceLocation("url:https://gitlab.com/bjatkin/golf-engine")
+	if err == nil {
+		t.Fatal("expected error for unsupported source-location host")
+	}
+}
+
+func TestBuildBackstageEntitiesFiltersByPath(t *testing.T) {
+	results := []Result{
+		{Path: "service/main.go", URL: "https://github.com/o/r/blob/main/service/main.go", Lines: []Line{{Text: "func main() {}"}}},
+		{Path: "docs/readme.md", URL: "https://github.com/o/r/blob/main/docs/readme.md", Lines: []Line{{Text: "hello"}}},
+	}
+
+	entities := buildBackstageEntities(results, "service")
+	if len(entities) != 1 {
+		t.Fatalf("expected 1 entity, got %d", len(entities))
+	}
+	if entities[0].Title != "service/main.go" {
+		t.Errorf("Title = %q, want service/main.go", entities[0].Title)
+	}
+}
+
+func TestBuildBackstageEntitiesNoPathFilter(t *testing.T) {
+	results := []Result{
+		{Path: "a.go", URL: "https://github.com/o/r/blob/main/a.go", Lines: []Line{{Text: "a"}}},
+		{Path: "b.go", URL: "https://github.com/o/r/blob/main/b.go", Lines: []Line{{Text: "b"}}},
+	}
+
+	entities := buildBackstageEntities(results, "")
+	if len(entities) != 2 {
+		t.Fatalf("expected 2 entities, got %d", len(entities))
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// stateFileNameInBackup is the name the state file is stored under
+// inside a backup archive, independent of its configured on-disk path,
+// so a backup can be restored into a deployment with a different
+// state_persist_path.
+const stateFileNameInBackup = "state.json"
+
+// localDataDirNameInBackup is the directory prefix local_data_dir's
+// contents are stored under inside a backup archive.
+const localDataDirNameInBackup = "local_data/"
+
+// CreateBackup writes a gzipped tar archive to w containing cfg's
+// persisted state file (if state_persist_path is set) and a snapshot of
+// cfg's local backend clones (if local_data_dir is set), so both can be
+// restored together with RestoreBackup. Uploading the resulting archive
+// to object storage is left to the caller (e.g. piping w through an
+// object storage CLI); this build doesn't vendor an object storage SDK.
+func CreateBackup(cfg Config, w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if cfg.StatePersistPath != "" {
+		if err := addFileToTar(tw, cfg.StatePersistPath, stateFileNameInBackup); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("backup state file: %w", err)
+		}
+	}
+
+	if cfg.LocalDataDir != "" {
+		if err := addDirToTar(tw, cfg.LocalDataDir, localDataDirNameInBackup); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("backup local data dir: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("finalize backup archive: %w", err)
+	}
+	return gz.Close()
+}
+
+// RestoreBackup reads a gzipped tar archive produced by CreateBackup
+// from r and writes its members back to cfg's state_persist_path and
+// local_data_dir, overwriting whatever is already there.
+func RestoreBackup(cfg Config, r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("open backup archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read backup archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		var dest string
+		switch {
+		case header.Name == stateFileNameInBackup:
+			if cfg.StatePersistPath == "" {
+				continue
+			}
+			dest = cfg.StatePersistPath
+		case len(header.Name) > len(localDataDirNameInBackup) && header.Name[:len(localDataDirNameInBackup)] == localDataDirNameInBackup:
+			if cfg.LocalDataDir == "" {
+				continue
+			}
+			safe, err := safeRelPath(header.Name[len(localDataDirNameInBackup):])
+			if err != nil {
+				continue // zip-slip attempt (e.g. "../../etc/cron.d/evil"); skip the entry
+			}
+			dest = filepath.Join(cfg.LocalDataDir, safe)
+		default:
+			continue
+		}
+
+		if err := ensureStateDir(dest); err != nil {
+			return fmt.Errorf("restore %s: %w", header.Name, err)
+		}
+		if err := writeTarEntry(dest, tr); err != nil {
+			return fmt.Errorf("restore %s: %w", header.Name, err)
+		}
+	}
+}
+
+// addFileToTar writes the file at path into tw under name.
+func addFileToTar(tw *tar.Writer, path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(data)), Mode: 0o644}); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// addDirToTar walks dir and writes every regular file into tw, each
+// named prefix+<path relative to dir>.
+func addDirToTar(tw *tar.Writer, dir, prefix string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return addFileToTar(tw, path, prefix+filepath.ToSlash(rel))
+	})
+}
+
+// writeTarEntry writes r's remaining content to a new file at dest,
+// creating or truncating it.
+func writeTarEntry(dest string, r io.Reader) error {
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	srcDir := t.TempDir()
+	statePath := filepath.Join(srcDir, "state.json")
+	if err := os.WriteFile(statePath, []byte(`{"version":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dataDir := filepath.Join(srcDir, "data")
+	if err := os.MkdirAll(filepath.Join(dataDir, "bjatkin", "golf-engine"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "bjatkin", "golf-engine", "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{StatePersistPath: statePath, LocalDataDir: dataDir}
+
+	var archive bytes.Buffer
+	if err := CreateBackup(cfg, &archive); err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+
+	dstDir := t.TempDir()
+	restoreCfg := Config{
+		StatePersistPath: filepath.Join(dstDir, "state.json"),
+		LocalDataDir:     filepath.Join(dstDir, "data"),
+	}
+	if err := RestoreBackup(restoreCfg, bytes.NewReader(archive.Bytes())); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+
+	gotState, err := os.ReadFile(restoreCfg.StatePersistPath)
+	if err != nil {
+		t.Fatalf("read restored state file: %v", err)
+	}
+	if string(gotState) != `{"version":1}` {
+		t.Errorf("restored state file = %q, want %q", gotState, `{"version":1}`)
+	}
+
+	gotSrc, err := os.ReadFile(filepath.Join(restoreCfg.LocalDataDir, "bjatkin", "golf-engine", "main.go"))
+	if err != nil {
+		t.Fatalf("read restored local data file: %v", err)
+	}
+	if string(gotSrc) != "package main\n" {
+		t.Errorf("restored local data file = %q, want %q", gotSrc, "package main\n")
+	}
+}
+
+func TestRestoreBackupRejectsZipSlipEntry(t *testing.T) {
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "data")
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	escapeTarget := filepath.Join(root, "evil")
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	body := []byte("pwned")
+	if err := tw.WriteHeader(&tar.Header{Name: localDataDirNameInBackup + "../evil", Size: int64(len(body)), Mode: 0o644}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(body); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{LocalDataDir: dataDir}
+	if err := RestoreBackup(cfg, bytes.NewReader(archive.Bytes())); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+
+	if _, err := os.Stat(escapeTarget); !os.IsNotExist(err) {
+		t.Errorf("zip-slip entry escaped local_data_dir: %s exists", escapeTarget)
+	}
+	entries, _ := os.ReadDir(dataDir)
+	if len(entries) != 0 {
+		t.Errorf("expected the malicious entry to be skipped, got dir entries: %v", entries)
+	}
+}
+
+func TestCreateBackupSkipsUnsetPaths(t *testing.T) {
+	var archive bytes.Buffer
+	if err := CreateBackup(Config{}, &archive); err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+	if archive.Len() == 0 {
+		t.Error("expected an (empty) gzipped tar archive, got no output")
+	}
+}
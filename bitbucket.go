@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultBitbucketBaseURL is Bitbucket Cloud's API root. Bitbucket
+// doesn't offer a self-hosted-with-the-same-API product the way GitHub
+// Enterprise or a self-hosted GitLab do, so unlike gitlabClient there's
+// no override for it in practice, but RepoBackend.BaseURL still works
+// for a compatible proxy if one is ever needed.
+const defaultBitbucketBaseURL = "https://api.bitbucket.org/2.0"
+
+// bitbucketSearchResponse is Bitbucket Cloud's code search response:
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-search/
+type bitbucketSearchResponse struct {
+	Size   int                     `json:"size"`
+	Values []bitbucketSearchResult `json:"values"`
+}
+
+type bitbucketSearchResult struct {
+	ContentMatches []bitbucketContentMatch `json:"content_matches"`
+	File           bitbucketFile           `json:"file"`
+}
+
+type bitbucketFile struct {
+	Path string `json:"path"`
+}
+
+type bitbucketContentMatch struct {
+	Lines []bitbucketMatchLine `json:"lines"`
+}
+
+type bitbucketMatchLine struct {
+	Segments []bitbucketMatchSegment `json:"segments"`
+}
+
+type bitbucketMatchSegment struct {
+	Text string `json:"text"`
+}
+
+// bitbucketClient queries Bitbucket Cloud's workspace-scoped code search
+// API and adapts results into the shared githubSearchItem shape.
+type bitbucketClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+func newBitbucketClientFor(baseURL, token string) *bitbucketClient {
+	if baseURL == "" {
+		baseURL = defaultBitbucketBaseURL
+	}
+	return &bitbucketClient{httpClient: http.DefaultClient, baseURL: baseURL, token: token}
+}
+
+// splitBitbucketRepo splits repo (a "workspace/repo_slug" path, matching
+// every other backend's repo naming) into its workspace, which is what
+// Bitbucket's search endpoint is scoped to.
+func splitBitbucketRepo(repo string) (workspace string, err error) {
+	workspace, _, ok := strings.Cut(repo, "/")
+	if !ok || workspace == "" {
+		return "", fmt.Errorf("invalid bitbucket repo %q: want workspace/repo_slug", repo)
+	}
+	return workspace, nil
+}
+
+// searchCode runs a code search scoped to repo (a "workspace/repo_slug"
+// path) and adapts the results into githubSearchItems. Bitbucket's
+// search API is workspace-scoped, not repo-scoped, so repo is also
+// added to the query as a repo: filter. page and perPage are forwarded
+// as Bitbucket's own page/pagelen query parameters when positive. The
+// total match count comes from the response's size field.
+func (c *bitbucketClient) searchCode(term, repo string, page, perPage int) ([]githubSearchItem, int, error) {
+	workspace, err := splitBitbucketRepo(repo)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("%s repo:%q", term, repo)
+	reqURL := fmt.Sprintf("%s/workspaces/%s/search/code?search_query=%s",
+		c.baseURL, url.PathEscape(workspace), url.QueryEscape(query))
+	if page > 0 {
+		reqURL += "&page=" + strconv.Itoa(page)
+	}
+	if perPage > 0 {
+		reqURL += "&pagelen=" + strconv.Itoa(perPage)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("build bitbucket search request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("bitbucket search request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("bitbucket search request: unexpected status %s", resp.Status)
+	}
+
+	var parsed bitbucketSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, 0, fmt.Errorf("decode bitbucket search response: %w", err)
+	}
+
+	items := make([]githubSearchItem, len(parsed.Values))
+	for i, v := range parsed.Values {
+		items[i] = githubSearchItem{
+			Name:        pathBase(v.File.Path),
+			Path:        v.File.Path,
+			TextMatches: bitbucketTextMatches(v.ContentMatches),
+		}
+		items[i].Repository.FullName = repo
+	}
+
+	return items, parsed.Size, nil
+}
+
+// bitbucketTextMatches flattens Bitbucket's line/segment match structure
+// into the shared githubTextMatch shape, one fragment per matched line.
+func bitbucketTextMatches(matches []bitbucketContentMatch) []githubTextMatch {
+	var textMatches []githubTextMatch
+	for _, m := range matches {
+		for _, line := range m.Lines {
+			var text strings.Builder
+			for _, seg := range line.Segments {
+				text.WriteString(seg.Text)
+			}
+			textMatches = append(textMatches, githubTextMatch{Fragment: text.String()})
+		}
+	}
+	return textMatches
+}
+
+// pathBase returns the final path segment, matching the "Name" field
+// GitHub's own search API returns alongside the full Path.
+func pathBase(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
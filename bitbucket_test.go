@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBitbucketClientSearchCode(t *testing.T) {
+	var gotPath, gotQuery, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.Query().Get("search_query")
+		gotAuth = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(bitbucketSearchResponse{
+			Size: 1,
+			Values: []bitbucketSearchResult{
+				{
+					File: bitbucketFile{Path: "internal/auth.go"},
+					ContentMatches: []bitbucketContentMatch{
+						{Lines: []bitbucketMatchLine{
+							{Segments: []bitbucketMatchSegment{{Text: "func "}, {Text: "Login() {}"}}},
+						}},
+					},
+				},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	client := newBitbucketClientFor(srv.URL, "bb-token")
+	items, total, err := client.searchCode("Login", "myteam/myrepo", 0, 0)
+	if err != nil {
+		t.Fatalf("searchCode: %v", err)
+	}
+
+	if gotPath != "/workspaces/myteam/search/code" {
+		t.Errorf("gotPath = %q, want /workspaces/myteam/search/code", gotPath)
+	}
+	if want := `Login repo:"myteam/myrepo"`; gotQuery != want {
+		t.Errorf("gotQuery = %q, want %q", gotQuery, want)
+	}
+	if gotAuth != "Bearer bb-token" {
+		t.Errorf("gotAuth = %q, want Bearer bb-token", gotAuth)
+	}
+
+	if total != 1 {
+		t.Errorf("total = %d, want 1", total)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	item := items[0]
+	if item.Name != "auth.go" || item.Path != "internal/auth.go" {
+		t.Errorf("item = %+v, want Name=auth.go Path=internal/auth.go", item)
+	}
+	if item.Repository.FullName != "myteam/myrepo" {
+		t.Errorf("item.Repository.FullName = %q, want myteam/myrepo", item.Repository.FullName)
+	}
+	if len(item.TextMatches) != 1 || item.TextMatches[0].Fragment != "func Login() {}" {
+		t.Errorf("item.TextMatches = %+v, want fragment %q", item.TextMatches, "func Login() {}")
+	}
+}
+
+func TestNewBitbucketClientForDefaultsBaseURL(t *testing.T) {
+	client := newBitbucketClientFor("", "")
+	if client.baseURL != defaultBitbucketBaseURL {
+		t.Errorf("baseURL = %q, want %q", client.baseURL, defaultBitbucketBaseURL)
+	}
+}
+
+func TestSplitBitbucketRepoRejectsMissingSlash(t *testing.T) {
+	if _, err := splitBitbucketRepo("no-slash"); err == nil {
+		t.Fatal("expected an error for a repo without a workspace/repo_slug slash")
+	}
+}
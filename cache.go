@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// resultCacheTTL is how long a search result set stays available for
+// refinement via the refine query parameter.
+const resultCacheTTL = 5 * time.Minute
+
+// resultCache holds recent search results in memory so they can be
+// re-filtered by a refine request without re-querying the backends.
+type resultCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	results   []Result
+	expiresAt time.Time
+}
+
+func newResultCache() *resultCache {
+	return &resultCache{entries: map[string]cacheEntry{}}
+}
+
+// Put stores results and returns the request ID clients can pass back
+// via the refine parameter to drill down into them.
+func (c *resultCache) Put(results []Result) (string, error) {
+	id, err := newRequestID()
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[id] = cacheEntry{results: results, expiresAt: time.Now().Add(resultCacheTTL)}
+	return id, nil
+}
+
+// Get returns the cached results for id, or ok=false if id is unknown
+// or has expired.
+func (c *resultCache) Get(id string) ([]Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, id)
+		return nil, false
+	}
+	return entry.results, true
+}
+
+func newRequestID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
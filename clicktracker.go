@@ -0,0 +1,70 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// clickEntry is one search result kept behind a click ID, along with the
+// RankExperiment arm that produced it, if any.
+type clickEntry struct {
+	url     string
+	rankArm string
+	clicks  int
+}
+
+// clickTracker maps click IDs (issued alongside each result returned
+// from /search) to that result's URL and the RankExperiment arm that
+// scored it, so a client-side result list can route link clicks through
+// /v1/click?result_id=... for relevance tracking, and so a click from an
+// experiment arm counts as that arm's outcome, before being redirected
+// to the real URL.
+type clickTracker struct {
+	mu      sync.Mutex
+	entries map[string]*clickEntry
+}
+
+func newClickTracker() *clickTracker {
+	return &clickTracker{entries: map[string]*clickEntry{}}
+}
+
+// Register stores url (and rankArm, "" if no experiment scored it)
+// behind a newly issued click ID.
+func (t *clickTracker) Register(url, rankArm string) (string, error) {
+	id, err := newClickID()
+	if err != nil {
+		return "", err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries[id] = &clickEntry{url: url, rankArm: rankArm}
+	return id, nil
+}
+
+// Resolve returns the URL and RankExperiment arm id was registered
+// with, recording a click, or ok=false if id is unknown.
+func (t *clickTracker) Resolve(id string) (url, rankArm string, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.entries[id]
+	if !ok {
+		return "", "", false
+	}
+	entry.clicks++
+	return entry.url, entry.rankArm, true
+}
+
+// newClickID generates a short random click ID, matching newShortLinkID's
+// approach for the same reason: unguessable and short enough to embed
+// in a URL query parameter without inflating it much.
+func newClickID() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate click id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
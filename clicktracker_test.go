@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestClickTrackerRegisterAndResolve(t *testing.T) {
+	tr := newClickTracker()
+
+	id, err := tr.Register("https://github.com/o/r/blob/main/a.go", "b")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	url, rankArm, ok := tr.Resolve(id)
+	if !ok {
+		t.Fatal("expected Resolve to find the registered click")
+	}
+	if url != "https://github.com/o/r/blob/main/a.go" {
+		t.Errorf("Resolve url = %q, want the original URL", url)
+	}
+	if rankArm != "b" {
+		t.Errorf("Resolve rankArm = %q, want %q", rankArm, "b")
+	}
+}
+
+func TestClickTrackerResolveUnknown(t *testing.T) {
+	tr := newClickTracker()
+	if _, _, ok := tr.Resolve("nope"); ok {
+		t.Error("expected Resolve to fail for an unknown id")
+	}
+}
+
+func TestClickTrackerTracksClicks(t *testing.T) {
+	tr := newClickTracker()
+	id, _ := tr.Register("https://example.com", "")
+
+	tr.Resolve(id)
+	tr.Resolve(id)
+
+	tr.mu.Lock()
+	clicks := tr.entries[id].clicks
+	tr.mu.Unlock()
+	if clicks != 2 {
+		t.Errorf("clicks = %d, want 2", clicks)
+	}
+}
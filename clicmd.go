@@ -0,0 +1,173 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/template"
+)
+
+//go:embed templates/completion.bash templates/completion.zsh templates/completion.fish
+var completionScriptsFS embed.FS
+
+// completionScripts maps a shell name to its embedded completion
+// script, generated statically rather than introspecting the flag sets
+// at runtime, the same way most single-binary Go CLIs without a
+// framework like cobra do it.
+var completionScripts = map[string]string{
+	"bash": "templates/completion.bash",
+	"zsh":  "templates/completion.zsh",
+	"fish": "templates/completion.fish",
+}
+
+// runCompletion is the "git_search completion <bash|zsh|fish>"
+// subcommand: it prints a completion script for the named shell to
+// stdout, for the caller to source or install (e.g.
+// `git_search completion bash > /etc/bash_completion.d/git_search`).
+func runCompletion(args []string) {
+	fs := flag.NewFlagSet("completion", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "usage: git_search completion <bash|zsh|fish>")
+		os.Exit(1)
+	}
+
+	path, ok := completionScripts[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unsupported shell %q: want bash, zsh, or fish\n", fs.Arg(0))
+		os.Exit(1)
+	}
+	script, err := completionScriptsFS.ReadFile(path)
+	if err != nil {
+		log.Fatalf("read embedded completion script: %v", err)
+	}
+	os.Stdout.Write(script)
+}
+
+// runSearch is the "git_search search [flags] <config_file> <query>"
+// subcommand: a one-shot search against the config's repos, for script
+// and fzf-style pipeline consumption, as an alternative to the
+// interactive "git_search tui" or running the HTTP server.
+func runSearch(args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	output := fs.String("output", "text", "output format: text, json, tsv, vimgrep, or template")
+	tmpl := fs.String("template", "", "Go text/template applied to each matched line when --output=template; fields: .Repo .Path .URL .Line .Text")
+	group := fs.String("group", "", "scope the search to this repo group (see repo_groups in the config file)")
+	contextLines := fs.Int("context_lines", -1, "lines of context around each match; -1 uses the config's max_context_lines")
+	perPage := fs.Int("per_page", defaultSearchPerPage, "results per page")
+	excludeForks := fs.Bool("exclude_forks", false, "exclude forked repos; overrides the config's exclude_forks when set")
+	excludeArchived := fs.Bool("exclude_archived", false, "exclude archived repos; overrides the config's exclude_archived when set")
+	lang := fs.String("lang", "", "restrict results to this language, e.g. go")
+	path := fs.String("path", "", "restrict results to paths starting with this prefix, e.g. cmd/")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "usage: git_search search [flags] <config_file> <query>")
+		os.Exit(1)
+	}
+
+	if *lang != "" && !isKnownLanguage(*lang) {
+		log.Fatalf("unknown --lang %q", *lang)
+	}
+
+	cfg, err := LoadConfig(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	s, err := newSearcher(cfg, nil, nil, nil)
+	if err != nil {
+		log.Fatalf("init searcher: %v", err)
+	}
+
+	results, _, _, _, err := s.Search(fs.Arg(1), *contextLines, false, 1, *perPage, "", *group, cfg.ExcludeForks || *excludeForks, cfg.ExcludeArchived || *excludeArchived, *lang, *path)
+	if err != nil {
+		log.Fatalf("search: %v", err)
+	}
+
+	if err := writeSearchResults(os.Stdout, results, *output, *tmpl); err != nil {
+		log.Fatalf("write results: %v", err)
+	}
+}
+
+// searchResultLine is what --output=template renders one at a time,
+// and what --output=tsv's columns come from: one matched line, with its
+// result's repo/path/URL alongside.
+type searchResultLine struct {
+	Repo string
+	Path string
+	URL  string
+	Line int
+	Text string
+}
+
+// writeSearchResults renders results to w in format ("text", "json",
+// "tsv", or "template"), returning an error for an unknown format or,
+// for "template", a template that fails to parse or execute.
+func writeSearchResults(w io.Writer, results []Result, format, tmplText string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+
+	case "text":
+		for _, r := range results {
+			fmt.Fprintf(w, "%s %s\n", r.Repo, r.Path)
+			for _, line := range r.Lines {
+				fmt.Fprintf(w, "  %d: %s\n", line.Number, line.Text)
+			}
+		}
+		return nil
+
+	case "tsv":
+		for _, line := range flattenSearchResultLines(results) {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", line.Repo, line.Path, line.Line, line.Text)
+		}
+		return nil
+
+	case "vimgrep":
+		writeVimgrepFormat(w, results)
+		return nil
+
+	case "template":
+		if tmplText == "" {
+			return fmt.Errorf("--output=template requires --template")
+		}
+		t, err := template.New("result").Parse(tmplText)
+		if err != nil {
+			return fmt.Errorf("parse --template: %w", err)
+		}
+		for _, line := range flattenSearchResultLines(results) {
+			if err := t.Execute(w, line); err != nil {
+				return fmt.Errorf("execute --template: %w", err)
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown --output %q: want text, json, tsv, vimgrep, or template", format)
+	}
+}
+
+// flattenSearchResultLines turns each result's matched lines into one
+// searchResultLine per line, carrying its result's repo/path/URL along
+// with it, for the line-oriented tsv/template formats.
+func flattenSearchResultLines(results []Result) []searchResultLine {
+	var lines []searchResultLine
+	for _, r := range results {
+		if len(r.Lines) == 0 {
+			lines = append(lines, searchResultLine{Repo: r.Repo, Path: r.Path, URL: r.URL})
+			continue
+		}
+		for _, line := range r.Lines {
+			lines = append(lines, searchResultLine{Repo: r.Repo, Path: r.Path, URL: r.URL, Line: line.Number, Text: line.Text})
+		}
+	}
+	return lines
+}
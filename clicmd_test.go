@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testResults() []Result {
+	return []Result{
+		{Repo: "bjatkin/repo", Path: "main.go", URL: "https://example.com/main.go", Lines: []Line{
+			{Number: 3, Text: "func main() {"},
+			{Number: 4, Text: "\tfmt.Println(\"hi\")"},
+		}},
+	}
+}
+
+func TestWriteSearchResultsJSON(t *testing.T) {
+	var out strings.Builder
+	if err := writeSearchResults(&out, testResults(), "json", ""); err != nil {
+		t.Fatalf("writeSearchResults: %v", err)
+	}
+	var decoded []Result
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatalf("decode json output: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0].Repo != "bjatkin/repo" {
+		t.Errorf("decoded = %+v, want the one test result", decoded)
+	}
+}
+
+func TestWriteSearchResultsTSV(t *testing.T) {
+	var out strings.Builder
+	if err := writeSearchResults(&out, testResults(), "tsv", ""); err != nil {
+		t.Fatalf("writeSearchResults: %v", err)
+	}
+	want := "bjatkin/repo\tmain.go\t3\tfunc main() {\nbjatkin/repo\tmain.go\t4\t\tfmt.Println(\"hi\")\n"
+	if out.String() != want {
+		t.Errorf("tsv output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestWriteSearchResultsVimgrep(t *testing.T) {
+	var out strings.Builder
+	if err := writeSearchResults(&out, testResults(), "vimgrep", ""); err != nil {
+		t.Fatalf("writeSearchResults: %v", err)
+	}
+	want := "main.go:3:1:func main() {\nmain.go:4:1:\tfmt.Println(\"hi\")\n"
+	if out.String() != want {
+		t.Errorf("vimgrep output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestWriteSearchResultsTemplate(t *testing.T) {
+	var out strings.Builder
+	err := writeSearchResults(&out, testResults(), "template", "{{.Path}}:{{.Line}}:{{.Text}}")
+	if err != nil {
+		t.Fatalf("writeSearchResults: %v", err)
+	}
+	want := "main.go:3:func main() {\nmain.go:4:\tfmt.Println(\"hi\")\n"
+	if out.String() != want {
+		t.Errorf("template output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestWriteSearchResultsTemplateRequiresTemplateFlag(t *testing.T) {
+	var out strings.Builder
+	if err := writeSearchResults(&out, testResults(), "template", ""); err == nil {
+		t.Error("expected an error when --template is empty")
+	}
+}
+
+func TestWriteSearchResultsUnknownFormat(t *testing.T) {
+	var out strings.Builder
+	if err := writeSearchResults(&out, testResults(), "xml", ""); err == nil {
+		t.Error("expected an error for an unknown --output format")
+	}
+}
+
+func TestCompletionScriptsAreEmbeddedForEachShell(t *testing.T) {
+	for shell, path := range completionScripts {
+		script, err := completionScriptsFS.ReadFile(path)
+		if err != nil {
+			t.Errorf("read embedded completion script for %s: %v", shell, err)
+			continue
+		}
+		if !strings.Contains(string(script), "git_search") {
+			t.Errorf("%s completion script doesn't mention git_search: %s", shell, script)
+		}
+	}
+}
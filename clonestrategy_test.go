@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestCloneOptionsForDefaults(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	idx.cloneDepth = 1
+	idx.cloneFilter = "blob:none"
+
+	opts := idx.cloneOptionsFor("owner/repo")
+	if opts.Depth != 1 {
+		t.Errorf("Depth = %d, want 1", opts.Depth)
+	}
+	if opts.Filter != "blob:none" {
+		t.Errorf("Filter = %q, want blob:none", opts.Filter)
+	}
+	if len(opts.Sparse) != 0 {
+		t.Errorf("Sparse = %v, want none", opts.Sparse)
+	}
+}
+
+func TestCloneOptionsForOverride(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	idx.cloneDepth = 1
+	idx.cloneOptions = map[string]RepoCloneOptions{
+		"owner/monorepo": {Repo: "owner/monorepo", Depth: -1, Sparse: []string{"src/"}},
+	}
+
+	opts := idx.cloneOptionsFor("owner/monorepo")
+	if opts.Depth != 0 {
+		t.Errorf("Depth = %d, want 0 (full history)", opts.Depth)
+	}
+	if len(opts.Sparse) != 1 || opts.Sparse[0] != "src/" {
+		t.Errorf("Sparse = %v, want [src/]", opts.Sparse)
+	}
+
+	other := idx.cloneOptionsFor("owner/other")
+	if other.Depth != 1 {
+		t.Errorf("unrelated repo Depth = %d, want default 1", other.Depth)
+	}
+}
+
+func TestCloneOptionsForSSHKey(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	idx.cloneOptions = map[string]RepoCloneOptions{
+		"owner/private": {Repo: "owner/private", SSHKeyPath: "/etc/keys/private"},
+	}
+
+	opts := idx.cloneOptionsFor("owner/private")
+	if opts.SSHKeyPath != "/etc/keys/private" {
+		t.Errorf("SSHKeyPath = %q, want /etc/keys/private", opts.SSHKeyPath)
+	}
+}
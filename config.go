@@ -0,0 +1,693 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultMaxContextLines is used when the config file does not set
+// max_context_lines.
+const defaultMaxContextLines = 10
+
+// Config holds the service configuration loaded from the config file
+// passed on the command line.
+type Config struct {
+	Port            int      `yaml:"port"`
+	Repos           []string `yaml:"repos"`
+	MaxContextLines int      `yaml:"max_context_lines"`
+
+	// RepoGroups assigns repos to named groups (e.g. "frontend",
+	// "infra"), so a search can be scoped to just one group via
+	// GET /search?group=name instead of every configured repo. A repo
+	// can belong to more than one group. Unset means no group scoping
+	// is available.
+	RepoGroups map[string][]string `yaml:"repo_groups"`
+
+	// ExcludeForks, when true, appends a fork:false qualifier to every
+	// search so forked repos are excluded by default; a caller can
+	// still include them for one request via GET /search?exclude_forks=0.
+	ExcludeForks bool `yaml:"exclude_forks"`
+
+	// ExcludeArchived, when true, appends an archived:false qualifier to
+	// every search so archived repos are excluded by default; a caller
+	// can still include them for one request via
+	// GET /search?exclude_archived=0.
+	ExcludeArchived bool `yaml:"exclude_archived"`
+
+	// RecencyHalfLife, when set, boosts results whose repo was pushed to
+	// more recently. A result whose repo was pushed exactly this long
+	// ago gets half the boost of one pushed just now. Empty disables
+	// the boost. Parsed with time.ParseDuration, e.g. "168h".
+	RecencyHalfLife string `yaml:"recency_half_life"`
+
+	// PathPenalties downweights results whose path matches a pattern,
+	// e.g. to push generated code or vendored dependencies down the
+	// results.
+	PathPenalties []PathPenalty `yaml:"path_penalties"`
+
+	// LocalDataDir is where the local backend clones repos to, for
+	// searches (like hex pattern matching) that need real file content
+	// rather than GitHub's code search API.
+	LocalDataDir string `yaml:"local_data_dir"`
+
+	// ArchiveExtensions lists which archive file extensions the local
+	// backend indexes the contents of, e.g. vendored .zip/.tar files.
+	// Defaults to defaultArchiveExtensions when unset.
+	ArchiveExtensions []string `yaml:"archive_extensions"`
+
+	// ArchiveMaxBytes bounds how large an archive member is read into
+	// memory for indexing. Defaults to defaultArchiveMaxBytes when 0.
+	ArchiveMaxBytes int64 `yaml:"archive_max_bytes"`
+
+	// LFSMode controls how the local backend handles Git LFS pointer
+	// files: "skip" to leave LFS-tracked files unindexed, "pointer" to
+	// index the pointer file's own metadata text, or "fetch" to pull the
+	// real content first. Defaults to "pointer" when unset.
+	LFSMode string `yaml:"lfs_mode"`
+
+	// CloneTimeout bounds how long a single clone can run before it's
+	// killed, so a pathological repo can't hang the indexer forever.
+	// Parsed with time.ParseDuration. Defaults to defaultCloneTimeout
+	// when unset.
+	CloneTimeout string `yaml:"clone_timeout"`
+
+	// CloneMaxCPUSeconds and CloneMaxMemoryMB cap the CPU time and
+	// resident memory a clone/index subprocess may use, enforced via
+	// ulimit, so a huge file or zip bomb can't take down the serving
+	// process. Zero disables the corresponding limit.
+	CloneMaxCPUSeconds int `yaml:"clone_max_cpu_seconds"`
+	CloneMaxMemoryMB   int `yaml:"clone_max_memory_mb"`
+
+	// CloneDepth is how many commits of history a clone keeps, by
+	// default. Defaults to defaultCloneDepth (a shallow clone of just
+	// the tip of the default branch) when unset.
+	CloneDepth int `yaml:"clone_depth"`
+
+	// CloneFilter, when set, is passed to git clone as --filter, e.g.
+	// "blob:none" for a blobless clone that fetches file content
+	// on-demand instead of up front. Empty clones normally.
+	CloneFilter string `yaml:"clone_filter"`
+
+	// CloneOptions overrides CloneDepth, CloneFilter and adds sparse
+	// checkout paths on a per-repo basis, for repos where the defaults
+	// don't fit (e.g. a monorepo where only one directory is searched).
+	CloneOptions []RepoCloneOptions `yaml:"clone_options"`
+
+	// RegexSearchTimeout bounds how long a single GET /search/local?regex=1
+	// scan may run before it's aborted, so an expensive pattern (or a
+	// very large clone) can't tie up a request indefinitely. Parsed with
+	// time.ParseDuration. Defaults to defaultRegexSearchTimeout when
+	// unset.
+	RegexSearchTimeout string `yaml:"regex_search_timeout"`
+
+	// LocalDiskQuotaMB bounds the total size of the local backend's data
+	// directory. Once over budget, the least-recently-searched repo
+	// clones are removed (and re-cloned on demand later) until the
+	// directory fits. 0 disables enforcement.
+	LocalDiskQuotaMB int64 `yaml:"local_disk_quota_mb"`
+
+	// LocalIndexBackend selects how the local backend (searchText,
+	// /search/local) finds matching files: "grep" (the default) narrows
+	// each search with a persisted trigram index but still matches by
+	// plain substring; "bleve" would add tokenization, stemming, and
+	// ranked results, but isn't available in this build (see
+	// validateLocalIndexBackend) since it requires a dependency this
+	// build doesn't vendor.
+	LocalIndexBackend string `yaml:"local_index_backend"`
+
+	// JobConcurrency bounds how many index/sync jobs the /admin/jobs
+	// queue runs at once. Defaults to defaultJobConcurrency when unset.
+	JobConcurrency int `yaml:"job_concurrency"`
+
+	// SyncInterval periodically enqueues a scheduled index job for every
+	// configured repo (a Go duration string, e.g. "1h"). Empty disables
+	// scheduled syncing entirely; repos are then only synced on demand
+	// (a webhook delivery, or a manual /admin/mirrors POST).
+	SyncInterval string `yaml:"sync_interval"`
+
+	// RepoSyncIntervals overrides SyncInterval for specific repos, e.g.
+	// syncing a fast-moving repo more often than the rest.
+	RepoSyncIntervals map[string]string `yaml:"repo_sync_intervals"`
+
+	// OrgDiscoveryInterval controls how often "org:name"/"user:name"
+	// entries in Repos are re-listed against the GitHub API to pick up
+	// newly created repos (a Go duration string, e.g. "1h"). Defaults to
+	// defaultOrgDiscoveryInterval when unset. Has no effect on a config
+	// with no org:/user: entries.
+	OrgDiscoveryInterval string `yaml:"org_discovery_interval"`
+
+	// SlackSigningSecret verifies that requests to
+	// /integrations/slack/command actually came from Slack. Empty
+	// disables verification, which is only safe behind a network the
+	// service already trusts.
+	SlackSigningSecret string `yaml:"slack_signing_secret"`
+
+	// GithubWebhookSecret verifies that requests to /webhooks/github
+	// actually came from GitHub, by checking the X-Hub-Signature-256
+	// header GitHub signs every delivery with. Empty disables
+	// verification, which is only safe behind a network the service
+	// already trusts.
+	GithubWebhookSecret string `yaml:"github_webhook_secret"`
+
+	// PublicAPI enables GET /public/search: a read-only, unauthenticated
+	// subset of the search API with long-lived Cache-Control and
+	// Surrogate-Key headers, suited to running an open-source deployment
+	// behind a CDN. Defaults to disabled.
+	PublicAPI bool `yaml:"public_api"`
+
+	// Name, Host, and PathPrefix identify one entry of Profiles: a
+	// self-contained Config (its own repos, tokens, and caches) routed
+	// to by request Host or path prefix so several isolated deployments
+	// (e.g. staging vs prod repo corpora) can share one process. Unused
+	// on the top-level Config.
+	Name       string `yaml:"name"`
+	Host       string `yaml:"host"`
+	PathPrefix string `yaml:"path_prefix"`
+
+	// Profiles, when set, turns this Config into a router: each entry
+	// is an independent Config (with its own Name/Host/PathPrefix)
+	// served in the same process, isolated from the others. Port is
+	// only meaningful on the top-level Config; a profile's own Port is
+	// ignored.
+	Profiles []Config `yaml:"profiles"`
+
+	// WatchConfig re-reads the config file (and, transitively, any
+	// mounted Kubernetes Secret it references) and hot-swaps it in
+	// whenever the file's resolved target changes, so a ConfigMap/Secret
+	// rotation is picked up without a restart. Defaults to disabled.
+	WatchConfig bool `yaml:"watch_config"`
+
+	// ConfigWatchInterval overrides how often the config file is polled
+	// when WatchConfig is set. Parsed with time.ParseDuration. Defaults
+	// to defaultConfigWatchInterval when unset.
+	ConfigWatchInterval string `yaml:"config_watch_interval"`
+
+	// RepoBackends overrides which code search backend, API base URL,
+	// and credential a specific repo uses, e.g. a repo hosted on a
+	// self-hosted GitLab instance among otherwise GitHub-hosted repos.
+	// Repos not listed here use the default GitHub backend.
+	RepoBackends []RepoBackend `yaml:"repo_backends"`
+
+	// URLRewriteRules rewrite a result's URL, e.g. to point at an
+	// internal GitHub Enterprise web host or a code-review tool instead
+	// of github.com. Rules are applied in order.
+	URLRewriteRules []URLRewriteRule `yaml:"url_rewrite_rules"`
+
+	// RepoAliases assigns a short, stable alias to a configured repo
+	// (e.g. "payments" for "org/payments-service"), so client
+	// integrations can refer to the repo by query param without being
+	// broken by a repo rename.
+	RepoAliases []RepoAlias `yaml:"repo_aliases"`
+
+	// GithubToken authenticates outbound requests to the default GitHub
+	// code search backend (repos not overridden by RepoBackends), so
+	// they run against the authenticated rate limit instead of the
+	// anonymous 10-requests/minute limit, and can see private repos.
+	// Falls back to the GITHUB_TOKEN environment variable when unset, so
+	// it doesn't have to be written to the config file in plaintext.
+	GithubToken string `yaml:"github_token"`
+
+	// GithubBaseURL overrides the default GitHub backend's code search
+	// API endpoint, e.g. "https://ghe.example.com/api/v3/search/code"
+	// for a GitHub Enterprise Server instance, so every repo not
+	// overridden by RepoBackends is searched against it instead of
+	// github.com. Empty defaults to github.com's public API.
+	GithubBaseURL string `yaml:"github_base_url"`
+
+	// GithubHTMLBaseURL overrides the scheme and host of a default-backend
+	// result's click-through URL, keeping its path unchanged. Only needed
+	// when GithubBaseURL's host isn't reachable as a browsable web UI
+	// (e.g. an internal-only API endpoint fronting a GHE instance whose
+	// web UI is exposed at a different, externally reachable host).
+	// Empty leaves GitHub's own reported URL as-is.
+	GithubHTMLBaseURL string `yaml:"github_html_base_url"`
+
+	// GitLabToken authenticates outbound requests to gitlab.com for
+	// repos: entries using the "gitlab:" prefix (see
+	// expandGitlabPrefixes). Falls back to the GITLAB_TOKEN environment
+	// variable when unset. A self-hosted GitLab instance needs its own
+	// credential and base URL instead, configured via RepoBackends.
+	GitLabToken string `yaml:"gitlab_token"`
+
+	// StatePersistPath, when set, persists this instance's runtime state
+	// (short links, REST hook subscriptions, disabled/imported repos,
+	// detected renames — the same document /admin/export serves) to a
+	// local JSON file, and restores it on startup, so a restart or
+	// redeploy doesn't lose it. Left unset, that state is in-memory only.
+	StatePersistPath string `yaml:"state_persist_path"`
+
+	// StateBackend selects what StatePersistPath is stored on: "file"
+	// (the default) writes a local JSON file; "sqlite" and "postgres"
+	// would let several instances share one store, but aren't available
+	// in this build (see validateStateBackend) since both require a
+	// database driver this build doesn't vendor.
+	StateBackend string `yaml:"state_backend"`
+
+	// StateEncryptionKeys, when set, encrypts StatePersistPath's file at
+	// rest with AES-256-GCM, so a leaked backup or disk snapshot doesn't
+	// expose the REST hook target URLs and other data the bundle
+	// contains. Each entry is a 32-byte key, hex-encoded (64 hex
+	// characters). The first key encrypts new writes; every key is
+	// tried, in order, to decrypt, so a key can be rotated by
+	// prepending a new one ahead of the old and leaving the old key in
+	// place until every state file has been re-saved under the new one.
+	StateEncryptionKeys []string `yaml:"state_encryption_keys"`
+
+	// KMSProvider, when set, envelope-encrypts StatePersistPath's file
+	// via a key management service instead of a locally configured
+	// key: "static" wraps the per-save data key with KMSStaticKey
+	// (suited to local development, or a non-cloud KMS a deployment
+	// already runs), or "aws"/"gcp" for the corresponding cloud KMS.
+	// Takes precedence over StateEncryptionKeys when both are set.
+	KMSProvider string `yaml:"kms_provider"`
+
+	// KMSStaticKey is the 32-byte, hex-encoded master key used when
+	// KMSProvider is "static".
+	KMSStaticKey string `yaml:"kms_static_key"`
+
+	// TelemetryEnabled opts this instance into periodically reporting
+	// aggregate, anonymized usage (version, backend kinds in use, and a
+	// coarse search volume bucket — never repo names, search terms,
+	// results, or API keys) to TelemetryEndpoint, to help maintainers
+	// prioritize features. Defaults to disabled. Has no effect if
+	// TelemetryEndpoint is empty.
+	TelemetryEnabled bool `yaml:"telemetry_enabled"`
+
+	// TelemetryEndpoint is the URL a telemetry report is POSTed to as
+	// JSON. There is no default; TelemetryEnabled does nothing until
+	// this is set.
+	TelemetryEndpoint string `yaml:"telemetry_endpoint"`
+
+	// TelemetryInterval overrides how often a report is sent. Parsed
+	// with time.ParseDuration. Defaults to defaultTelemetryInterval
+	// when unset.
+	TelemetryInterval string `yaml:"telemetry_interval"`
+
+	// Flags seeds the initial state of experimental feature flags (see
+	// FlagSemanticSearch, FlagHybridRanking), toggleable afterward at
+	// runtime via GET/POST /admin/flags without a restart. Unlisted
+	// flags default to disabled.
+	Flags map[string]bool `yaml:"flags"`
+
+	// RankExperiment, when Enabled, splits search traffic between the
+	// top-level ranking configuration above (arm "a") and ArmB (arm
+	// "b"), assigned sticky per API key, so a ranking change can be
+	// evaluated against the current default with real traffic before it
+	// replaces it. See /admin/ab_test.
+	RankExperiment RankExperiment `yaml:"rank_experiment"`
+
+	// Retention bounds how long usage/query history (see usageTracker,
+	// /admin/usage) is kept, for deployments that need to comply with a
+	// data-retention policy. See also /admin/purge, which purges on
+	// demand by API key, cutoff time, or both.
+	Retention RetentionConfig `yaml:"retention"`
+
+	// IPAccess restricts which caller IPs (and, optionally, countries)
+	// may reach the public search surface and the /admin/ surface,
+	// evaluated separately so an admin API can be locked down tighter
+	// than public search. Recompiled on every config reload (see
+	// WatchConfig), so a change takes effect without a restart.
+	IPAccess IPAccessConfig `yaml:"ip_access"`
+
+	// ServiceSigningSecret enables HMAC request signing (see
+	// requestsigning.go) as an alternative to an API key for
+	// machine-to-machine callers: every request must carry a
+	// timestamp, a nonce, and a signature over both plus the body,
+	// checked against this secret, with replay protection via the
+	// nonce. Empty disables the requirement entirely. Falls back to
+	// the SERVICE_SIGNING_SECRET environment variable when unset.
+	ServiceSigningSecret string `yaml:"service_signing_secret"`
+
+	// Roles assigns each API key (see apiKeyHeader/apiKeyParam) a role —
+	// "reader", "curator", or "admin" — enforced per endpoint by
+	// authzMiddleware. An empty (the default) map disables enforcement
+	// entirely, matching the all-or-nothing model this service had
+	// before roles existed. A key with no entry defaults to "reader"
+	// once enforcement is on.
+	Roles map[string]string `yaml:"roles"`
+
+	// SecurityHeaders controls the hardened response headers and TRACE
+	// rejection applied to every response by default; see
+	// securityheaders.go.
+	SecurityHeaders SecurityHeadersConfig `yaml:"security_headers"`
+
+	// MaxHeaderBytes caps the size of an incoming request's header
+	// block, passed straight to http.Server.MaxHeaderBytes. 0 (the
+	// default) uses net/http's own default (currently 1MB). Only
+	// meaningful on the top-level Config, same as Port.
+	MaxHeaderBytes int `yaml:"max_header_bytes"`
+
+	// ErrorReporting configures where a recovered handler panic is
+	// reported, in addition to always being logged; see recovery.go.
+	ErrorReporting ErrorReportingConfig `yaml:"error_reporting"`
+
+	// SelfUpdateCheck periodically checks this project's own GitHub
+	// releases for a version newer than the one running, surfacing "new
+	// version available" in the logs and GET /version. It never
+	// installs anything; see selfupdate.go.
+	SelfUpdateCheck SelfUpdateCheckConfig `yaml:"self_update_check"`
+
+	// SLOs defines the availability/latency targets to track against
+	// live /search traffic and report on via GET /admin/slo. Unset
+	// means no SLOs are tracked and that endpoint reports an empty list.
+	SLOs []SLOConfig `yaml:"slos"`
+}
+
+// SLOConfig defines one error-budget SLO to track against /search
+// traffic; see sloTracker.
+type SLOConfig struct {
+	// Name identifies this SLO in the /admin/slo report.
+	Name string `yaml:"name"`
+
+	// TargetAvailability is the fraction (0-1) of requests that must be
+	// "good" - succeeding and, if LatencyThresholdMS is set, answered
+	// within it - for the SLO to be met. 1 - TargetAvailability is its
+	// error budget.
+	TargetAvailability float64 `yaml:"target_availability"`
+
+	// LatencyThresholdMS, when set, additionally requires a request to
+	// finish within this many milliseconds to count as "good". 0 means
+	// only success/failure counts.
+	LatencyThresholdMS int64 `yaml:"latency_threshold_ms"`
+
+	// Window is how far back to compute this SLO's actual availability
+	// and burn rate, as a Go duration string (e.g. "1h", "720h" for 30
+	// days). Defaults to defaultSLOWindow when unset or unparsable.
+	Window string `yaml:"window"`
+}
+
+// SelfUpdateCheckConfig controls selfUpdateChecker.
+type SelfUpdateCheckConfig struct {
+	// Enabled turns the check on. Disabled (the default) makes no
+	// network calls at all.
+	Enabled bool `yaml:"enabled"`
+
+	// Repo is the GitHub "owner/name" repo to check releases against.
+	// Defaults to defaultSelfUpdateRepo (this project's own repo).
+	Repo string `yaml:"repo"`
+
+	// Interval is how often to re-check, as a Go duration string.
+	// Defaults to defaultSelfUpdateInterval.
+	Interval string `yaml:"interval"`
+}
+
+// ErrorReportingConfig controls recoveryMiddleware's error sink.
+type ErrorReportingConfig struct {
+	// SentryDSN, when set, sends every recovered panic to that Sentry
+	// project as an event, via a plain HTTP POST to its store endpoint
+	// (this service doesn't vendor the Sentry SDK). Empty disables
+	// external reporting; panics are still recovered and logged either
+	// way.
+	SentryDSN string `yaml:"sentry_dsn"`
+}
+
+// SecurityHeadersConfig controls securityHeadersMiddleware.
+type SecurityHeadersConfig struct {
+	// Disable turns off every header this middleware would otherwise
+	// set (and stops rejecting TRACE requests), for a deployment that
+	// already applies its own hardening at a proxy in front of this
+	// service.
+	Disable bool `yaml:"disable"`
+
+	// ContentSecurityPolicy overrides defaultContentSecurityPolicy when
+	// set.
+	ContentSecurityPolicy string `yaml:"content_security_policy"`
+}
+
+// IPAccessConfig holds the IP allow/deny rules for each of the service's
+// two request surfaces.
+type IPAccessConfig struct {
+	Public IPAccessRule `yaml:"public"`
+	Admin  IPAccessRule `yaml:"admin"`
+
+	// TrustedProxies lists the CIDR ranges of reverse proxies/CDNs
+	// allowed to set ClientIPHeader with the caller's real IP. This is
+	// the deployment geoCountryHeader already assumes (a CDN resolving
+	// country upstream), but without TrustedProxies configured, Allow/
+	// Deny CIDR rules are still checked against the proxy's own address
+	// instead of the client's, silently doing nothing useful. Empty
+	// (the default) never trusts the header and uses the connection's
+	// RemoteAddr directly.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// ClientIPHeader is the header a proxy listed in TrustedProxies
+	// sets with the original client IP, e.g. "X-Forwarded-For" (read as
+	// a comma-separated list, using the first entry) or a CDN-specific
+	// header such as Cloudflare's "CF-Connecting-IP". Defaults to
+	// "X-Forwarded-For" when TrustedProxies is non-empty. Has no effect
+	// otherwise.
+	//
+	// Trusting this header only pushes the spoofing problem back one
+	// hop: it's the responsibility of whatever's listed in
+	// TrustedProxies to strip or overwrite any value a client sent for
+	// this header itself before forwarding the request, the same
+	// requirement any reverse proxy setting X-Forwarded-For has to meet.
+	ClientIPHeader string `yaml:"client_ip_header"`
+}
+
+// IPAccessRule allows or denies requests by source IP (via CIDR ranges)
+// and, optionally, by country. Deny is checked before Allow, so an
+// explicit deny always wins even if the same address also matches an
+// allow entry. An empty Allow list means "allow from anywhere not
+// denied"; a non-empty one switches to allow-listing, where only
+// matching addresses (and not denied) get through.
+//
+// Country codes are matched against GeoCountryHeader, since this service
+// doesn't embed a GeoIP database of its own — a deployment behind a CDN
+// or reverse proxy that already resolves geo (e.g. Cloudflare's
+// CF-IPCountry) forwards it as a header instead.
+type IPAccessRule struct {
+	Allow          []string `yaml:"allow"`
+	Deny           []string `yaml:"deny"`
+	AllowCountries []string `yaml:"allow_countries"`
+	DenyCountries  []string `yaml:"deny_countries"`
+}
+
+// RetentionConfig bounds how long the service holds onto query/usage
+// history before dropping it automatically.
+type RetentionConfig struct {
+	// UsageDays is how many days of /admin/usage history (see
+	// usageTracker) to keep; days older than that are dropped the next
+	// time usage is recorded or reported. 0 or negative keeps history
+	// forever.
+	UsageDays int `yaml:"usage_days"`
+}
+
+// RankExperiment configures an A/B test between the service's default
+// ranking rules and an alternate.
+type RankExperiment struct {
+	Enabled bool          `yaml:"enabled"`
+	ArmB    RankArmConfig `yaml:"arm_b"`
+}
+
+// RankArmConfig is one ranking configuration a RankExperiment arm scores
+// results with, mirroring the top-level RecencyHalfLife and
+// PathPenalties fields.
+type RankArmConfig struct {
+	RecencyHalfLife string        `yaml:"recency_half_life"`
+	PathPenalties   []PathPenalty `yaml:"path_penalties"`
+}
+
+// RepoCloneOptions overrides the local backend's default clone strategy
+// for one repo.
+type RepoCloneOptions struct {
+	Repo string `yaml:"repo"`
+
+	// Depth overrides Config.CloneDepth for this repo. -1 clones full
+	// history; 0 leaves the default depth in place.
+	Depth int `yaml:"depth"`
+
+	// Filter overrides Config.CloneFilter for this repo.
+	Filter string `yaml:"filter"`
+
+	// Sparse, when set, limits the checkout to these path patterns via
+	// git sparse-checkout, so only the parts of a large repo that are
+	// actually searched get materialized on disk.
+	Sparse []string `yaml:"sparse"`
+
+	// SSHKeyPath, when set, clones this repo over SSH using the deploy
+	// key at this path instead of the default HTTPS URL, for private
+	// repos the service otherwise can't reach.
+	SSHKeyPath string `yaml:"ssh_key_path"`
+}
+
+// PathPenalty downweights results whose path (or one of its segments)
+// matches Pattern (a filepath.Match glob) by multiplying its rank score
+// by Penalty. A Penalty of 0.5 halves the score; 0 excludes it from
+// ranking entirely without dropping it from the result set.
+type PathPenalty struct {
+	Pattern string  `yaml:"pattern"`
+	Penalty float64 `yaml:"penalty"`
+}
+
+// redactedSecret replaces a non-empty secret with a fixed placeholder,
+// so its length and value don't leak into a config dump.
+const redactedSecret = "REDACTED"
+
+// Redacted returns a copy of cfg with secret fields masked, safe to
+// print or serve from /admin/config for operators to verify what was
+// actually loaded (file + defaults) without exposing credentials.
+func (cfg Config) Redacted() Config {
+	redacted := cfg
+	if redacted.SlackSigningSecret != "" {
+		redacted.SlackSigningSecret = redactedSecret
+	}
+	if redacted.GithubWebhookSecret != "" {
+		redacted.GithubWebhookSecret = redactedSecret
+	}
+	if redacted.GithubToken != "" {
+		redacted.GithubToken = redactedSecret
+	}
+	if redacted.GitLabToken != "" {
+		redacted.GitLabToken = redactedSecret
+	}
+	if redacted.KMSStaticKey != "" {
+		redacted.KMSStaticKey = redactedSecret
+	}
+	if redacted.ServiceSigningSecret != "" {
+		redacted.ServiceSigningSecret = redactedSecret
+	}
+	if redacted.ErrorReporting.SentryDSN != "" {
+		redacted.ErrorReporting.SentryDSN = redactedSecret
+	}
+
+	if len(cfg.CloneOptions) > 0 {
+		redacted.CloneOptions = make([]RepoCloneOptions, len(cfg.CloneOptions))
+		for i, opt := range cfg.CloneOptions {
+			redacted.CloneOptions[i] = opt
+			if opt.SSHKeyPath != "" {
+				redacted.CloneOptions[i].SSHKeyPath = redactedSecret
+			}
+		}
+	}
+
+	if len(cfg.Profiles) > 0 {
+		redacted.Profiles = make([]Config, len(cfg.Profiles))
+		for i, profile := range cfg.Profiles {
+			redacted.Profiles[i] = profile.Redacted()
+		}
+	}
+
+	return redacted
+}
+
+// LoadConfig reads and parses the yaml config file at path.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse config file: %w", err)
+	}
+
+	applyConfigDefaults(&cfg)
+	for i := range cfg.Profiles {
+		applyConfigDefaults(&cfg.Profiles[i])
+	}
+
+	return cfg, nil
+}
+
+// applyConfigDefaults fills in the zero-value defaults for a single
+// profile's worth of config. Called once for the top-level config and
+// once per entry in Profiles, since each profile is otherwise a
+// complete, independent Config.
+func applyConfigDefaults(cfg *Config) {
+	if cfg.Port == 0 {
+		cfg.Port = 8000
+	}
+	if cfg.MaxContextLines == 0 {
+		cfg.MaxContextLines = defaultMaxContextLines
+	}
+	if len(cfg.ArchiveExtensions) == 0 {
+		cfg.ArchiveExtensions = defaultArchiveExtensions
+	}
+	if cfg.ArchiveMaxBytes == 0 {
+		cfg.ArchiveMaxBytes = defaultArchiveMaxBytes
+	}
+	if cfg.LFSMode == "" {
+		cfg.LFSMode = lfsModePointer
+	}
+	if cfg.CloneTimeout == "" {
+		cfg.CloneTimeout = defaultCloneTimeout.String()
+	}
+	if cfg.CloneDepth == 0 {
+		cfg.CloneDepth = defaultCloneDepth
+	}
+	if cfg.JobConcurrency == 0 {
+		cfg.JobConcurrency = defaultJobConcurrency
+	}
+	if cfg.GithubToken == "" {
+		cfg.GithubToken = os.Getenv("GITHUB_TOKEN")
+	}
+	if cfg.GitLabToken == "" {
+		cfg.GitLabToken = os.Getenv("GITLAB_TOKEN")
+	}
+	if cfg.ServiceSigningSecret == "" {
+		cfg.ServiceSigningSecret = os.Getenv("SERVICE_SIGNING_SECRET")
+	}
+	if cfg.GithubWebhookSecret == "" {
+		cfg.GithubWebhookSecret = os.Getenv("GITHUB_WEBHOOK_SECRET")
+	}
+	expandGitlabPrefixes(cfg)
+}
+
+// gitlabRepoPrefix marks a repos: entry as GitLab-hosted (e.g.
+// "gitlab:group/project"), so a config can mix GitHub and GitLab repos
+// in one repos: list without a RepoBackends entry for each.
+const gitlabRepoPrefix = "gitlab:"
+
+// expandGitlabPrefixes rewrites repos: entries carrying gitlabRepoPrefix
+// into their bare "group/project" form and registers an implicit
+// RepoBackend override for gitlab.com, unless the repo already has an
+// explicit RepoBackends entry (e.g. pointing it at a self-hosted
+// instance instead), which always wins.
+func expandGitlabPrefixes(cfg *Config) {
+	overridden := map[string]bool{}
+	for _, rb := range cfg.RepoBackends {
+		overridden[rb.Repo] = true
+	}
+
+	for i, repo := range cfg.Repos {
+		if !strings.HasPrefix(repo, gitlabRepoPrefix) {
+			continue
+		}
+
+		real := strings.TrimPrefix(repo, gitlabRepoPrefix)
+		cfg.Repos[i] = real
+		if overridden[real] {
+			continue
+		}
+		cfg.RepoBackends = append(cfg.RepoBackends, RepoBackend{Repo: real, Kind: backendGitlab, Token: cfg.GitLabToken})
+		overridden[real] = true
+	}
+}
+
+// reposInGroup returns cfg.Repos, filtered down to the repos
+// cfg.RepoGroups[group] lists, when group is non-empty. An empty group
+// (the common case: no group= filter given) returns cfg.Repos
+// unfiltered; an unrecognized group name returns no repos at all, same
+// as any other filter that matches nothing.
+func reposInGroup(cfg Config, group string) []string {
+	if group == "" {
+		return cfg.Repos
+	}
+
+	inGroup := map[string]bool{}
+	for _, repo := range cfg.RepoGroups[group] {
+		inGroup[repo] = true
+	}
+	var repos []string
+	for _, repo := range cfg.Repos {
+		if inGroup[repo] {
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
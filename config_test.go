@@ -0,0 +1,159 @@
+package main
+
+import "testing"
+
+func TestConfigRedactedMasksSlackSecret(t *testing.T) {
+	cfg := Config{SlackSigningSecret: "shh"}
+	redacted := cfg.Redacted()
+
+	if redacted.SlackSigningSecret != redactedSecret {
+		t.Errorf("SlackSigningSecret = %q, want %q", redacted.SlackSigningSecret, redactedSecret)
+	}
+}
+
+func TestConfigRedactedLeavesEmptySecretsEmpty(t *testing.T) {
+	cfg := Config{}
+	redacted := cfg.Redacted()
+
+	if redacted.SlackSigningSecret != "" {
+		t.Errorf("SlackSigningSecret = %q, want empty", redacted.SlackSigningSecret)
+	}
+}
+
+func TestConfigRedactedMasksGithubToken(t *testing.T) {
+	cfg := Config{GithubToken: "ghp_secret"}
+	redacted := cfg.Redacted()
+
+	if redacted.GithubToken != redactedSecret {
+		t.Errorf("GithubToken = %q, want %q", redacted.GithubToken, redactedSecret)
+	}
+}
+
+func TestApplyConfigDefaultsFallsBackToGithubTokenEnvVar(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "env-token")
+
+	cfg := Config{}
+	applyConfigDefaults(&cfg)
+
+	if cfg.GithubToken != "env-token" {
+		t.Errorf("GithubToken = %q, want %q", cfg.GithubToken, "env-token")
+	}
+}
+
+func TestApplyConfigDefaultsPrefersConfiguredGithubToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "env-token")
+
+	cfg := Config{GithubToken: "configured-token"}
+	applyConfigDefaults(&cfg)
+
+	if cfg.GithubToken != "configured-token" {
+		t.Errorf("GithubToken = %q, want %q", cfg.GithubToken, "configured-token")
+	}
+}
+
+func TestConfigRedactedMasksGitLabToken(t *testing.T) {
+	cfg := Config{GitLabToken: "glpat-secret"}
+	redacted := cfg.Redacted()
+
+	if redacted.GitLabToken != redactedSecret {
+		t.Errorf("GitLabToken = %q, want %q", redacted.GitLabToken, redactedSecret)
+	}
+}
+
+func TestExpandGitlabPrefixesRegistersOverride(t *testing.T) {
+	cfg := Config{Repos: []string{"bjatkin/golf-engine", "gitlab:bjatkin/gitlab-project"}, GitLabToken: "glpat-secret"}
+	applyConfigDefaults(&cfg)
+
+	want := []string{"bjatkin/golf-engine", "bjatkin/gitlab-project"}
+	if len(cfg.Repos) != 2 || cfg.Repos[0] != want[0] || cfg.Repos[1] != want[1] {
+		t.Errorf("Repos = %v, want %v (gitlab: prefix stripped)", cfg.Repos, want)
+	}
+
+	if len(cfg.RepoBackends) != 1 {
+		t.Fatalf("RepoBackends = %+v, want a single implicit override", cfg.RepoBackends)
+	}
+	rb := cfg.RepoBackends[0]
+	if rb.Repo != "bjatkin/gitlab-project" || rb.Kind != backendGitlab || rb.Token != "glpat-secret" {
+		t.Errorf("RepoBackends[0] = %+v, want gitlab override for bjatkin/gitlab-project using GitLabToken", rb)
+	}
+}
+
+func TestExpandGitlabPrefixesPrefersExplicitOverride(t *testing.T) {
+	cfg := Config{
+		Repos: []string{"gitlab:bjatkin/self-hosted"},
+		RepoBackends: []RepoBackend{
+			{Repo: "bjatkin/self-hosted", Kind: backendGitlab, BaseURL: "https://gitlab.example.com/api/v4"},
+		},
+	}
+	applyConfigDefaults(&cfg)
+
+	if len(cfg.RepoBackends) != 1 || cfg.RepoBackends[0].BaseURL != "https://gitlab.example.com/api/v4" {
+		t.Errorf("RepoBackends = %+v, want the explicit self-hosted override left untouched", cfg.RepoBackends)
+	}
+}
+
+func TestReposInGroupReturnsAllReposWhenGroupEmpty(t *testing.T) {
+	cfg := Config{
+		Repos:      []string{"bjatkin/web", "bjatkin/terraform"},
+		RepoGroups: map[string][]string{"frontend": {"bjatkin/web"}},
+	}
+	got := reposInGroup(cfg, "")
+	if len(got) != 2 || got[0] != "bjatkin/web" || got[1] != "bjatkin/terraform" {
+		t.Errorf("reposInGroup(\"\") = %v, want cfg.Repos unfiltered", got)
+	}
+}
+
+func TestReposInGroupFiltersToGroupMembers(t *testing.T) {
+	cfg := Config{
+		Repos: []string{"bjatkin/web", "bjatkin/terraform"},
+		RepoGroups: map[string][]string{
+			"frontend": {"bjatkin/web"},
+			"infra":    {"bjatkin/terraform"},
+		},
+	}
+	got := reposInGroup(cfg, "frontend")
+	if len(got) != 1 || got[0] != "bjatkin/web" {
+		t.Errorf("reposInGroup(\"frontend\") = %v, want [bjatkin/web]", got)
+	}
+}
+
+func TestReposInGroupReturnsNoneForUnknownGroup(t *testing.T) {
+	cfg := Config{
+		Repos:      []string{"bjatkin/web"},
+		RepoGroups: map[string][]string{"frontend": {"bjatkin/web"}},
+	}
+	got := reposInGroup(cfg, "does-not-exist")
+	if len(got) != 0 {
+		t.Errorf("reposInGroup(\"does-not-exist\") = %v, want none", got)
+	}
+}
+
+func TestConfigRedactedMasksKMSStaticKey(t *testing.T) {
+	cfg := Config{KMSStaticKey: "shh"}
+	redacted := cfg.Redacted()
+
+	if redacted.KMSStaticKey != redactedSecret {
+		t.Errorf("KMSStaticKey = %q, want %q", redacted.KMSStaticKey, redactedSecret)
+	}
+}
+
+func TestConfigRedactedMasksSSHKeyPath(t *testing.T) {
+	cfg := Config{CloneOptions: []RepoCloneOptions{{Repo: "o/r", SSHKeyPath: "/etc/deploy_key"}}}
+	redacted := cfg.Redacted()
+
+	if redacted.CloneOptions[0].SSHKeyPath != redactedSecret {
+		t.Errorf("SSHKeyPath = %q, want %q", redacted.CloneOptions[0].SSHKeyPath, redactedSecret)
+	}
+	if cfg.CloneOptions[0].SSHKeyPath != "/etc/deploy_key" {
+		t.Error("Redacted mutated the original config's CloneOptions")
+	}
+}
+
+func TestConfigRedactedRecursesIntoProfiles(t *testing.T) {
+	cfg := Config{Profiles: []Config{{Name: "staging", SlackSigningSecret: "shh"}}}
+	redacted := cfg.Redacted()
+
+	if redacted.Profiles[0].SlackSigningSecret != redactedSecret {
+		t.Errorf("Profiles[0].SlackSigningSecret = %q, want %q", redacted.Profiles[0].SlackSigningSecret, redactedSecret)
+	}
+}
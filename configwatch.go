@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"time"
+)
+
+// defaultConfigWatchInterval is how often the config file's resolved
+// (symlink-following) target is checked for a change, when
+// ConfigWatchInterval isn't set.
+//
+// Kubernetes rotates a mounted ConfigMap/Secret by atomically
+// re-pointing a "..data" symlink at a new timestamped directory; the
+// file the service actually reads is itself a symlink into "..data".
+// fsnotify watching that file's directory entry sees no event, since
+// the entry never changes — only what it resolves to does. Polling
+// filepath.EvalSymlinks catches it either way.
+const defaultConfigWatchInterval = 30 * time.Second
+
+// watchConfig polls path's resolved target every interval and calls
+// onChange with the freshly loaded Config whenever it changes. It never
+// returns; run it in its own goroutine.
+func watchConfig(path string, interval time.Duration, onChange func(Config)) {
+	if interval <= 0 {
+		interval = defaultConfigWatchInterval
+	}
+
+	last, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		last = path
+	}
+
+	for range time.Tick(interval) {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			log.Printf("config watch: resolve %s: %v", path, err)
+			continue
+		}
+		if resolved == last {
+			continue
+		}
+		last = resolved
+
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			log.Printf("config watch: reload %s: %v", path, err)
+			continue
+		}
+		onChange(cfg)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestWatchConfigDetectsSymlinkSwap simulates the Kubernetes
+// ConfigMap/Secret rotation pattern: the watched path is a symlink,
+// and rotation re-points it at a new target directory rather than
+// modifying the original file in place.
+func TestWatchConfigDetectsSymlinkSwap(t *testing.T) {
+	dir := t.TempDir()
+
+	targetA := filepath.Join(dir, "v1")
+	targetB := filepath.Join(dir, "v2")
+	if err := os.Mkdir(targetA, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(targetB, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	confA := filepath.Join(targetA, "config.yaml")
+	confB := filepath.Join(targetB, "config.yaml")
+	if err := os.WriteFile(confA, []byte("port: 9001\nrepos: [o/a]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(confB, []byte("port: 9002\nrepos: [o/b]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	link := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(confA, link); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan Config, 1)
+	go watchConfig(link, 20*time.Millisecond, func(cfg Config) { changed <- cfg })
+
+	// Give watchConfig time to resolve its baseline target before the
+	// symlink is rotated, so the swap below is guaranteed to be seen as
+	// a change rather than racing the goroutine's own startup.
+	time.Sleep(50 * time.Millisecond)
+
+	// Rotate the symlink the way kubelet does: point it at the new
+	// version rather than editing the file it currently targets.
+	tmpLink := link + ".tmp"
+	if err := os.Symlink(confB, tmpLink); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpLink, link); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case cfg := <-changed:
+		if cfg.Port != 9002 {
+			t.Errorf("Port = %d, want 9002", cfg.Port)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for watchConfig to detect the symlink swap")
+	}
+}
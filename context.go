@@ -0,0 +1,30 @@
+package main
+
+// trimContext trims lines down to at most contextLines of surrounding
+// context above and below each matched line (a line with Offsets set).
+func trimContext(lines []Line, contextLines int) []Line {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if len(line.Offsets) == 0 {
+			continue
+		}
+		lo, hi := i-contextLines, i+contextLines
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(lines)-1 {
+			hi = len(lines) - 1
+		}
+		for j := lo; j <= hi; j++ {
+			keep[j] = true
+		}
+	}
+
+	var trimmed []Line
+	for i, line := range lines {
+		if keep[i] {
+			trimmed = append(trimmed, line)
+		}
+	}
+	return trimmed
+}
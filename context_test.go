@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestTrimContext(t *testing.T) {
+	lines := []Line{
+		{Number: 1, Text: "a"},
+		{Number: 2, Text: "b"},
+		{Number: 3, Text: "c", Offsets: []Offset{{Start: 0, End: 1}}},
+		{Number: 4, Text: "d"},
+		{Number: 5, Text: "e"},
+	}
+
+	trimmed := trimContext(lines, 1)
+	if len(trimmed) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(trimmed))
+	}
+	if trimmed[0].Number != 2 || trimmed[2].Number != 4 {
+		t.Errorf("unexpected trimmed lines: %+v", trimmed)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+// collapseDuplicates merges results that share the same blob SHA (the
+// identical file content, typically across forks or mirrors) into a
+// single result listing every location it was found at. Backends that
+// don't report a SHA (e.g. a non-GitHub provider) are instead deduped by
+// FileURL, so the same file surfacing from more than one provider still
+// collapses into one result.
+func collapseDuplicates(results []Result) []Result {
+	byKey := map[string]int{} // dedupe key -> index into collapsed
+
+	var collapsed []Result
+	for _, r := range results {
+		key := r.SHA
+		if key == "" {
+			key = r.URL
+		}
+		if key == "" {
+			collapsed = append(collapsed, r)
+			continue
+		}
+
+		if idx, ok := byKey[key]; ok {
+			collapsed[idx].Locations = append(collapsed[idx].Locations, Location{Repo: r.Repo, Path: r.Path, URL: r.URL})
+			continue
+		}
+
+		r.Locations = []Location{{Repo: r.Repo, Path: r.Path, URL: r.URL}}
+		byKey[key] = len(collapsed)
+		collapsed = append(collapsed, r)
+	}
+
+	return collapsed
+}
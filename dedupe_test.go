@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestCollapseDuplicates(t *testing.T) {
+	results := []Result{
+		{Repo: "a/x", Path: "lib.go", URL: "u1", SHA: "abc"},
+		{Repo: "a/x-fork", Path: "lib.go", URL: "u2", SHA: "abc"},
+		{Repo: "b/y", Path: "main.go", URL: "u3", SHA: "def"},
+	}
+
+	collapsed := collapseDuplicates(results)
+	if len(collapsed) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(collapsed))
+	}
+	if len(collapsed[0].Locations) != 2 {
+		t.Errorf("expected 2 locations for shared SHA, got %d", len(collapsed[0].Locations))
+	}
+	if len(collapsed[1].Locations) != 1 {
+		t.Errorf("expected 1 location for unique SHA, got %d", len(collapsed[1].Locations))
+	}
+}
+
+func TestCollapseDuplicatesFallsBackToURLWithoutSHA(t *testing.T) {
+	results := []Result{
+		{Repo: "a/x", Path: "lib.go", URL: "https://github.com/a/x/blob/main/lib.go"},
+		{Repo: "a/mirror", Path: "lib.go", URL: "https://github.com/a/x/blob/main/lib.go"},
+	}
+
+	collapsed := collapseDuplicates(results)
+	if len(collapsed) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(collapsed))
+	}
+	if len(collapsed[0].Locations) != 2 {
+		t.Errorf("expected 2 locations for shared URL, got %d", len(collapsed[0].Locations))
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// diskQuota evicts least-recently-searched repo clones from disk once
+// the local backend's data directory grows past a configured budget,
+// keeping index metadata (mirror status) so an evicted repo just
+// re-clones the next time it's searched.
+type diskQuota struct {
+	mu         sync.Mutex
+	lastAccess map[string]time.Time
+}
+
+func newDiskQuota() *diskQuota {
+	return &diskQuota{lastAccess: map[string]time.Time{}}
+}
+
+// touch records repo as just having been accessed, so it's the last
+// thing considered for eviction.
+func (q *diskQuota) touch(repo string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lastAccess[repo] = time.Now()
+}
+
+// Enforce removes the least-recently-accessed repo clones under
+// idx.dataDir until its total size is at or under quotaBytes. quotaBytes
+// of 0 disables enforcement.
+func (q *diskQuota) Enforce(idx *localIndex, quotaBytes int64) error {
+	if quotaBytes <= 0 {
+		return nil
+	}
+
+	repos, err := clonedRepos(idx.dataDir)
+	if err != nil {
+		return err
+	}
+
+	sizes := make(map[string]int64, len(repos))
+	var total int64
+	for _, repo := range repos {
+		size, err := dirSize(idx.clonePath(repo))
+		if err != nil {
+			continue
+		}
+		sizes[repo] = size
+		total += size
+	}
+	if total <= quotaBytes {
+		return nil
+	}
+
+	q.mu.Lock()
+	sort.Slice(repos, func(i, j int) bool {
+		return q.lastAccess[repos[i]].Before(q.lastAccess[repos[j]])
+	})
+	q.mu.Unlock()
+
+	for _, repo := range repos {
+		if total <= quotaBytes {
+			break
+		}
+		if err := os.RemoveAll(idx.clonePath(repo)); err != nil {
+			continue
+		}
+		total -= sizes[repo]
+
+		q.mu.Lock()
+		delete(q.lastAccess, repo)
+		q.mu.Unlock()
+	}
+
+	return nil
+}
+
+// clonedRepos lists every "owner/name" repo currently cloned under
+// dataDir.
+func clonedRepos(dataDir string) ([]string, error) {
+	owners, err := os.ReadDir(dataDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []string
+	for _, owner := range owners {
+		if !owner.IsDir() {
+			continue
+		}
+		names, err := os.ReadDir(filepath.Join(dataDir, owner.Name()))
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			if !name.IsDir() {
+				continue
+			}
+			repos = append(repos, owner.Name()+"/"+name.Name())
+		}
+	}
+	return repos, nil
+}
+
+// dirSize returns the total size in bytes of every regular file under
+// dir.
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
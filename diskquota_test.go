@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiskQuotaEvictsLeastRecentlyAccessed(t *testing.T) {
+	dataDir := t.TempDir()
+	idx := newLocalIndex(dataDir)
+
+	writeFile(t, filepath.Join(dataDir, "owner", "old", ".git", "HEAD"), 100)
+	writeFile(t, filepath.Join(dataDir, "owner", "new", ".git", "HEAD"), 100)
+
+	idx.quota.touch("owner/old")
+	idx.quota.touch("owner/new")
+
+	if err := idx.quota.Enforce(idx, 150); err != nil {
+		t.Fatalf("Enforce: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "owner", "old")); !os.IsNotExist(err) {
+		t.Error("expected the least-recently-accessed repo to be evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "owner", "new")); err != nil {
+		t.Error("expected the more recently accessed repo to survive")
+	}
+}
+
+func TestDiskQuotaDisabledByZero(t *testing.T) {
+	dataDir := t.TempDir()
+	idx := newLocalIndex(dataDir)
+
+	writeFile(t, filepath.Join(dataDir, "owner", "repo", ".git", "HEAD"), 100)
+
+	if err := idx.quota.Enforce(idx, 0); err != nil {
+		t.Fatalf("Enforce: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "owner", "repo")); err != nil {
+		t.Error("expected quota enforcement to be a no-op when disabled")
+	}
+}
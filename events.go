@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is one item on the service's activity stream, served over SSE
+// at /admin/events.
+type Event struct {
+	Type string    `json:"type"`
+	Data any       `json:"data,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+// eventBus fans out published events to every current subscriber.
+// Subscribers that fall behind have events dropped rather than blocking
+// publishers, since activity events are informational, not a queue that
+// must be delivered in full.
+type eventBus struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: map[chan Event]struct{}{}}
+}
+
+// Subscribe registers a new listener and returns a channel of events
+// published from this point on, plus an unsubscribe func the caller must
+// call when done listening.
+func (b *eventBus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 32)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+// Publish sends an event of the given type to every current subscriber.
+func (b *eventBus) Publish(eventType string, data any) {
+	event := Event{Type: eventType, Data: data, Time: time.Now()}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default: // subscriber is behind; drop rather than block publishers
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestEventBusPublishDeliversToSubscriber(t *testing.T) {
+	bus := newEventBus()
+	sub, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	bus.Publish("search_executed", map[string]string{"term": "needle"})
+
+	event := <-sub
+	if event.Type != "search_executed" {
+		t.Errorf("Type = %q, want search_executed", event.Type)
+	}
+}
+
+func TestEventBusUnsubscribeStopsDelivery(t *testing.T) {
+	bus := newEventBus()
+	sub, unsubscribe := bus.Subscribe()
+	unsubscribe()
+
+	bus.Publish("search_executed", nil)
+
+	if _, ok := <-sub; ok {
+		t.Error("expected the channel to be closed after unsubscribe")
+	}
+}
+
+func TestEventBusDropsWhenSubscriberFull(t *testing.T) {
+	bus := newEventBus()
+	sub, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < 100; i++ {
+		bus.Publish("search_executed", nil)
+	}
+
+	if len(sub) == 0 {
+		t.Error("expected some events to have been buffered")
+	}
+}
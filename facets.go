@@ -0,0 +1,50 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Facets holds aggregate counts over a result set so a client can offer
+// filter sidebars without walking every result itself.
+type Facets struct {
+	Repos      map[string]int `json:"repos,omitempty"`
+	Languages  map[string]int `json:"languages,omitempty"`
+	PathPrefix map[string]int `json:"path_prefixes,omitempty"`
+	Extensions map[string]int `json:"extensions,omitempty"`
+}
+
+// buildFacets computes facet counts across the full match set.
+func buildFacets(results []Result) Facets {
+	f := Facets{
+		Repos:      map[string]int{},
+		Languages:  map[string]int{},
+		PathPrefix: map[string]int{},
+		Extensions: map[string]int{},
+	}
+
+	for _, r := range results {
+		f.Repos[r.Repo]++
+		if r.FileType.Language != "" {
+			f.Languages[r.FileType.Language]++
+		}
+		if prefix := topLevelDir(r.Path); prefix != "" {
+			f.PathPrefix[prefix]++
+		}
+		if ext := filepath.Ext(r.Path); ext != "" {
+			f.Extensions[ext]++
+		}
+	}
+
+	return f
+}
+
+// topLevelDir returns the first path segment of path, or "" if path has
+// no directory component.
+func topLevelDir(path string) string {
+	idx := strings.Index(path, "/")
+	if idx == -1 {
+		return ""
+	}
+	return path[:idx]
+}
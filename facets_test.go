@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestBuildFacets(t *testing.T) {
+	results := []Result{
+		{Repo: "a/x", Path: "src/main.go", FileType: FileType{Language: "Go"}},
+		{Repo: "a/x", Path: "src/util.go", FileType: FileType{Language: "Go"}},
+		{Repo: "b/y", Path: "lib/main.py", FileType: FileType{Language: "Python"}},
+	}
+
+	f := buildFacets(results)
+
+	if f.Repos["a/x"] != 2 || f.Repos["b/y"] != 1 {
+		t.Errorf("Repos = %v", f.Repos)
+	}
+	if f.Languages["Go"] != 2 || f.Languages["Python"] != 1 {
+		t.Errorf("Languages = %v", f.Languages)
+	}
+	if f.PathPrefix["src"] != 2 || f.PathPrefix["lib"] != 1 {
+		t.Errorf("PathPrefix = %v", f.PathPrefix)
+	}
+	if f.Extensions[".go"] != 2 || f.Extensions[".py"] != 1 {
+		t.Errorf("Extensions = %v", f.Extensions)
+	}
+}
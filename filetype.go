@@ -0,0 +1,95 @@
+package main
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// languageByExt maps common file extensions to a human readable
+// language name. This is intentionally small; unknown extensions just
+// fall back to the raw extension.
+var languageByExt = map[string]string{
+	".go":   "Go",
+	".py":   "Python",
+	".js":   "JavaScript",
+	".ts":   "TypeScript",
+	".rb":   "Ruby",
+	".rs":   "Rust",
+	".java": "Java",
+	".c":    "C",
+	".h":    "C",
+	".cpp":  "C++",
+	".md":   "Markdown",
+	".yaml": "YAML",
+	".yml":  "YAML",
+	".json": "JSON",
+	".sh":   "Shell",
+}
+
+// FileType describes the detected language and MIME type of a search
+// result, so clients can pick an appropriate renderer.
+type FileType struct {
+	Language string `json:"language,omitempty"`
+	MIME     string `json:"mime,omitempty"`
+}
+
+// detectFileType determines a result's language and MIME type from its
+// path extension, falling back to content sniffing over the snippet
+// text when the extension is unrecognized or missing.
+func detectFileType(path, content string) FileType {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	ft := FileType{Language: languageByExt[ext]}
+
+	if m := mime.TypeByExtension(ext); m != "" {
+		ft.MIME = m
+	} else {
+		ft.MIME = http.DetectContentType([]byte(content))
+	}
+
+	return ft
+}
+
+// knownLanguages lists every language detectFileType can recognize, for
+// validating a lang= query parameter.
+func knownLanguages() []string {
+	seen := map[string]bool{}
+	var langs []string
+	for _, lang := range languageByExt {
+		key := strings.ToLower(lang)
+		if !seen[key] {
+			seen[key] = true
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// isKnownLanguage reports whether lang (matched case-insensitively, e.g.
+// "go" matches "Go") is one detectFileType can recognize.
+func isKnownLanguage(lang string) bool {
+	for _, known := range knownLanguages() {
+		if strings.EqualFold(known, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByLanguage keeps only results whose detected language matches
+// lang case-insensitively. It's a backstop for lang=, since the
+// language: qualifier appended to the backend query isn't honored by
+// every provider.
+func filterByLanguage(results []Result, lang string) []Result {
+	var out []Result
+	for _, r := range results {
+		if strings.EqualFold(r.FileType.Language, lang) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
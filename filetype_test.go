@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestDetectFileType(t *testing.T) {
+	ft := detectFileType("main.go", "package main")
+	if ft.Language != "Go" {
+		t.Errorf("Language = %q, want Go", ft.Language)
+	}
+	if ft.MIME == "" {
+		t.Errorf("MIME should not be empty")
+	}
+}
+
+func TestDetectFileTypeUnknownExtension(t *testing.T) {
+	ft := detectFileType("Makefile", "all:\n\tgo build .\n")
+	if ft.Language != "" {
+		t.Errorf("Language = %q, want empty for unknown extension", ft.Language)
+	}
+	if ft.MIME == "" {
+		t.Errorf("MIME should fall back to content sniffing")
+	}
+}
+
+func TestIsKnownLanguageCaseInsensitive(t *testing.T) {
+	if !isKnownLanguage("go") {
+		t.Error("expected \"go\" to match the known language \"Go\"")
+	}
+	if isKnownLanguage("cobol") {
+		t.Error("expected \"cobol\" to not be a known language")
+	}
+}
+
+func TestFilterByLanguage(t *testing.T) {
+	results := []Result{
+		{Path: "main.go", FileType: FileType{Language: "Go"}},
+		{Path: "readme.md", FileType: FileType{Language: "Markdown"}},
+	}
+
+	filtered := filterByLanguage(results, "go")
+	if len(filtered) != 1 || filtered[0].Path != "main.go" {
+		t.Errorf("filtered = %+v, want just main.go", filtered)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import "sync"
+
+// FlagSemanticSearch and FlagHybridRanking name the experimental
+// behaviors this flag system was introduced for. Neither is implemented
+// in this build yet — ranking still only ever runs the keyword-based
+// rankResults pipeline — but the flags exist so operators can wire up
+// their rollout tooling (and toggle it live via /admin/flags) ahead of
+// the feature landing, rather than needing a config change and restart
+// on the day it does.
+const (
+	FlagSemanticSearch = "semantic_search"
+	FlagHybridRanking  = "hybrid_ranking"
+)
+
+// featureFlagStore tracks which experimental features are enabled,
+// seeded from Config.Flags at startup and toggleable at runtime via
+// GET/POST /admin/flags, so a feature can be rolled out gradually
+// without a config change and restart.
+type featureFlagStore struct {
+	mu      sync.Mutex
+	enabled map[string]bool
+}
+
+// newFeatureFlagStore seeds a featureFlagStore from a config's flags:
+// section. The seed map is copied, not aliased.
+func newFeatureFlagStore(seed map[string]bool) *featureFlagStore {
+	enabled := map[string]bool{}
+	for name, on := range seed {
+		enabled[name] = on
+	}
+	return &featureFlagStore{enabled: enabled}
+}
+
+// Enabled reports whether name is currently on. An unknown or never-set
+// flag reports false.
+func (s *featureFlagStore) Enabled(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enabled[name]
+}
+
+// Set turns name on or off.
+func (s *featureFlagStore) Set(name string, on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enabled[name] = on
+}
+
+// List returns every flag that's ever been explicitly set, on or off.
+func (s *featureFlagStore) List() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]bool, len(s.enabled))
+	for name, on := range s.enabled {
+		out[name] = on
+	}
+	return out
+}
+
+// Import restores flag state from a state export bundle, overwriting
+// any flag it names.
+func (s *featureFlagStore) Import(flags map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, on := range flags {
+		s.enabled[name] = on
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestFeatureFlagStoreEnabledDefaultsFalse(t *testing.T) {
+	fs := newFeatureFlagStore(nil)
+
+	if fs.Enabled(FlagSemanticSearch) {
+		t.Error("expected an unset flag to default to disabled")
+	}
+}
+
+func TestFeatureFlagStoreSeedIsCopiedNotAliased(t *testing.T) {
+	seed := map[string]bool{FlagHybridRanking: true}
+	fs := newFeatureFlagStore(seed)
+
+	seed[FlagHybridRanking] = false
+	if !fs.Enabled(FlagHybridRanking) {
+		t.Error("expected mutating the seed map after construction to not affect the store")
+	}
+}
+
+func TestFeatureFlagStoreSet(t *testing.T) {
+	fs := newFeatureFlagStore(nil)
+
+	fs.Set(FlagSemanticSearch, true)
+	if !fs.Enabled(FlagSemanticSearch) {
+		t.Error("expected Set(true) to enable the flag")
+	}
+
+	fs.Set(FlagSemanticSearch, false)
+	if fs.Enabled(FlagSemanticSearch) {
+		t.Error("expected Set(false) to disable the flag")
+	}
+}
+
+func TestFeatureFlagStoreListReturnsCopy(t *testing.T) {
+	fs := newFeatureFlagStore(map[string]bool{FlagHybridRanking: true})
+
+	list := fs.List()
+	list[FlagHybridRanking] = false
+	if !fs.Enabled(FlagHybridRanking) {
+		t.Error("expected mutating the returned List() map to not affect the store")
+	}
+}
+
+func TestFeatureFlagStoreImportOverwritesNamedFlags(t *testing.T) {
+	fs := newFeatureFlagStore(map[string]bool{FlagSemanticSearch: true})
+
+	fs.Import(map[string]bool{FlagSemanticSearch: false, FlagHybridRanking: true})
+
+	if fs.Enabled(FlagSemanticSearch) {
+		t.Error("expected Import to overwrite an existing flag")
+	}
+	if !fs.Enabled(FlagHybridRanking) {
+		t.Error("expected Import to add a new flag")
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// minifiedLineLen is the line length past which a .js file is treated
+// as minified and re-flowed before indexing.
+const minifiedLineLen = 300
+
+// flattenContent rewrites structured or minified source into a form
+// where matches land on meaningful lines with usable snippets, instead
+// of one giant line (minified JS) or a wall of JSON escapes (notebooks).
+// Files it doesn't recognize are returned unchanged.
+func flattenContent(path string, data []byte) []byte {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ipynb":
+		if flat, ok := flattenNotebook(data); ok {
+			return flat
+		}
+	case ".js":
+		if looksMinified(data) {
+			return reflowMinifiedJS(data)
+		}
+	}
+	return data
+}
+
+type notebook struct {
+	Cells []struct {
+		CellType string   `json:"cell_type"`
+		Source   []string `json:"source"`
+	} `json:"cells"`
+}
+
+// flattenNotebook extracts each cell's source lines from a Jupyter
+// .ipynb file (which stores source as a JSON array of line fragments),
+// so matches land on the original source line instead of inside a JSON
+// string literal.
+func flattenNotebook(data []byte) ([]byte, bool) {
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, false
+	}
+
+	var out strings.Builder
+	for _, cell := range nb.Cells {
+		out.WriteString("# cell: " + cell.CellType + "\n")
+		for _, line := range cell.Source {
+			out.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				out.WriteString("\n")
+			}
+		}
+	}
+	return []byte(out.String()), true
+}
+
+// looksMinified reports whether data contains a line long enough that
+// it was very likely produced by a minifier rather than a human.
+func looksMinified(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		if len(line) > minifiedLineLen {
+			return true
+		}
+	}
+	return false
+}
+
+// reflowMinifiedJS breaks a minified JS file back into one statement
+// per line by inserting newlines after the characters that typically
+// end a JS statement or block. It's a heuristic, not a real parser, but
+// it's enough to turn one giant line into usable snippets.
+func reflowMinifiedJS(data []byte) []byte {
+	var out strings.Builder
+	for _, r := range string(data) {
+		out.WriteRune(r)
+		if r == ';' || r == '{' || r == '}' {
+			out.WriteRune('\n')
+		}
+	}
+	return []byte(out.String())
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlattenNotebook(t *testing.T) {
+	nb := `{"cells":[{"cell_type":"code","source":["import os\n","print(os.getcwd())"]}]}`
+
+	flat := flattenContent("nb.ipynb", []byte(nb))
+	if !strings.Contains(string(flat), "print(os.getcwd())") {
+		t.Errorf("flattened notebook missing source line: %s", flat)
+	}
+	if strings.Contains(string(flat), `"cell_type"`) {
+		t.Errorf("flattened notebook should not contain raw JSON: %s", flat)
+	}
+}
+
+func TestReflowMinifiedJS(t *testing.T) {
+	minified := strings.Repeat("a", minifiedLineLen+1) + ";function f(){return 1;}"
+
+	flat := flattenContent("bundle.js", []byte(minified))
+	lines := strings.Split(string(flat), "\n")
+	if len(lines) < 2 {
+		t.Errorf("expected minified JS to be reflowed onto multiple lines, got %d", len(lines))
+	}
+}
+
+func TestFlattenContentUnrecognized(t *testing.T) {
+	data := []byte("package main\n")
+	flat := flattenContent("main.go", data)
+	if string(flat) != string(data) {
+		t.Errorf("expected unrecognized file type to pass through unchanged")
+	}
+}
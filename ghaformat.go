@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// writeGHAFormat writes results as GitHub Actions workflow commands
+// (https://docs.github.com/actions/using-workflows/workflow-commands-for-github-actions),
+// one ::warning per matched line, so a workflow step calling the service
+// can annotate the PR directly instead of parsing JSON.
+func writeGHAFormat(w io.Writer, results []Result) {
+	for _, result := range results {
+		for _, line := range result.Lines {
+			fmt.Fprintf(w, "::warning file=%s,line=%d::match in %s\n", result.Path, line.Number, result.Repo)
+		}
+	}
+}
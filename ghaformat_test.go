@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteGHAFormat(t *testing.T) {
+	results := []Result{
+		{
+			Repo: "owner/repo",
+			Path: "src/main.go",
+			Lines: []Line{
+				{Number: 10, Text: "needle here"},
+				{Number: 20, Text: "another needle"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	writeGHAFormat(&buf, results)
+
+	out := buf.String()
+	if !strings.Contains(out, "::warning file=src/main.go,line=10::match in owner/repo") {
+		t.Errorf("output missing expected annotation for line 10, got: %s", out)
+	}
+	if !strings.Contains(out, "::warning file=src/main.go,line=20::match in owner/repo") {
+		t.Errorf("output missing expected annotation for line 20, got: %s", out)
+	}
+}
+
+func TestWriteGHAFormatEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	writeGHAFormat(&buf, nil)
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output for no results, got: %s", buf.String())
+	}
+}
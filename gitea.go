@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultGiteaBaseURL is a placeholder; Gitea and Forgejo are almost
+// always self-hosted, so RepoBackend.BaseURL is effectively required for
+// this backend. This default only avoids an empty request URL if a
+// config omits it.
+const defaultGiteaBaseURL = "https://try.gitea.io/api/v1"
+
+// giteaCodeSearchResult is one hit from a Gitea/Forgejo instance's
+// repo-scoped code search API.
+type giteaCodeSearchResult struct {
+	Filename string `json:"filename"`
+	Content  string `json:"content"`
+	CommitID string `json:"commit_id"`
+}
+
+// giteaCodeSearchResponse wraps the list of hits Gitea's code search
+// endpoint returns, along with the total match count for pagination.
+type giteaCodeSearchResponse struct {
+	Total   int                     `json:"total_count"`
+	Results []giteaCodeSearchResult `json:"data"`
+}
+
+// giteaClient queries a Gitea or Forgejo instance's repo-scoped code
+// search API and adapts results into the shared githubSearchItem shape.
+// Gitea and Forgejo share this API, so one client covers both.
+type giteaClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+func newGiteaClientFor(baseURL, token string) *giteaClient {
+	if baseURL == "" {
+		baseURL = defaultGiteaBaseURL
+	}
+	return &giteaClient{httpClient: http.DefaultClient, baseURL: baseURL, token: token}
+}
+
+// splitGiteaRepo splits repo (an "owner/name" path, matching every other
+// backend's repo naming) into its owner and name, which Gitea's search
+// endpoint is scoped to.
+func splitGiteaRepo(repo string) (owner, name string, err error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" {
+		return "", "", fmt.Errorf("invalid gitea repo %q: want owner/name", repo)
+	}
+	return owner, name, nil
+}
+
+// searchCode runs a code search scoped to repo (an "owner/name" path) and
+// adapts the results into githubSearchItems. page and perPage are
+// forwarded as Gitea's own page/limit query parameters when positive.
+// The total match count comes from the response's total_count field.
+func (c *giteaClient) searchCode(term, repo string, page, perPage int) ([]githubSearchItem, int, error) {
+	owner, name, err := splitGiteaRepo(repo)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	reqURL := fmt.Sprintf("%s/repos/%s/%s/search/code?q=%s",
+		c.baseURL, url.PathEscape(owner), url.PathEscape(name), url.QueryEscape(term))
+	if page > 0 {
+		reqURL += "&page=" + strconv.Itoa(page)
+	}
+	if perPage > 0 {
+		reqURL += "&limit=" + strconv.Itoa(perPage)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("build gitea search request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gitea search request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("gitea search request: unexpected status %s", resp.Status)
+	}
+
+	var parsed giteaCodeSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, 0, fmt.Errorf("decode gitea search response: %w", err)
+	}
+
+	items := make([]githubSearchItem, len(parsed.Results))
+	for i, hit := range parsed.Results {
+		items[i] = githubSearchItem{
+			Name:    pathBase(hit.Filename),
+			Path:    hit.Filename,
+			HTMLURL: fmt.Sprintf("%s/%s/%s/src/commit/%s/%s", c.webBaseURL(), owner, name, hit.CommitID, hit.Filename),
+			TextMatches: []githubTextMatch{
+				{Fragment: hit.Content},
+			},
+		}
+		items[i].Repository.FullName = repo
+	}
+
+	total := parsed.Total
+	if total == 0 {
+		total = len(items)
+	}
+	return items, total, nil
+}
+
+// webBaseURL strips Gitea/Forgejo's "/api/v1" API suffix from the
+// client's base URL to get the web URL a human would browse, so search
+// hits carry a usable click-through link.
+func (c *giteaClient) webBaseURL() string {
+	return strings.TrimSuffix(c.baseURL, "/api/v1")
+}
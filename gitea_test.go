@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGiteaClientSearchCode(t *testing.T) {
+	var gotPath, gotQuery, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.Query().Get("q")
+		gotAuth = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(giteaCodeSearchResponse{
+			Total: 1,
+			Results: []giteaCodeSearchResult{
+				{Filename: "internal/auth.go", Content: "func Login() {}", CommitID: "abc123"},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	client := newGiteaClientFor(srv.URL, "gitea-token")
+	items, total, err := client.searchCode("Login", "myorg/myrepo", 0, 0)
+	if err != nil {
+		t.Fatalf("searchCode: %v", err)
+	}
+
+	if gotPath != "/repos/myorg/myrepo/search/code" {
+		t.Errorf("gotPath = %q, want /repos/myorg/myrepo/search/code", gotPath)
+	}
+	if gotQuery != "Login" {
+		t.Errorf("gotQuery = %q, want Login", gotQuery)
+	}
+	if gotAuth != "token gitea-token" {
+		t.Errorf("gotAuth = %q, want token gitea-token", gotAuth)
+	}
+
+	if total != 1 {
+		t.Errorf("total = %d, want 1", total)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	item := items[0]
+	if item.Name != "auth.go" || item.Path != "internal/auth.go" {
+		t.Errorf("item = %+v, want Name=auth.go Path=internal/auth.go", item)
+	}
+	if item.Repository.FullName != "myorg/myrepo" {
+		t.Errorf("item.Repository.FullName = %q, want myorg/myrepo", item.Repository.FullName)
+	}
+	if want := srv.URL + "/myorg/myrepo/src/commit/abc123/internal/auth.go"; item.HTMLURL != want {
+		t.Errorf("item.HTMLURL = %q, want %q", item.HTMLURL, want)
+	}
+	if len(item.TextMatches) != 1 || item.TextMatches[0].Fragment != "func Login() {}" {
+		t.Errorf("item.TextMatches = %+v, want fragment %q", item.TextMatches, "func Login() {}")
+	}
+}
+
+func TestNewGiteaClientForDefaultsBaseURL(t *testing.T) {
+	client := newGiteaClientFor("", "")
+	if client.baseURL != defaultGiteaBaseURL {
+		t.Errorf("baseURL = %q, want %q", client.baseURL, defaultGiteaBaseURL)
+	}
+}
+
+func TestSplitGiteaRepoRejectsMissingSlash(t *testing.T) {
+	if _, _, err := splitGiteaRepo("no-slash"); err == nil {
+		t.Fatal("expected an error for a repo without an owner/name slash")
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const githubSearchCodeURL = "https://api.github.com/search/code"
+
+// githubMaxQueryLen mirrors GitHub code search's own query length limit;
+// a longer query fails outright with "query must be 256 characters or
+// less" instead of returning partial results.
+const githubMaxQueryLen = 256
+
+// githubRateLimit is the most recently observed GitHub API rate limit
+// state for a client, parsed from the X-RateLimit-Remaining and
+// X-RateLimit-Reset response headers.
+type githubRateLimit struct {
+	Remaining int
+	Reset     time.Time
+}
+
+// githubClient queries the GitHub code search API.
+type githubClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+
+	rateLimitMu sync.Mutex
+	rateLimit   githubRateLimit
+}
+
+func newGithubClient() *githubClient {
+	return &githubClient{httpClient: http.DefaultClient, baseURL: githubSearchCodeURL}
+}
+
+// newGithubClientFor builds a githubClient for a repo whose RepoBackend
+// override points at a GitHub Enterprise instance (baseURL) and/or
+// authenticates with token. Empty baseURL falls back to github.com.
+func newGithubClientFor(baseURL, token string) *githubClient {
+	if baseURL == "" {
+		baseURL = githubSearchCodeURL
+	}
+	return &githubClient{httpClient: http.DefaultClient, baseURL: baseURL, token: token}
+}
+
+// githubTextMatch is a single matched fragment returned by the GitHub
+// code search API.
+type githubTextMatch struct {
+	Fragment string `json:"fragment"`
+	Matches  []struct {
+		Text    string `json:"text"`
+		Indices [2]int `json:"indices"`
+	} `json:"matches"`
+}
+
+// githubSearchItem is one result item from the GitHub code search API.
+type githubSearchItem struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	SHA        string `json:"sha"`
+	HTMLURL    string `json:"html_url"`
+	Repository struct {
+		FullName string    `json:"full_name"`
+		PushedAt time.Time `json:"pushed_at"`
+	} `json:"repository"`
+	TextMatches []githubTextMatch `json:"text_matches"`
+}
+
+type githubSearchResponse struct {
+	TotalCount int                `json:"total_count"`
+	Items      []githubSearchItem `json:"items"`
+}
+
+// RateLimit reports the most recently observed GitHub API rate limit
+// state, so other subsystems (e.g. the /admin/jobs queue deciding how
+// aggressively to schedule syncs) can throttle their own use of this
+// client without having to make a request just to find out.
+func (c *githubClient) RateLimit() githubRateLimit {
+	c.rateLimitMu.Lock()
+	defer c.rateLimitMu.Unlock()
+	return c.rateLimit
+}
+
+// throttleForRateLimit blocks until GitHub's rate limit window resets,
+// if the last observed response reported none remaining, so the next
+// request doesn't immediately fail with a 403 that would otherwise
+// bubble up as a raw error to search callers.
+func (c *githubClient) throttleForRateLimit() {
+	c.rateLimitMu.Lock()
+	limit := c.rateLimit
+	c.rateLimitMu.Unlock()
+
+	if limit.Remaining > 0 || limit.Reset.IsZero() {
+		return
+	}
+	if wait := time.Until(limit.Reset); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// recordRateLimit updates c's rate limit state from resp's headers.
+func (c *githubClient) recordRateLimit(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	var reset time.Time
+	if sec, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(sec, 0)
+	}
+
+	c.rateLimitMu.Lock()
+	c.rateLimit = githubRateLimit{Remaining: remaining, Reset: reset}
+	c.rateLimitMu.Unlock()
+}
+
+// buildGithubQuery combines term with a repo: qualifier per repo, the
+// form GitHub's code search API expects to scope a query to one or more
+// repos.
+func buildGithubQuery(term string, repos []string) string {
+	q := term
+	for _, repo := range repos {
+		q += " repo:" + repo
+	}
+	return q
+}
+
+// chunkReposForQuery groups repos into the fewest chunks whose combined
+// query (via buildGithubQuery) each stay within githubMaxQueryLen, so a
+// config with many repos doesn't produce a single query GitHub rejects
+// as too long. A repo that doesn't fit even alongside term is placed in
+// its own chunk regardless; that request is sent anyway and GitHub's own
+// error, if any, is left to surface normally.
+func chunkReposForQuery(term string, repos []string) [][]string {
+	var chunks [][]string
+	var current []string
+	for _, repo := range repos {
+		candidate := append(append([]string{}, current...), repo)
+		if len(current) > 0 && len(buildGithubQuery(term, candidate)) > githubMaxQueryLen {
+			chunks = append(chunks, current)
+			current = []string{repo}
+			continue
+		}
+		current = candidate
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// searchCode runs a code search scoped to repo for the given query term
+// and returns the raw GitHub search items and GitHub's reported total
+// match count. page and perPage are forwarded to GitHub as-is when
+// positive; a zero value for either leaves GitHub's own default in
+// effect.
+func (c *githubClient) searchCode(term, repo string, page, perPage int) ([]githubSearchItem, int, error) {
+	return c.doSearch(buildGithubQuery(term, []string{repo}), page, perPage)
+}
+
+// searchCodeMulti is searchCode scoped to several repos at once via
+// repeated repo: qualifiers in a single query, so a caller with many
+// configured repos can batch them into fewer requests. Callers are
+// responsible for keeping each call's repos within githubMaxQueryLen,
+// e.g. via chunkReposForQuery.
+func (c *githubClient) searchCodeMulti(term string, repos []string, page, perPage int) ([]githubSearchItem, int, error) {
+	return c.doSearch(buildGithubQuery(term, repos), page, perPage)
+}
+
+// doSearch runs query against the GitHub code search API and returns the
+// raw search items and GitHub's reported total match count. It throttles
+// ahead of a request it already knows will be rate-limited, and honors a
+// Retry-After response by waiting and retrying once, rather than
+// bubbling either case up as a raw error.
+func (c *githubClient) doSearch(query string, page, perPage int) ([]githubSearchItem, int, error) {
+	c.throttleForRateLimit()
+
+	reqURL := c.baseURL + "?q=" + url.QueryEscape(query)
+	if page > 0 {
+		reqURL += "&page=" + strconv.Itoa(page)
+	}
+	if perPage > 0 {
+		reqURL += "&per_page=" + strconv.Itoa(perPage)
+	}
+
+	resp, err := c.doSearchRequest(reqURL)
+	if err != nil {
+		return nil, 0, err
+	}
+	c.recordRateLimit(resp)
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" && resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			time.Sleep(time.Duration(seconds) * time.Second)
+			resp, err = c.doSearchRequest(reqURL)
+			if err != nil {
+				return nil, 0, err
+			}
+			c.recordRateLimit(resp)
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("github search request: unexpected status %s", resp.Status)
+	}
+
+	var out githubSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, 0, fmt.Errorf("decode github search response: %w", err)
+	}
+
+	return out.Items, out.TotalCount, nil
+}
+
+// doSearchRequest issues a single GET to reqURL with the client's auth
+// header set.
+func (c *githubClient) doSearchRequest(reqURL string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build github search request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3.text-match+json")
+	req.Header.Set("User-Agent", userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github search request: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGithubClientSearchCodeRecordsRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "27")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		json.NewEncoder(w).Encode(githubSearchResponse{})
+	}))
+	defer srv.Close()
+
+	client := newGithubClientFor(srv.URL, "")
+	if _, _, err := client.searchCode("term", "bjatkin/golf-engine", 0, 0); err != nil {
+		t.Fatalf("searchCode: %v", err)
+	}
+
+	limit := client.RateLimit()
+	if limit.Remaining != 27 {
+		t.Errorf("RateLimit().Remaining = %d, want 27", limit.Remaining)
+	}
+	if limit.Reset.Before(time.Now()) {
+		t.Errorf("RateLimit().Reset = %v, want a time in the future", limit.Reset)
+	}
+}
+
+func TestGithubClientSearchCodeThrottlesWhenExhausted(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", "5")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		json.NewEncoder(w).Encode(githubSearchResponse{})
+	}))
+	defer srv.Close()
+
+	client := newGithubClientFor(srv.URL, "")
+	client.rateLimit = githubRateLimit{Remaining: 0, Reset: time.Now().Add(-time.Second)}
+
+	start := time.Now()
+	if _, _, err := client.searchCode("term", "bjatkin/golf-engine", 0, 0); err != nil {
+		t.Fatalf("searchCode: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("searchCode took %v, expected throttling against an already-past reset to return promptly", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestGithubClientSearchCodeMultiQueriesEveryRepo(t *testing.T) {
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("q")
+		json.NewEncoder(w).Encode(githubSearchResponse{TotalCount: 2, Items: []githubSearchItem{{Name: "a.go"}, {Name: "b.go"}}})
+	}))
+	defer srv.Close()
+
+	client := newGithubClientFor(srv.URL, "")
+	items, total, err := client.searchCodeMulti("term", []string{"bjatkin/a", "bjatkin/b"}, 0, 0)
+	if err != nil {
+		t.Fatalf("searchCodeMulti: %v", err)
+	}
+	if gotQuery != "term repo:bjatkin/a repo:bjatkin/b" {
+		t.Errorf("query = %q, want %q", gotQuery, "term repo:bjatkin/a repo:bjatkin/b")
+	}
+	if len(items) != 2 || total != 2 {
+		t.Errorf("items = %+v, total = %d, want 2 items and total 2", items, total)
+	}
+}
+
+func TestChunkReposForQueryStaysWithinQueryLimit(t *testing.T) {
+	var repos []string
+	for i := 0; i < 40; i++ {
+		repos = append(repos, "bjatkin/some-fairly-long-repo-name-"+strconv.Itoa(i))
+	}
+
+	chunks := chunkReposForQuery("term", repos)
+	if len(chunks) < 2 {
+		t.Fatalf("expected repos to split into more than one chunk, got %d", len(chunks))
+	}
+
+	var seen int
+	for _, chunk := range chunks {
+		if len(buildGithubQuery("term", chunk)) > githubMaxQueryLen {
+			t.Errorf("chunk %v produces a query longer than %d characters", chunk, githubMaxQueryLen)
+		}
+		seen += len(chunk)
+	}
+	if seen != len(repos) {
+		t.Errorf("chunked %d repos total, want %d", seen, len(repos))
+	}
+}
+
+func TestChunkReposForQuerySingleOversizedRepoGetsItsOwnChunk(t *testing.T) {
+	term := string(make([]byte, githubMaxQueryLen))
+	chunks := chunkReposForQuery(term, []string{"bjatkin/a", "bjatkin/b"})
+	if len(chunks) != 2 {
+		t.Fatalf("expected each repo in its own chunk, got %d chunks", len(chunks))
+	}
+}
+
+func TestGithubClientSearchCodeRetriesAfterRetryAfterHeader(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(githubSearchResponse{Items: []githubSearchItem{{Name: "found.go"}}})
+	}))
+	defer srv.Close()
+
+	client := newGithubClientFor(srv.URL, "")
+	items, _, err := client.searchCode("term", "bjatkin/golf-engine", 0, 0)
+	if err != nil {
+		t.Fatalf("searchCode: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("requests = %d, want 2 (initial + retry)", requests)
+	}
+	if len(items) != 1 || items[0].Name != "found.go" {
+		t.Errorf("items = %+v, want a single found.go item", items)
+	}
+}
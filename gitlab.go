@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultGitlabBaseURL is GitLab.com's API v4 root. A self-hosted
+// instance overrides this via RepoBackend.BaseURL.
+const defaultGitlabBaseURL = "https://gitlab.com/api/v4"
+
+// gitlabBlobSearchResult is one hit from GitLab's blob search API:
+// https://docs.gitlab.com/ee/api/search.html
+type gitlabBlobSearchResult struct {
+	Basename string `json:"basename"`
+	Data     string `json:"data"`
+	Path     string `json:"path"`
+	Filename string `json:"filename"`
+	Ref      string `json:"ref"`
+}
+
+// gitlabClient queries a GitLab instance's project-scoped blob search
+// API and adapts results into the shared githubSearchItem shape.
+type gitlabClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+func newGitlabClientFor(baseURL, token string) *gitlabClient {
+	if baseURL == "" {
+		baseURL = defaultGitlabBaseURL
+	}
+	return &gitlabClient{httpClient: http.DefaultClient, baseURL: baseURL, token: token}
+}
+
+// searchCode runs a blob search scoped to repo (a "namespace/project"
+// path, which GitHub accepts URL-encoded in place of a numeric project
+// ID) and adapts the results into githubSearchItems. page and perPage
+// are forwarded as GitLab's own pagination query parameters when
+// positive. The total match count comes from GitLab's X-Total response
+// header; if the backend omits it, the returned page's item count is
+// used instead.
+func (c *gitlabClient) searchCode(term, repo string, page, perPage int) ([]githubSearchItem, int, error) {
+	reqURL := fmt.Sprintf("%s/projects/%s/search?scope=blobs&search=%s",
+		c.baseURL, url.PathEscape(repo), url.QueryEscape(term))
+	if page > 0 {
+		reqURL += "&page=" + strconv.Itoa(page)
+	}
+	if perPage > 0 {
+		reqURL += "&per_page=" + strconv.Itoa(perPage)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("build gitlab search request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gitlab search request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("gitlab search request: unexpected status %s", resp.Status)
+	}
+
+	var blobs []gitlabBlobSearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&blobs); err != nil {
+		return nil, 0, fmt.Errorf("decode gitlab search response: %w", err)
+	}
+
+	items := make([]githubSearchItem, len(blobs))
+	for i, b := range blobs {
+		items[i] = githubSearchItem{
+			Name:    b.Filename,
+			Path:    b.Path,
+			HTMLURL: c.blobURL(repo, b),
+			TextMatches: []githubTextMatch{
+				{Fragment: b.Data},
+			},
+		}
+		items[i].Repository.FullName = repo
+	}
+
+	total := len(items)
+	if raw := resp.Header.Get("X-Total"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil {
+			total = n
+		}
+	}
+	return items, total, nil
+}
+
+// blobURL builds the web (not API) URL a human would click through to
+// for a blob search hit, so GitLab results carry a usable link just like
+// GitHub ones. GitLab's blob search API doesn't always return a ref for
+// a hit; HEAD is used when it doesn't, which resolves to the project's
+// default branch.
+func (c *gitlabClient) blobURL(repo string, b gitlabBlobSearchResult) string {
+	ref := b.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	webBaseURL := strings.TrimSuffix(c.baseURL, "/api/v4")
+	return fmt.Sprintf("%s/%s/-/blob/%s/%s", webBaseURL, repo, ref, b.Path)
+}
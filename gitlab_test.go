@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitlabClientSearchCode(t *testing.T) {
+	var gotPath, gotToken string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotToken = r.Header.Get("PRIVATE-TOKEN")
+		json.NewEncoder(w).Encode([]gitlabBlobSearchResult{
+			{Filename: "auth.go", Path: "internal/auth.go", Data: "func Login() {}"},
+		})
+	}))
+	defer srv.Close()
+
+	client := newGitlabClientFor(srv.URL, "glpat-secret")
+	items, _, err := client.searchCode("Login", "group/project", 0, 0)
+	if err != nil {
+		t.Fatalf("searchCode: %v", err)
+	}
+
+	if gotPath != "/projects/group%2Fproject/search" {
+		t.Errorf("gotPath = %q, want /projects/group%%2Fproject/search", gotPath)
+	}
+	if gotToken != "glpat-secret" {
+		t.Errorf("gotToken = %q, want glpat-secret", gotToken)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	item := items[0]
+	if item.Name != "auth.go" || item.Path != "internal/auth.go" {
+		t.Errorf("item = %+v, want Name=auth.go Path=internal/auth.go", item)
+	}
+	if item.Repository.FullName != "group/project" {
+		t.Errorf("item.Repository.FullName = %q, want group/project", item.Repository.FullName)
+	}
+	if len(item.TextMatches) != 1 || item.TextMatches[0].Fragment != "func Login() {}" {
+		t.Errorf("item.TextMatches = %+v, want fragment %q", item.TextMatches, "func Login() {}")
+	}
+	if want := srv.URL + "/group/project/-/blob/HEAD/internal/auth.go"; item.HTMLURL != want {
+		t.Errorf("item.HTMLURL = %q, want %q", item.HTMLURL, want)
+	}
+}
+
+func TestGitlabClientBlobURLUsesRefWhenPresent(t *testing.T) {
+	client := newGitlabClientFor("https://gitlab.example.com/api/v4", "")
+	url := client.blobURL("group/project", gitlabBlobSearchResult{Path: "main.go", Ref: "release-1.2"})
+
+	if want := "https://gitlab.example.com/group/project/-/blob/release-1.2/main.go"; url != want {
+		t.Errorf("blobURL = %q, want %q", url, want)
+	}
+}
+
+func TestNewGitlabClientForDefaultsBaseURL(t *testing.T) {
+	client := newGitlabClientFor("", "")
+	if client.baseURL != defaultGitlabBaseURL {
+		t.Errorf("baseURL = %q, want %q", client.baseURL, defaultGitlabBaseURL)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+// defaultGroupByRepoCap bounds how many results are kept per repo when
+// group_by=repo is requested, unless the client overrides it.
+const defaultGroupByRepoCap = 20
+
+// RepoGroup is one repository's results when group_by=repo is used.
+type RepoGroup struct {
+	Repo    string   `json:"repo"`
+	Count   int      `json:"count"`
+	Results []Result `json:"results"`
+}
+
+// groupByRepo buckets results by repo, preserving each repo's original
+// result order and capping how many results are kept per repo. Count
+// reflects the total number of matches for the repo, even when Results
+// is capped.
+func groupByRepo(results []Result, perRepoCap int) []RepoGroup {
+	order := []string{}
+	groups := map[string]*RepoGroup{}
+
+	for _, r := range results {
+		g, ok := groups[r.Repo]
+		if !ok {
+			g = &RepoGroup{Repo: r.Repo}
+			groups[r.Repo] = g
+			order = append(order, r.Repo)
+		}
+		g.Count++
+		if perRepoCap <= 0 || len(g.Results) < perRepoCap {
+			g.Results = append(g.Results, r)
+		}
+	}
+
+	out := make([]RepoGroup, 0, len(order))
+	for _, repo := range order {
+		out = append(out, *groups[repo])
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestGroupByRepo(t *testing.T) {
+	results := []Result{
+		{Repo: "a/x", Path: "1.go"},
+		{Repo: "a/x", Path: "2.go"},
+		{Repo: "b/y", Path: "3.go"},
+	}
+
+	groups := groupByRepo(results, 1)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	if groups[0].Repo != "a/x" || groups[0].Count != 2 || len(groups[0].Results) != 1 {
+		t.Errorf("unexpected group: %+v", groups[0])
+	}
+}
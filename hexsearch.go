@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HexMatch is a single byte-pattern match found by the local backend's
+// hex search mode, useful for firmware/asset repos where text search
+// doesn't apply.
+type HexMatch struct {
+	Repo   string `json:"repo"`
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+}
+
+// searchHex clones repo (if needed) and scans every file in it for the
+// byte sequence encoded by hexPattern (e.g. "DEADBEEF"), returning every
+// match with its byte offset into the file.
+func (idx *localIndex) searchHex(repo, hexPattern string) ([]HexMatch, error) {
+	dir, err := idx.ensureClone(repo)
+	if err != nil {
+		return nil, err
+	}
+	return idx.scanDirHex(repo, dir, hexPattern)
+}
+
+// searchHexAtRef checks out repo as of ref (a SHA or tag), caching the
+// checkout on disk, and scans it for the byte sequence encoded by
+// hexPattern exactly like searchHex scans the default branch. This is
+// what answers "did this firmware blob exist in v1.2.0?" without
+// disturbing the repo's regular clone.
+func (idx *localIndex) searchHexAtRef(repo, ref, hexPattern string) ([]HexMatch, error) {
+	dir, err := idx.ensureRefWorktree(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	return idx.scanDirHex(repo, dir, hexPattern)
+}
+
+// scanDirHex scans dir (a working tree of repo, either its main clone
+// or a historical ref's checkout) for the byte sequence encoded by
+// hexPattern.
+func (idx *localIndex) scanDirHex(repo, dir, hexPattern string) ([]HexMatch, error) {
+	pattern, err := hex.DecodeString(strings.TrimSpace(hexPattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex pattern: %w", err)
+	}
+	if len(pattern) == 0 {
+		return nil, fmt.Errorf("hex pattern must not be empty")
+	}
+
+	var matches []HexMatch
+	seen := newCaseFolder()
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil // don't follow symlinks; a malicious repo could point one outside the clone
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		if seen.collides(filepath.ToSlash(rel)) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil // unreadable file (permissions, broken symlink); skip it
+		}
+
+		for offset := 0; ; {
+			pos := bytes.Index(data[offset:], pattern)
+			if pos == -1 {
+				break
+			}
+			matches = append(matches, HexMatch{Repo: repo, Path: filepath.ToSlash(rel), Offset: int64(offset + pos)})
+			offset += pos + 1
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s for hex pattern: %w", repo, err)
+	}
+
+	return matches, nil
+}
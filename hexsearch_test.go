@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchHex(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+	repoDir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "firmware.bin"), []byte{0x00, 0xDE, 0xAD, 0xBE, 0xEF, 0x00}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	matches, err := idx.searchHex(repo, "DEADBEEF")
+	if err != nil {
+		t.Fatalf("searchHex: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+	if matches[0].Path != "firmware.bin" || matches[0].Offset != 1 {
+		t.Errorf("unexpected match: %+v", matches[0])
+	}
+}
+
+func TestSearchHexAtRefUsesRefWorktree(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+
+	repoDir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "firmware.bin"), []byte{0xDE, 0xAD, 0xBE, 0xEF}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	refDir := idx.refPath(repo, "v1.2.0")
+	if err := os.MkdirAll(filepath.Join(refDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(refDir, "firmware.bin"), []byte{0xCA, 0xFE, 0xBA, 0xBE}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := idx.searchHexAtRef(repo, "v1.2.0", "CAFEBABE")
+	if err != nil {
+		t.Fatalf("searchHexAtRef: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match from the ref worktree, got %d", len(matches))
+	}
+
+	matches, err = idx.searchHexAtRef(repo, "v1.2.0", "DEADBEEF")
+	if err != nil {
+		t.Fatalf("searchHexAtRef: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Fatalf("expected 0 matches, since the ref worktree should not see the main clone's content, got %d", len(matches))
+	}
+}
+
+func TestSearchHexInvalidPattern(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	if _, err := idx.searchHex("owner/repo", "not-hex"); err == nil {
+		t.Fatal("expected error for invalid hex pattern")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Offset is a machine-readable range within a snippet line that a
+// search term matched. Start and End are byte offsets into Line.Text;
+// RuneStart and RuneEnd are the same range in runes, since clients
+// working with non-ASCII text (accented identifiers, CJK comments)
+// generally want to index by rune/codepoint, not byte. Term records the
+// matched text, so a client running a multi-term AND query can tell
+// which of its terms produced the match.
+type Offset struct {
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	RuneStart int    `json:"rune_start"`
+	RuneEnd   int    `json:"rune_end"`
+	Term      string `json:"term"`
+}
+
+// Line is a single line of a match snippet along with the offsets of
+// any matched terms within it, so clients can highlight matches without
+// re-running the search themselves.
+type Line struct {
+	Number  int      `json:"number"`
+	Text    string   `json:"text"`
+	Offsets []Offset `json:"offsets,omitempty"`
+}
+
+// splitFragment breaks a GitHub text_matches fragment into Lines and
+// remaps the fragment-relative match indices onto per-line byte offsets.
+func splitFragment(fragment string, matches []githubTextMatch) []Line {
+	var lines []Line
+
+	lineStart := 0
+	for i, raw := range strings.Split(fragment, "\n") {
+		lineEnd := lineStart + len(raw)
+
+		line := Line{Number: i + 1, Text: raw}
+		for _, tm := range matches {
+			for _, m := range tm.Matches {
+				start, end := m.Indices[0], m.Indices[1]
+				if start >= lineStart && end <= lineEnd {
+					relStart, relEnd := start-lineStart, end-lineStart
+					line.Offsets = append(line.Offsets, Offset{
+						Start:     relStart,
+						End:       relEnd,
+						RuneStart: utf8.RuneCountInString(raw[:relStart]),
+						RuneEnd:   utf8.RuneCountInString(raw[:relEnd]),
+						Term:      m.Text,
+					})
+				}
+			}
+		}
+
+		lines = append(lines, line)
+		lineStart = lineEnd + 1 // account for the '\n' stripped by Split
+	}
+
+	return lines
+}
+
+// mergeLines combines lines with identical text into a single line
+// carrying the union of their offsets. GitHub returns one fragment per
+// matched term for boolean AND queries, so the same line can otherwise
+// show up once per term; merging avoids returning duplicate, overlapping
+// fragments and lets a single line report every term it matched.
+func mergeLines(lines []Line) []Line {
+	var merged []Line
+	seen := map[string]int{} // line text -> index into merged
+
+	for _, line := range lines {
+		idx, ok := seen[line.Text]
+		if !ok {
+			seen[line.Text] = len(merged)
+			merged = append(merged, line)
+			continue
+		}
+
+		for _, off := range line.Offsets {
+			if !containsOffset(merged[idx].Offsets, off) {
+				merged[idx].Offsets = append(merged[idx].Offsets, off)
+			}
+		}
+	}
+
+	return merged
+}
+
+func containsOffset(offsets []Offset, off Offset) bool {
+	for _, o := range offsets {
+		if o == off {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestSplitFragment(t *testing.T) {
+	fragment := "func foo() {\n\treturn bar\n}"
+	matches := []githubTextMatch{
+		{
+			Fragment: fragment,
+			Matches: []struct {
+				Text    string `json:"text"`
+				Indices [2]int `json:"indices"`
+			}{
+				{Text: "foo", Indices: [2]int{5, 8}},
+				{Text: "bar", Indices: [2]int{21, 24}},
+			},
+		},
+	}
+
+	lines := splitFragment(fragment, matches)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+
+	want0 := Offset{Start: 5, End: 8, RuneStart: 5, RuneEnd: 8, Term: "foo"}
+	if len(lines[0].Offsets) != 1 || lines[0].Offsets[0] != want0 {
+		t.Errorf("line 1 offsets = %v, want [%v]", lines[0].Offsets, want0)
+	}
+
+	want1 := Offset{Start: 8, End: 11, RuneStart: 8, RuneEnd: 11, Term: "bar"}
+	if len(lines[1].Offsets) != 1 || lines[1].Offsets[0] != want1 {
+		t.Errorf("line 2 offsets = %v, want [%v]", lines[1].Offsets, want1)
+	}
+}
+
+func TestSplitFragmentMultibyte(t *testing.T) {
+	fragment := "// café note"
+	matches := []githubTextMatch{
+		{
+			Fragment: fragment,
+			Matches: []struct {
+				Text    string `json:"text"`
+				Indices [2]int `json:"indices"`
+			}{
+				{Text: "café", Indices: [2]int{3, 8}}, // "é" is 2 bytes in UTF-8
+			},
+		},
+	}
+
+	lines := splitFragment(fragment, matches)
+	off := lines[0].Offsets[0]
+	if off.End-off.Start != 5 {
+		t.Errorf("byte range = %d, want 5", off.End-off.Start)
+	}
+	if off.RuneEnd-off.RuneStart != 4 {
+		t.Errorf("rune range = %d, want 4", off.RuneEnd-off.RuneStart)
+	}
+}
+
+func TestMergeLines(t *testing.T) {
+	lines := []Line{
+		{Number: 1, Text: "func foo() bar {", Offsets: []Offset{{Start: 5, End: 8, Term: "foo"}}},
+		{Number: 1, Text: "func foo() bar {", Offsets: []Offset{{Start: 12, End: 15, Term: "bar"}}},
+	}
+
+	merged := mergeLines(lines)
+	if len(merged) != 1 {
+		t.Fatalf("expected 1 merged line, got %d", len(merged))
+	}
+	if len(merged[0].Offsets) != 2 {
+		t.Fatalf("expected 2 offsets on merged line, got %d", len(merged[0].Offsets))
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// defaultConfigTemplate is the starter config.yaml text "gitsearch
+// init" scaffolds, with placeholders it fills in from prompted answers.
+// This repo ships no UI assets or rule packs to embed alongside it -
+// the whole web UI is served by handlers already compiled into the
+// binary, and there's no bundled rule pack format for git_search's
+// policy checker - so the config template is what makes the binary a
+// self-contained "download and run" deploy.
+//
+//go:embed templates/default_config.yaml
+var defaultConfigTemplateFS embed.FS
+
+// runInit is the "git_search init [config_file]" subcommand: it
+// interactively scaffolds a starter config.yaml (defaulting to that
+// name in the current directory) from defaultConfigTemplate, prompting
+// for the handful of fields every deploy needs and leaving the rest at
+// their template defaults for editing by hand afterward.
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	fs.Parse(args)
+
+	out := "config.yaml"
+	if fs.NArg() > 0 {
+		out = fs.Arg(0)
+	}
+	if _, err := os.Stat(out); err == nil {
+		fmt.Fprintf(os.Stderr, "%s already exists, not overwriting\n", out)
+		os.Exit(1)
+	}
+
+	template, err := defaultConfigTemplateFS.ReadFile("templates/default_config.yaml")
+	if err != nil {
+		log.Fatalf("read embedded config template: %v", err)
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	port := prompt(in, "Port to listen on", "8080")
+	repo := prompt(in, "First repo to index (owner/name)", "owner/name")
+
+	cfg := string(template)
+	cfg = strings.ReplaceAll(cfg, "{{PORT}}", port)
+	cfg = strings.ReplaceAll(cfg, "{{REPO}}", repo)
+
+	if err := os.WriteFile(out, []byte(cfg), 0o644); err != nil {
+		log.Fatalf("write %s: %v", out, err)
+	}
+	fmt.Printf("wrote starter config to %s\n", out)
+}
+
+// prompt asks question on stdout, reads a line from in, and returns
+// def if the answer is empty (including when in is not interactive,
+// e.g. redirected from /dev/null).
+func prompt(in *bufio.Reader, question, def string) string {
+	fmt.Printf("%s [%s]: ", question, def)
+	line, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return def
+	}
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		return def
+	}
+	return answer
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestPromptReturnsAnswerWhenGiven(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("9090\n"))
+	if got := prompt(in, "Port to listen on", "8080"); got != "9090" {
+		t.Errorf("prompt = %q, want 9090", got)
+	}
+}
+
+func TestPromptFallsBackToDefaultOnEmptyOrEOF(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader(""))
+	if got := prompt(in, "Port to listen on", "8080"); got != "8080" {
+		t.Errorf("prompt = %q, want 8080", got)
+	}
+}
+
+func TestDefaultConfigTemplateFillsPlaceholders(t *testing.T) {
+	template, err := defaultConfigTemplateFS.ReadFile("templates/default_config.yaml")
+	if err != nil {
+		t.Fatalf("read embedded config template: %v", err)
+	}
+	cfg := strings.ReplaceAll(string(template), "{{PORT}}", "9090")
+	cfg = strings.ReplaceAll(cfg, "{{REPO}}", "owner/repo")
+	if strings.Contains(cfg, "{{") {
+		t.Errorf("unfilled placeholder remains: %s", cfg)
+	}
+
+	var parsed Config
+	if err := yaml.Unmarshal([]byte(cfg), &parsed); err != nil {
+		t.Fatalf("parse scaffolded config: %v", err)
+	}
+	if parsed.Port != 9090 {
+		t.Errorf("Port = %d, want 9090", parsed.Port)
+	}
+	if len(parsed.Repos) != 1 || parsed.Repos[0] != "owner/repo" {
+		t.Errorf("Repos = %v, want [owner/repo]", parsed.Repos)
+	}
+}
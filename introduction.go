@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// introductionCacheTTL is how long a computed Introduction is kept, long
+// enough to cover repeated look-ups against the same result set without
+// re-running git log for every request; introductions rarely change once
+// history is written, so a generous TTL is safe.
+const introductionCacheTTL = 24 * time.Hour
+
+// Introduction is the commit and date a matching line was first
+// introduced at, found via a pickaxe search (git log -S) over path.
+type Introduction struct {
+	Commit string    `json:"commit"`
+	Date   time.Time `json:"date"`
+}
+
+// introductionCache holds computed Introductions in memory, keyed by
+// repo, path, and search term, since a pickaxe search walks the entire
+// history of a file and is too slow to run on every request.
+type introductionCache struct {
+	mu      sync.Mutex
+	entries map[string]introductionCacheEntry
+}
+
+type introductionCacheEntry struct {
+	intro     *Introduction
+	expiresAt time.Time
+}
+
+func newIntroductionCache() *introductionCache {
+	return &introductionCache{entries: map[string]introductionCacheEntry{}}
+}
+
+func introductionCacheKey(repo, path, term string) string {
+	return repo + "\x00" + path + "\x00" + term
+}
+
+func (c *introductionCache) get(repo, path, term string) (*Introduction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := introductionCacheKey(repo, path, term)
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.intro, true
+}
+
+func (c *introductionCache) put(repo, path, term string, intro *Introduction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[introductionCacheKey(repo, path, term)] = introductionCacheEntry{
+		intro:     intro,
+		expiresAt: time.Now().Add(introductionCacheTTL),
+	}
+}
+
+// firstIntroduced runs a pickaxe search (git log -S<term>, oldest first)
+// over path's history and returns the commit and date term first
+// appeared, or nil if it never did (e.g. it only ever existed in a
+// still-uncommitted working tree state).
+func (idx *localIndex) firstIntroduced(repo, path, term string) (*Introduction, error) {
+	if intro, ok := idx.introductions.get(repo, path, term); ok {
+		return intro, nil
+	}
+
+	dir, err := idx.ensureClone(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := idx.sandbox.run("git", "-C", dir, "log", "--reverse", "--format=%H|%cI", "-S"+term, "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("pickaxe %s %s: %w: %s", repo, path, err, out)
+	}
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if firstLine == "" {
+		idx.introductions.put(repo, path, term, nil)
+		return nil, nil
+	}
+
+	commit, dateStr, ok := strings.Cut(firstLine, "|")
+	if !ok {
+		return nil, fmt.Errorf("pickaxe %s %s: unexpected git log output: %q", repo, path, firstLine)
+	}
+
+	date, err := time.Parse(time.RFC3339, dateStr)
+	if err != nil {
+		return nil, fmt.Errorf("pickaxe %s %s: parse commit date: %w", repo, path, err)
+	}
+
+	intro := &Introduction{Commit: commit, Date: date}
+	idx.introductions.put(repo, path, term, intro)
+	return intro, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initTestRepo creates a small real git repository at dir with two
+// commits, so pickaxe searches have real history to walk.
+func initTestRepo(t *testing.T, dir string) {
+	t.Helper()
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	run("init", "-q")
+	run("config", "user.name", "test")
+	run("config", "user.email", "test@example.com")
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "main.go")
+	run("commit", "-q", "-m", "initial commit")
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nconst apiKey = \"sk-live-deadbeef\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "main.go")
+	run("commit", "-q", "-m", "add api key")
+}
+
+func TestFirstIntroducedFindsCommitAndDate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+	dir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	initTestRepo(t, dir)
+
+	idx := newLocalIndex(dataDir)
+	intro, err := idx.firstIntroduced(repo, "main.go", "sk-live-deadbeef")
+	if err != nil {
+		t.Fatalf("firstIntroduced: %v", err)
+	}
+	if intro == nil {
+		t.Fatal("expected a non-nil Introduction")
+	}
+	if intro.Commit == "" {
+		t.Error("expected a commit SHA")
+	}
+	if intro.Date.IsZero() {
+		t.Error("expected a non-zero commit date")
+	}
+
+	// cached: a second call must return the same value without re-running git.
+	again, err := idx.firstIntroduced(repo, "main.go", "sk-live-deadbeef")
+	if err != nil {
+		t.Fatalf("firstIntroduced (cached): %v", err)
+	}
+	if again.Commit != intro.Commit {
+		t.Errorf("expected cached result to match, got %q want %q", again.Commit, intro.Commit)
+	}
+}
+
+func TestFirstIntroducedReturnsNilForUnmatchedTerm(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+	dir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	initTestRepo(t, dir)
+
+	idx := newLocalIndex(dataDir)
+	intro, err := idx.firstIntroduced(repo, "main.go", "never-existed-in-history")
+	if err != nil {
+		t.Fatalf("firstIntroduced: %v", err)
+	}
+	if intro != nil {
+		t.Errorf("expected nil Introduction, got %+v", intro)
+	}
+}
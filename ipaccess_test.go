@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIPAccessRuleAllowsByDefault(t *testing.T) {
+	rule, err := compileIPAccessRule(IPAccessRule{})
+	if err != nil {
+		t.Fatalf("compileIPAccessRule: %v", err)
+	}
+	if !rule.Allowed(net.ParseIP("1.2.3.4"), "") {
+		t.Error("expected an empty rule to allow any address")
+	}
+}
+
+func TestIPAccessRuleDenyWinsOverAllow(t *testing.T) {
+	rule, err := compileIPAccessRule(IPAccessRule{
+		Allow: []string{"10.0.0.0/8"},
+		Deny:  []string{"10.0.0.5"},
+	})
+	if err != nil {
+		t.Fatalf("compileIPAccessRule: %v", err)
+	}
+	if rule.Allowed(net.ParseIP("10.0.0.5"), "") {
+		t.Error("expected the denied address to be blocked despite matching allow")
+	}
+	if !rule.Allowed(net.ParseIP("10.0.0.6"), "") {
+		t.Error("expected a different allowed address to pass")
+	}
+}
+
+func TestIPAccessRuleAllowListRejectsUnlisted(t *testing.T) {
+	rule, err := compileIPAccessRule(IPAccessRule{Allow: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatalf("compileIPAccessRule: %v", err)
+	}
+	if rule.Allowed(net.ParseIP("8.8.8.8"), "") {
+		t.Error("expected an address outside the allow list to be blocked")
+	}
+}
+
+func TestIPAccessRuleCountryRules(t *testing.T) {
+	rule, err := compileIPAccessRule(IPAccessRule{DenyCountries: []string{"kp"}})
+	if err != nil {
+		t.Fatalf("compileIPAccessRule: %v", err)
+	}
+	if rule.Allowed(net.ParseIP("1.2.3.4"), "KP") {
+		t.Error("expected a denied country to be blocked regardless of case")
+	}
+	if !rule.Allowed(net.ParseIP("1.2.3.4"), "US") {
+		t.Error("expected a non-denied country to pass")
+	}
+}
+
+func TestCompileIPAccessRuleRejectsInvalidCIDR(t *testing.T) {
+	if _, err := compileIPAccessRule(IPAccessRule{Allow: []string{"not-an-ip"}}); err == nil {
+		t.Error("expected an error for a malformed CIDR")
+	}
+}
+
+func TestIPAccessMiddlewareBlocksDeniedAdminPath(t *testing.T) {
+	policy := &ipAccessPolicy{}
+	var err error
+	policy.admin, err = compileIPAccessRule(IPAccessRule{Deny: []string{"1.2.3.4"}})
+	if err != nil {
+		t.Fatalf("compileIPAccessRule: %v", err)
+	}
+
+	handler := ipAccessMiddleware(func() *ipAccessPolicy { return policy }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/usage", nil)
+	req.RemoteAddr = "1.2.3.4:5555"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestIPAccessMiddlewareOnlyAppliesAdminRuleToAdminPaths(t *testing.T) {
+	policy := &ipAccessPolicy{}
+	var err error
+	policy.admin, err = compileIPAccessRule(IPAccessRule{Deny: []string{"1.2.3.4"}})
+	if err != nil {
+		t.Fatalf("compileIPAccessRule: %v", err)
+	}
+
+	handler := ipAccessMiddleware(func() *ipAccessPolicy { return policy }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	req.RemoteAddr = "1.2.3.4:5555"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d: admin deny rule shouldn't apply to /search", w.Code, http.StatusOK)
+	}
+}
+
+func TestRequestIPUsesClientIPHeaderFromTrustedProxy(t *testing.T) {
+	_, trusted, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %v", err)
+	}
+	policy := &ipAccessPolicy{trustedProxies: []*net.IPNet{trusted}, clientIPHeader: "X-Forwarded-For"}
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	req.RemoteAddr = "10.0.0.5:5555"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.5")
+
+	ip := requestIP(req, policy)
+	if ip == nil || ip.String() != "203.0.113.9" {
+		t.Errorf("requestIP() = %v, want 203.0.113.9 from the trusted proxy's header", ip)
+	}
+}
+
+func TestRequestIPIgnoresHeaderFromUntrustedRemote(t *testing.T) {
+	_, trusted, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %v", err)
+	}
+	policy := &ipAccessPolicy{trustedProxies: []*net.IPNet{trusted}, clientIPHeader: "X-Forwarded-For"}
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	req.RemoteAddr = "8.8.8.8:5555"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+
+	ip := requestIP(req, policy)
+	if ip == nil || ip.String() != "8.8.8.8" {
+		t.Errorf("requestIP() = %v, want the untrusted RemoteAddr 8.8.8.8, not the spoofed header", ip)
+	}
+}
+
+func TestCompileIPAccessPolicyDefaultsClientIPHeader(t *testing.T) {
+	policy, err := compileIPAccessPolicy(Config{IPAccess: IPAccessConfig{TrustedProxies: []string{"10.0.0.0/8"}}})
+	if err != nil {
+		t.Fatalf("compileIPAccessPolicy: %v", err)
+	}
+	if policy.clientIPHeader != defaultClientIPHeader {
+		t.Errorf("clientIPHeader = %q, want default %q", policy.clientIPHeader, defaultClientIPHeader)
+	}
+}
+
+func TestIPAccessMiddlewareNilPolicyAllowsEverything(t *testing.T) {
+	handler := ipAccessMiddleware(func() *ipAccessPolicy { return nil }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/usage", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JobPriority orders pending index jobs: lower values run first.
+type JobPriority int
+
+const (
+	JobPriorityWebhook   JobPriority = iota // triggered by a webhook; run first
+	JobPriorityScheduled                    // a periodic sync
+	JobPriorityRebuild                      // a full rebuild; run when nothing more urgent is pending
+)
+
+// jobPriorityLevels lists every priority from highest to lowest, the
+// order jobQueue drains them in.
+var jobPriorityLevels = []JobPriority{JobPriorityWebhook, JobPriorityScheduled, JobPriorityRebuild}
+
+// JobStatus is the lifecycle state of a Job.
+type JobStatus string
+
+const (
+	JobStatusQueued  JobStatus = "queued"
+	JobStatusRunning JobStatus = "running"
+	JobStatusDone    JobStatus = "done"
+	JobStatusFailed  JobStatus = "failed"
+)
+
+// defaultJobMaxAttempts and defaultJobBackoff govern retries: a failed
+// job is retried with exponentially increasing delay up to
+// defaultJobMaxAttempts times before it's left failed.
+const (
+	defaultJobMaxAttempts = 3
+	defaultJobBackoff     = 2 * time.Second
+	defaultJobConcurrency = 2
+)
+
+// Job is one index/sync task tracked by jobQueue.
+type Job struct {
+	ID          string      `json:"id"`
+	Repo        string      `json:"repo"`
+	Priority    JobPriority `json:"priority"`
+	Status      JobStatus   `json:"status"`
+	Attempts    int         `json:"attempts"`
+	MaxAttempts int         `json:"max_attempts"`
+	LastError   string      `json:"last_error,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	FinishedAt  time.Time   `json:"finished_at,omitempty"`
+
+	// DeliveryID, when set, is the webhook delivery this job was
+	// enqueued to replay, so its outcome can be recorded back onto that
+	// delivery.
+	DeliveryID string `json:"delivery_id,omitempty"`
+}
+
+// jobQueue runs index/sync jobs with a bounded number of concurrent
+// workers, draining higher-priority jobs before lower-priority ones, and
+// retrying failed jobs with backoff before giving up.
+type jobQueue struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+
+	queues map[JobPriority]chan *Job
+	sem    chan struct{} // bounds concurrent workers
+
+	run func(job *Job) error
+}
+
+// newJobQueue starts a job queue with the given concurrency limit,
+// executing each job with run. Workers keep draining queues until the
+// queue channels are closed.
+func newJobQueue(concurrency int, run func(job *Job) error) *jobQueue {
+	q := &jobQueue{
+		jobs:   map[string]*Job{},
+		queues: map[JobPriority]chan *Job{},
+		sem:    make(chan struct{}, concurrency),
+		run:    run,
+	}
+	for _, p := range jobPriorityLevels {
+		q.queues[p] = make(chan *Job, 1024)
+	}
+	for i := 0; i < concurrency; i++ {
+		go q.worker()
+	}
+	return q
+}
+
+// Enqueue adds a job to sync repo at the given priority and returns it.
+func (q *jobQueue) Enqueue(repo string, priority JobPriority) (*Job, error) {
+	return q.EnqueueForDelivery(repo, priority, "")
+}
+
+// EnqueueForDelivery is Enqueue, additionally tagging the job with the
+// webhook delivery it's replaying so the outcome can be recorded back
+// onto that delivery.
+func (q *jobQueue) EnqueueForDelivery(repo string, priority JobPriority, deliveryID string) (*Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		ID:          id,
+		Repo:        repo,
+		Priority:    priority,
+		Status:      JobStatusQueued,
+		MaxAttempts: defaultJobMaxAttempts,
+		CreatedAt:   time.Now(),
+		DeliveryID:  deliveryID,
+	}
+
+	q.mu.Lock()
+	q.jobs[job.ID] = job
+	q.mu.Unlock()
+
+	q.queues[priority] <- job
+	return job, nil
+}
+
+// List returns every job the queue knows about, most recently created
+// first.
+func (q *jobQueue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	out := make([]Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		out = append(out, *job)
+	}
+	return out
+}
+
+// worker pulls one job at a time from the highest-priority non-empty
+// queue and runs it, up to the queue's concurrency limit.
+func (q *jobQueue) worker() {
+	for {
+		job := q.next()
+
+		q.mu.Lock()
+		job.Status = JobStatusRunning
+		job.Attempts++
+		q.mu.Unlock()
+
+		err := q.run(job)
+
+		q.mu.Lock()
+		if err == nil {
+			job.Status = JobStatusDone
+			job.FinishedAt = time.Now()
+			q.mu.Unlock()
+			continue
+		}
+
+		job.LastError = err.Error()
+		if job.Attempts >= job.MaxAttempts {
+			job.Status = JobStatusFailed
+			job.FinishedAt = time.Now()
+			q.mu.Unlock()
+			continue
+		}
+		job.Status = JobStatusQueued
+		q.mu.Unlock()
+
+		backoff := defaultJobBackoff * time.Duration(1<<uint(job.Attempts-1))
+		time.AfterFunc(backoff, func() {
+			q.queues[job.Priority] <- job
+		})
+	}
+}
+
+// next blocks until a job is available, preferring higher-priority
+// queues over lower ones.
+func (q *jobQueue) next() *Job {
+	for {
+		for _, p := range jobPriorityLevels {
+			select {
+			case job := <-q.queues[p]:
+				return job
+			default:
+			}
+		}
+		// Nothing ready right now; block on the highest-priority queue
+		// that has anything at all, checked round-robin so we don't
+		// starve lower-priority jobs when nothing higher is pending.
+		select {
+		case job := <-q.queues[JobPriorityWebhook]:
+			return job
+		case job := <-q.queues[JobPriorityScheduled]:
+			return job
+		case job := <-q.queues[JobPriorityRebuild]:
+			return job
+		}
+	}
+}
+
+// newJobID generates a random hex job identifier.
+func newJobID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate job id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
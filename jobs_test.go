@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJobQueueRunsEnqueuedJob(t *testing.T) {
+	var ran atomic.Int32
+	q := newJobQueue(1, func(job *Job) error {
+		ran.Add(1)
+		return nil
+	})
+
+	job, err := q.Enqueue("owner/repo", JobPriorityScheduled)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if ran.Load() == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if ran.Load() != 1 {
+		t.Fatalf("expected job to run once, ran %d times", ran.Load())
+	}
+
+	jobs := q.List()
+	if len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Fatalf("expected List to report the enqueued job")
+	}
+}
+
+func TestJobQueueRetriesOnFailure(t *testing.T) {
+	var attempts atomic.Int32
+	q := newJobQueue(1, func(job *Job) error {
+		n := attempts.Add(1)
+		if n < 2 {
+			return fmt.Errorf("transient failure")
+		}
+		return nil
+	})
+
+	job, err := q.Enqueue("owner/repo", JobPriorityWebhook)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		found := q.List()
+		for _, j := range found {
+			if j.ID == job.ID && j.Status == JobStatusDone {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("expected job to eventually succeed after a retry")
+}
+
+func TestJobQueuePrioritizesWebhookOverRebuild(t *testing.T) {
+	var mu sync.Mutex
+	var order []JobPriority
+	release := make(chan struct{})
+
+	q := newJobQueue(1, func(job *Job) error {
+		if job.Repo == "owner/blocker" {
+			<-release
+			return nil
+		}
+		mu.Lock()
+		order = append(order, job.Priority)
+		mu.Unlock()
+		return nil
+	})
+
+	// Occupy the single worker so both jobs below queue up before
+	// either one runs.
+	if _, err := q.Enqueue("owner/blocker", JobPriorityScheduled); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := q.Enqueue("owner/rebuild", JobPriorityRebuild); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := q.Enqueue("owner/webhook", JobPriorityWebhook); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(order)
+		mu.Unlock()
+		if n >= 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) == 0 || order[0] != JobPriorityWebhook {
+		t.Errorf("first job run had priority %v, want JobPriorityWebhook", order)
+	}
+}
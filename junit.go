@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// junitTestSuites is the JUnit XML root element. CI systems that
+// understand JUnit render each junitTestCase with a Failure as a failed
+// test, one per policy violation.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// buildJUnit groups policy findings into one testsuite per rule, with a
+// failing testcase per violation, so CI systems can gate on them like
+// any other test failure.
+func buildJUnit(findings []PolicyFinding) junitTestSuites {
+	order := []string{}
+	byRule := map[string][]PolicyFinding{}
+	for _, f := range findings {
+		if _, ok := byRule[f.RuleID]; !ok {
+			order = append(order, f.RuleID)
+		}
+		byRule[f.RuleID] = append(byRule[f.RuleID], f)
+	}
+
+	var suites []junitTestSuite
+	for _, ruleID := range order {
+		ruleFindings := byRule[ruleID]
+
+		var cases []junitTestCase
+		for _, f := range ruleFindings {
+			cases = append(cases, junitTestCase{
+				Name: fileLineName(f.Path, f.Line),
+				Failure: &junitFailure{
+					Message: f.Description + " found in " + f.Repo,
+				},
+			})
+		}
+
+		suites = append(suites, junitTestSuite{
+			Name:      ruleID,
+			Tests:     len(cases),
+			Failures:  len(cases),
+			TestCases: cases,
+		})
+	}
+
+	return junitTestSuites{Suites: suites}
+}
+
+func fileLineName(path string, line int) string {
+	return path + ":" + strconv.Itoa(line)
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestBuildJUnitGroupsByRule(t *testing.T) {
+	findings := []PolicyFinding{
+		{RuleID: "aws-access-key-id", Description: "AWS access key ID", Repo: "owner/repo", Path: "a.go", Line: 1},
+		{RuleID: "aws-access-key-id", Description: "AWS access key ID", Repo: "owner/repo", Path: "b.go", Line: 2},
+		{RuleID: "github-token", Description: "GitHub personal access token", Repo: "owner/repo", Path: "c.go", Line: 3},
+	}
+
+	suites := buildJUnit(findings)
+	if len(suites.Suites) != 2 {
+		t.Fatalf("expected 2 testsuites, got %d", len(suites.Suites))
+	}
+
+	aws := suites.Suites[0]
+	if aws.Name != "aws-access-key-id" || aws.Tests != 2 || aws.Failures != 2 {
+		t.Errorf("aws suite = %+v, want Name=aws-access-key-id Tests=2 Failures=2", aws)
+	}
+	if aws.TestCases[0].Failure == nil {
+		t.Error("expected each testcase to carry a Failure")
+	}
+}
+
+func TestBuildJUnitEmpty(t *testing.T) {
+	suites := buildJUnit(nil)
+	if len(suites.Suites) != 0 {
+		t.Errorf("expected no testsuites for no findings, got %d", len(suites.Suites))
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// KMSProvider wraps and unwraps a per-save data encryption key via an
+// external key management service, for envelope encryption: the state
+// bundle itself is encrypted with a random, one-time data key, and only
+// that (much smaller) key is sent to the KMS to encrypt, so the bulk of
+// the data never leaves the process.
+//
+// The only implementation in this build is staticKMSProvider, which
+// wraps the data key with a locally configured master key instead of
+// calling out to a real key management service. AWS KMS and GCP KMS
+// are the natural next providers to add behind this interface for
+// deployments with strict key-management requirements, but aren't
+// implemented here: this build doesn't vendor either cloud's SDK (the
+// sandbox this was written in has no network access to fetch one), so
+// adding either would mean shipping code that can't actually be built
+// or tested.
+type KMSProvider interface {
+	EncryptDataKey(plaintext []byte) ([]byte, error)
+	DecryptDataKey(ciphertext []byte) ([]byte, error)
+}
+
+// newKMSProvider builds the KMSProvider kind names, configured from
+// cfg. kind is one of "static", "aws", or "gcp".
+func newKMSProvider(kind string, cfg Config) (KMSProvider, error) {
+	switch kind {
+	case "static":
+		key, err := hex.DecodeString(cfg.KMSStaticKey)
+		if err != nil {
+			return nil, fmt.Errorf("decode kms_static_key: %w", err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("kms_static_key must be 32 bytes (64 hex chars) for AES-256, got %d bytes", len(key))
+		}
+		return &staticKMSProvider{key: key}, nil
+	case "aws", "gcp":
+		return nil, fmt.Errorf("kms_provider %q is not implemented in this build: no %s SDK is vendored", kind, kind)
+	default:
+		return nil, fmt.Errorf("unsupported kms_provider: %q", kind)
+	}
+}
+
+// staticKMSProvider wraps a data key with a locally configured master
+// key using AES-256-GCM, standing in for a real KMS call. Suited to
+// local development and testing of the envelope encryption path, or as
+// a deployment's own key-management service if it already has one that
+// isn't AWS or GCP.
+type staticKMSProvider struct {
+	key []byte
+}
+
+func (p *staticKMSProvider) EncryptDataKey(plaintext []byte) ([]byte, error) {
+	return encryptStateBytes([][]byte{p.key}, plaintext)
+}
+
+func (p *staticKMSProvider) DecryptDataKey(ciphertext []byte) ([]byte, error) {
+	return decryptStateBytes([][]byte{p.key}, ciphertext)
+}
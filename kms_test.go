@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStaticKMSProviderWrapUnwrapRoundTrip(t *testing.T) {
+	provider, err := newKMSProvider("static", Config{KMSStaticKey: strings.Repeat("ab", 32)})
+	if err != nil {
+		t.Fatalf("newKMSProvider() error = %v", err)
+	}
+
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	wrapped, err := provider.EncryptDataKey(dataKey)
+	if err != nil {
+		t.Fatalf("EncryptDataKey() error = %v", err)
+	}
+
+	got, err := provider.DecryptDataKey(wrapped)
+	if err != nil {
+		t.Fatalf("DecryptDataKey() error = %v", err)
+	}
+	if string(got) != string(dataKey) {
+		t.Errorf("DecryptDataKey() = %q, want %q", got, dataKey)
+	}
+}
+
+func TestNewKMSProviderRejectsUnimplementedCloudProviders(t *testing.T) {
+	for _, kind := range []string{"aws", "gcp"} {
+		if _, err := newKMSProvider(kind, Config{}); err == nil {
+			t.Errorf("newKMSProvider(%q) expected an error, got none", kind)
+		}
+	}
+}
+
+func TestNewKMSProviderRejectsUnknownKind(t *testing.T) {
+	if _, err := newKMSProvider("azure", Config{}); err == nil {
+		t.Fatal("expected an error for an unsupported kms_provider")
+	}
+}
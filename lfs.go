@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+)
+
+// lfsPointerPrefix identifies a Git LFS pointer file, per the format
+// documented at https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md.
+var lfsPointerPrefix = []byte("version https://git-lfs.github.com/spec/v1")
+
+// LFS modes for Config.LFSMode.
+const (
+	lfsModeSkip    = "skip"    // don't index LFS-tracked files at all
+	lfsModePointer = "pointer" // index the pointer file's own metadata text (default)
+	lfsModeFetch   = "fetch"   // fetch the real content and index that
+)
+
+// isLFSPointer reports whether data is a Git LFS pointer file rather
+// than real file content.
+func isLFSPointer(data []byte) bool {
+	return bytes.HasPrefix(data, lfsPointerPrefix)
+}
+
+// resolveLFS handles an LFS pointer file found at rel (relative to
+// dir) according to mode, returning the content to index and whether
+// the file should be indexed at all.
+func resolveLFS(dir, rel string, data []byte, mode string) ([]byte, bool) {
+	if !isLFSPointer(data) {
+		return data, true
+	}
+
+	switch mode {
+	case lfsModeSkip:
+		return nil, false
+	case lfsModeFetch:
+		cmd := exec.Command("git", "-C", dir, "lfs", "pull", "--include", rel)
+		if err := cmd.Run(); err != nil {
+			return data, true // fall back to indexing the pointer
+		}
+		if fetched, err := os.ReadFile(dir + "/" + rel); err == nil && !isLFSPointer(fetched) {
+			return fetched, true
+		}
+		return data, true
+	default: // lfsModePointer
+		return data, true
+	}
+}
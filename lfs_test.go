@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestIsLFSPointer(t *testing.T) {
+	pointer := []byte("version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 123\n")
+	if !isLFSPointer(pointer) {
+		t.Error("expected pointer content to be detected as an LFS pointer")
+	}
+
+	if isLFSPointer([]byte("just a regular file")) {
+		t.Error("expected regular content not to be detected as an LFS pointer")
+	}
+}
+
+func TestResolveLFSSkip(t *testing.T) {
+	pointer := []byte("version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 123\n")
+
+	data, ok := resolveLFS(t.TempDir(), "asset.bin", pointer, lfsModeSkip)
+	if ok {
+		t.Error("expected skip mode to exclude the file from indexing")
+	}
+	if data != nil {
+		t.Errorf("expected nil data for skipped file, got %q", data)
+	}
+}
+
+func TestResolveLFSPointerMode(t *testing.T) {
+	pointer := []byte("version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 123\n")
+
+	data, ok := resolveLFS(t.TempDir(), "asset.bin", pointer, lfsModePointer)
+	if !ok {
+		t.Fatal("expected pointer mode to index the file")
+	}
+	if string(data) != string(pointer) {
+		t.Errorf("expected pointer mode to index the pointer text unchanged, got %q", data)
+	}
+}
+
+func TestResolveLFSNonPointerUnaffected(t *testing.T) {
+	content := []byte("regular file content")
+
+	data, ok := resolveLFS(t.TempDir(), "notes.txt", content, lfsModeSkip)
+	if !ok {
+		t.Fatal("expected non-pointer content to always be indexed regardless of mode")
+	}
+	if string(data) != string(content) {
+		t.Errorf("expected content unchanged, got %q", data)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLocalDataDir is where local backend clones are stored when the
+// config doesn't set local_data_dir.
+const defaultLocalDataDir = "./data"
+
+// defaultCloneDepth is used when the config doesn't set clone_depth.
+const defaultCloneDepth = 1
+
+// localIndex clones configured repos to disk so backends that need real
+// file content (rather than GitHub's code search API) can operate on
+// them, e.g. binary/hex search.
+type localIndex struct {
+	dataDir           string
+	archiveExtensions []string
+	archiveMaxBytes   int64
+	lfsMode           string
+	sandbox           sandboxLimits
+
+	cloneDepth   int
+	cloneFilter  string
+	cloneOptions map[string]RepoCloneOptions // by repo
+
+	// regexSearchTimeout bounds a searchRegex/searchRegexAtRef scan; see
+	// Config.RegexSearchTimeout. Zero means use defaultRegexSearchTimeout.
+	regexSearchTimeout time.Duration
+
+	quota          *diskQuota
+	diskQuotaBytes int64
+
+	introductions *introductionCache
+	ownership     *ownershipCache
+	symbols       *symbolCache
+
+	trigramMu      sync.Mutex
+	trigramIndexes map[string]*trigramIndex // by repo, see trigramindex.go
+}
+
+func newLocalIndex(dataDir string) *localIndex {
+	if dataDir == "" {
+		dataDir = defaultLocalDataDir
+	}
+	idx := &localIndex{
+		dataDir:           dataDir,
+		archiveExtensions: defaultArchiveExtensions,
+		archiveMaxBytes:   defaultArchiveMaxBytes,
+		lfsMode:           lfsModePointer,
+		sandbox:           sandboxLimits{timeout: defaultCloneTimeout},
+		cloneDepth:        defaultCloneDepth,
+		quota:             newDiskQuota(),
+		introductions:     newIntroductionCache(),
+		ownership:         newOwnershipCache(),
+		symbols:           newSymbolCache(),
+		trigramIndexes:    map[string]*trigramIndex{},
+	}
+	idx.loadPersistedTrigramIndexes()
+	return idx
+}
+
+// clonePath returns the on-disk directory a repo is (or will be) cloned
+// into.
+func (idx *localIndex) clonePath(repo string) string {
+	return filepath.Join(idx.dataDir, repo)
+}
+
+// refPath returns the on-disk directory a repo's historical ref is (or
+// will be) checked out into, as an additional git worktree alongside
+// its main clone.
+func (idx *localIndex) refPath(repo, ref string) string {
+	return filepath.Join(idx.dataDir, ".refs", repo, ref)
+}
+
+// cloneOptionsFor resolves the depth, filter, and sparse-checkout paths
+// to use for repo, applying its per-repo override (if any) over the
+// local index's defaults.
+func (idx *localIndex) cloneOptionsFor(repo string) RepoCloneOptions {
+	opts := RepoCloneOptions{Repo: repo, Depth: idx.cloneDepth, Filter: idx.cloneFilter}
+	if override, ok := idx.cloneOptions[repo]; ok {
+		switch {
+		case override.Depth == -1:
+			opts.Depth = 0 // full history
+		case override.Depth != 0:
+			opts.Depth = override.Depth
+		}
+		if override.Filter != "" {
+			opts.Filter = override.Filter
+		}
+		opts.Sparse = override.Sparse
+		opts.SSHKeyPath = override.SSHKeyPath
+	}
+	return opts
+}
+
+// ensureClone clones repo into the local index if it isn't already
+// present, and returns its on-disk path.
+func (idx *localIndex) ensureClone(repo string) (string, error) {
+	dir := idx.clonePath(repo)
+	idx.quota.touch(repo)
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return dir, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return "", fmt.Errorf("create local index dir: %w", err)
+	}
+
+	opts := idx.cloneOptionsFor(repo)
+
+	args := []string{"clone"}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.Filter != "" {
+		args = append(args, "--filter="+opts.Filter)
+	}
+	if len(opts.Sparse) > 0 {
+		args = append(args, "--sparse")
+	}
+
+	var env []string
+	url := fmt.Sprintf("https://github.com/%s.git", repo)
+	if opts.SSHKeyPath != "" {
+		url = fmt.Sprintf("git@github.com:%s.git", repo)
+		env = []string{"GIT_SSH_COMMAND=ssh -i " + shellQuote(opts.SSHKeyPath) + " -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new"}
+	}
+	args = append(args, url, dir)
+
+	if out, err := idx.sandbox.runEnv(env, "git", args...); err != nil {
+		return "", fmt.Errorf("clone %s: %w: %s", repo, err, out)
+	}
+
+	if len(opts.Sparse) > 0 {
+		setArgs := append([]string{"-C", dir, "sparse-checkout", "set", "--cone"}, opts.Sparse...)
+		if out, err := idx.sandbox.run("git", setArgs...); err != nil {
+			return "", fmt.Errorf("sparse-checkout %s: %w: %s", repo, err, out)
+		}
+	}
+
+	if err := idx.quota.Enforce(idx, idx.diskQuotaBytes); err != nil {
+		return "", fmt.Errorf("enforce disk quota: %w", err)
+	}
+
+	return dir, nil
+}
+
+// headRef returns dir's checked-out commit SHA, for building a
+// click-through URL back to the hosted file a local search result came
+// from. Falls back to the literal "HEAD" (same fallback gitlabClient's
+// blobURL uses) if the SHA can't be resolved.
+func (idx *localIndex) headRef(dir string) string {
+	out, err := idx.sandbox.run("git", "-C", dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "HEAD"
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// ensureRefWorktree checks out repo as of ref (a SHA or tag) into its
+// own on-disk directory, cached across calls, so searching the same
+// historical ref repeatedly doesn't re-fetch or re-checkout it.
+func (idx *localIndex) ensureRefWorktree(repo, ref string) (string, error) {
+	dir, err := idx.ensureClone(repo)
+	if err != nil {
+		return "", err
+	}
+
+	refDir := idx.refPath(repo, ref)
+	if _, err := os.Stat(filepath.Join(refDir, ".git")); err == nil {
+		return refDir, nil
+	}
+
+	if out, err := idx.sandbox.run("git", "-C", dir, "fetch", "origin", ref); err != nil {
+		return "", fmt.Errorf("fetch %s@%s: %w: %s", repo, ref, err, out)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(refDir), 0o755); err != nil {
+		return "", fmt.Errorf("create ref worktree dir: %w", err)
+	}
+
+	if out, err := idx.sandbox.run("git", "-C", dir, "worktree", "add", "--detach", refDir, ref); err != nil {
+		return "", fmt.Errorf("checkout %s@%s: %w: %s", repo, ref, err, out)
+	}
+
+	return refDir, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchTextAtRefUsesRefWorktree(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+
+	repoDir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main // current"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	refDir := idx.refPath(repo, "v1.2.0")
+	if err := os.MkdirAll(filepath.Join(refDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(refDir, "main.go"), []byte("package main // v1.2.0 era"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := idx.searchTextAtRef(repo, "v1.2.0", "v1.2.0 era")
+	if err != nil {
+		t.Fatalf("searchTextAtRef: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result from the ref worktree, got %d", len(results))
+	}
+
+	results, err = idx.searchTextAtRef(repo, "v1.2.0", "current")
+	if err != nil {
+		t.Fatalf("searchTextAtRef: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected 0 results, since the ref worktree should not see the main clone's content, got %d", len(results))
+	}
+}
+
+func TestRefPathIsScopedPerRepoAndRef(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	a := idx.refPath("owner/repo", "v1.0.0")
+	b := idx.refPath("owner/repo", "v2.0.0")
+	if a == b {
+		t.Error("expected different refs to resolve to different paths")
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "fmt"
+
+// Supported values for Config.LocalIndexBackend.
+const (
+	localIndexBackendGrep  = "grep"  // trigram-narrowed substring search (searchText); the default
+	localIndexBackendBleve = "bleve" // full-text index with tokenization, stemming, and ranked results
+)
+
+// validateLocalIndexBackend rejects an unknown or unsupported
+// local_index_backend value at config-compile time, rather than only
+// failing the first local search that hits it.
+//
+// "bleve" is accepted by the config schema but not actually usable yet:
+// this build has no network access to vendor the bleve module, and this
+// repo's policy is to never fake a dependency it doesn't have. Selecting
+// it fails loudly and immediately with that explanation, instead of
+// silently falling back to grep search and giving results a caller
+// might reasonably assume were relevance-ranked.
+func validateLocalIndexBackend(backend string) error {
+	switch backend {
+	case "", localIndexBackendGrep:
+		return nil
+	case localIndexBackendBleve:
+		return fmt.Errorf("local_index_backend: %q is not available in this build (the bleve module isn't vendored); use %q, the default, instead", localIndexBackendBleve, localIndexBackendGrep)
+	default:
+		return fmt.Errorf("local_index_backend: unknown backend %q", backend)
+	}
+}
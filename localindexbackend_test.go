@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestValidateLocalIndexBackendAcceptsGrepAndEmpty(t *testing.T) {
+	for _, backend := range []string{"", "grep"} {
+		if err := validateLocalIndexBackend(backend); err != nil {
+			t.Errorf("validateLocalIndexBackend(%q): %v", backend, err)
+		}
+	}
+}
+
+func TestValidateLocalIndexBackendRejectsBleve(t *testing.T) {
+	err := validateLocalIndexBackend("bleve")
+	if err == nil {
+		t.Fatal("expected an error selecting the unavailable bleve backend")
+	}
+}
+
+func TestValidateLocalIndexBackendRejectsUnknown(t *testing.T) {
+	if err := validateLocalIndexBackend("elasticsearch"); err == nil {
+		t.Error("expected an error for an unrecognized backend")
+	}
+}
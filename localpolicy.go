@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// checkPolicy clones repo (if needed) and scans every text file in it
+// against the built-in policy rules (hardcoded secrets, private keys),
+// reusing the same symlink and case-collision guards as searchText.
+func (idx *localIndex) checkPolicy(repo string) ([]PolicyFinding, error) {
+	dir, err := idx.ensureClone(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []PolicyFinding
+	seen := newCaseFolder()
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if seen.collides(rel) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(data, 0) != -1 {
+			return nil // unreadable or binary
+		}
+
+		findings = append(findings, checkPolicyContent(defaultPolicyRules, repo, rel, data)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return findings, nil
+}
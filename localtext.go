@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxRegexPatternLength bounds how large a regex= pattern searchRegex
+// will accept, so an attacker-sized pattern is rejected outright rather
+// than handed to regexp.Compile at all.
+const maxRegexPatternLength = 512
+
+// defaultRegexSearchTimeout is used when the config doesn't set
+// regex_search_timeout.
+const defaultRegexSearchTimeout = 10 * time.Second
+
+// compileSearchRegex compiles pattern for use in a regex search,
+// rejecting one over maxRegexPatternLength up front. Go's regexp
+// package is RE2-based, so a compiled pattern can't exhibit the
+// catastrophic backtracking a backtracking engine (PCRE, etc.) would be
+// vulnerable to; the length limit here and the scan-wide timeout in
+// searchDirRegex are both just a defensive ceiling on how much work one
+// request can demand.
+func compileSearchRegex(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > maxRegexPatternLength {
+		return nil, fmt.Errorf("regex pattern too long: %d bytes (max %d)", len(pattern), maxRegexPatternLength)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex pattern: %w", err)
+	}
+	return re, nil
+}
+
+// searchText clones repo (if needed) and scans its files for term,
+// flattening structured or minified files first so matches land on
+// meaningful lines. Binary files are skipped, and archive members are
+// indexed with a "archive/path!inner/path" path so results still point
+// at a real location. Each result's URL links back to the file on
+// github.com at the clone's current HEAD, exactly like a backend-API
+// result's, so callers can't tell a local-index result from one served
+// via the search API.
+//
+// The scan is narrowed to the files repo's persisted trigram index (see
+// trigramindex.go) says could possibly contain term, so a repeated
+// search over a large clone doesn't have to read every file again.
+func (idx *localIndex) searchText(repo, term string) ([]Result, error) {
+	dir, err := idx.ensureClone(repo)
+	if err != nil {
+		return nil, err
+	}
+	candidates, narrowed := idx.trigramCandidates(repo, dir, term)
+	return idx.searchDirText(repo, dir, idx.headRef(dir), term, candidates, narrowed)
+}
+
+// searchTextAtRef checks out repo as of ref (a SHA or tag), caching the
+// checkout on disk, and scans it for term exactly like searchText scans
+// the default branch. This is what answers "did this string exist in
+// v1.2.0?" without disturbing the repo's regular clone. Each result's
+// URL points at ref rather than HEAD.
+//
+// Historical ref checkouts aren't trigram-indexed (they're one-off, and
+// indexing every ref ever searched would grow without bound), so this
+// always does a full scan.
+func (idx *localIndex) searchTextAtRef(repo, ref, term string) ([]Result, error) {
+	dir, err := idx.ensureRefWorktree(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	return idx.searchDirText(repo, dir, ref, term, nil, false)
+}
+
+// searchRegex clones repo (if needed) and scans its files for pattern, a
+// regular expression compiled safely via compileSearchRegex - GitHub's
+// code search API has no regex equivalent, so this is a key
+// differentiator of local mode. Unlike searchText, this is never
+// trigram-narrowed: an arbitrary regex can't generally be reduced to a
+// small set of required trigrams, so it always does a full scan, bounded
+// by regexSearchTimeout so a pathological pattern or a very large clone
+// can't tie up a request indefinitely.
+func (idx *localIndex) searchRegex(repo, pattern string) ([]Result, error) {
+	dir, err := idx.ensureClone(repo)
+	if err != nil {
+		return nil, err
+	}
+	return idx.searchDirRegex(repo, dir, idx.headRef(dir), pattern)
+}
+
+// searchRegexAtRef is searchRegex against repo as of a historical ref (a
+// SHA or tag) instead of its default branch.
+func (idx *localIndex) searchRegexAtRef(repo, ref, pattern string) ([]Result, error) {
+	dir, err := idx.ensureRefWorktree(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	return idx.searchDirRegex(repo, dir, ref, pattern)
+}
+
+// searchDirRegex scans dir (a working tree of repo) for pattern. ref is
+// the commit or ref results' URLs should point at. Archive members
+// aren't descended into for a regex search, unlike searchDirText - doing
+// so would mean extracting every archive up front rather than only the
+// ones a literal substring's trigram candidates narrowed things down to.
+func (idx *localIndex) searchDirRegex(repo, dir, ref, pattern string) ([]Result, error) {
+	re, err := compileSearchRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := idx.regexSearchTimeout
+	if timeout <= 0 {
+		timeout = defaultRegexSearchTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var results []Result
+	seen := newCaseFolder()
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil // don't follow symlinks; a malicious repo could point one outside the clone
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if seen.collides(rel) || isArchive(rel, idx.archiveExtensions) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		data, ok := resolveLFS(dir, rel, data, idx.lfsMode)
+		if !ok {
+			return nil
+		}
+
+		if r := matchFileRegex(repo, rel, ref, data, re); r != nil {
+			results = append(results, *r)
+		}
+		return nil
+	})
+	if err == context.DeadlineExceeded {
+		return nil, fmt.Errorf("regex search timed out after %s (pattern too expensive for this clone's size)", timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// searchDirText scans dir (a working tree of repo, either its main
+// clone or a historical ref's checkout) for term. ref is the commit or
+// ref results' URLs should point at. When narrowed is true, only files
+// present in candidates (plus every archive, which the trigram index
+// doesn't cover) are scanned; when false, every file is scanned exactly
+// as before the trigram index existed.
+func (idx *localIndex) searchDirText(repo, dir, ref, term string, candidates map[string]bool, narrowed bool) ([]Result, error) {
+	needle := strings.ToLower(term)
+	var results []Result
+	seen := newCaseFolder()
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil // don't follow symlinks; a malicious repo could point one outside the clone
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if seen.collides(rel) {
+			return nil
+		}
+		if narrowed && !candidates[rel] && !isArchive(rel, idx.archiveExtensions) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		data, ok := resolveLFS(dir, rel, data, idx.lfsMode)
+		if !ok {
+			return nil
+		}
+
+		if isArchive(rel, idx.archiveExtensions) {
+			entries, err := extractArchive(rel, data, idx.archiveMaxBytes)
+			if err != nil {
+				return nil // corrupt/unreadable archive; skip it
+			}
+			for _, entry := range entries {
+				if r := matchFile(repo, rel+"!"+entry.Path, ref, entry.Data, needle, term); r != nil {
+					results = append(results, *r)
+				}
+			}
+			return nil
+		}
+
+		if r := matchFile(repo, rel, ref, data, needle, term); r != nil {
+			results = append(results, *r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// matchFile searches a single file's content for needle, returning a
+// Result if it matched or nil (including for binary content, which is
+// skipped) if it didn't. ref is used to build the result's click-through
+// URL; for an archive member (path holds a "!inner/path" suffix) the URL
+// points at the archive file itself, since that's the real location on
+// github.com.
+func matchFile(repo, path, ref string, data []byte, needle, term string) *Result {
+	if bytes.IndexByte(data, 0) != -1 {
+		return nil // binary content
+	}
+
+	flat := flattenContent(path, data)
+
+	var lines []Line
+	for i, raw := range strings.Split(string(flat), "\n") {
+		lower := strings.ToLower(raw)
+		start := strings.Index(lower, needle)
+		if start == -1 {
+			continue
+		}
+		lines = append(lines, Line{
+			Number:  i + 1,
+			Text:    raw,
+			Offsets: []Offset{{Start: start, End: start + len(term), Term: term}},
+		})
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	urlPath, _, _ := strings.Cut(path, "!")
+	return &Result{
+		Repo:     repo,
+		Path:     path,
+		URL:      fmt.Sprintf("https://github.com/%s/blob/%s/%s", repo, ref, urlPath),
+		FileType: detectFileType(path, string(flat)),
+		Lines:    lines,
+	}
+}
+
+// matchFileRegex is matchFile for a regex search: it searches a single
+// file's content for re, returning a Result if any line matched or nil
+// (including for binary content, which is skipped) if none did.
+func matchFileRegex(repo, path, ref string, data []byte, re *regexp.Regexp) *Result {
+	if bytes.IndexByte(data, 0) != -1 {
+		return nil // binary content
+	}
+
+	flat := flattenContent(path, data)
+
+	var lines []Line
+	for i, raw := range strings.Split(string(flat), "\n") {
+		loc := re.FindStringIndex(raw)
+		if loc == nil {
+			continue
+		}
+		lines = append(lines, Line{
+			Number:  i + 1,
+			Text:    raw,
+			Offsets: []Offset{{Start: loc[0], End: loc[1], Term: raw[loc[0]:loc[1]]}},
+		})
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return &Result{
+		Repo:     repo,
+		Path:     path,
+		URL:      fmt.Sprintf("https://github.com/%s/blob/%s/%s", repo, ref, path),
+		FileType: detectFileType(path, string(flat)),
+		Lines:    lines,
+	}
+}
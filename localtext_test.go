@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchTextFlattensNotebook(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+	repoDir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	nb := `{"cells":[{"cell_type":"code","source":["print(\"hello world\")"]}]}`
+	if err := os.WriteFile(filepath.Join(repoDir, "notebook.ipynb"), []byte(nb), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	results, err := idx.searchText(repo, "hello world")
+	if err != nil {
+		t.Fatalf("searchText: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if len(results[0].Lines) != 1 {
+		t.Fatalf("expected 1 matching line, got %d", len(results[0].Lines))
+	}
+	if want := "https://github.com/owner/repo/blob/HEAD/notebook.ipynb"; results[0].URL != want {
+		t.Errorf("URL = %q, want %q (HEAD is the fallback ref for a non-real git clone)", results[0].URL, want)
+	}
+}
+
+func TestSearchRegexMatchesFilesByPattern(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+	repoDir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("func run() {}\nfunc walk() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "readme.md"), []byte("no functions here\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	results, err := idx.searchRegex(repo, `func \w+\(\)`)
+	if err != nil {
+		t.Fatalf("searchRegex: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].Path != "main.go" {
+		t.Errorf("Path = %q, want main.go", results[0].Path)
+	}
+	if len(results[0].Lines) != 2 {
+		t.Fatalf("expected 2 matching lines, got %d", len(results[0].Lines))
+	}
+}
+
+func TestSearchRegexRejectsInvalidPattern(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	if _, err := idx.searchRegex("owner/repo", `(unclosed`); err == nil {
+		t.Fatal("expected an error for an invalid regex pattern")
+	}
+}
+
+func TestSearchRegexRejectsOverlongPattern(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	pattern := strings.Repeat("a", maxRegexPatternLength+1)
+	if _, err := idx.searchRegex("owner/repo", pattern); err == nil {
+		t.Fatal("expected an error for a pattern over maxRegexPatternLength")
+	}
+}
+
+func TestSearchRegexTimesOutOnSlowScan(t *testing.T) {
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+	repoDir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	idx.regexSearchTimeout = time.Nanosecond
+	if _, err := idx.searchRegex(repo, "hello"); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+}
+
+func TestMatchFileURLPointsAtArchiveFileNotInnerPath(t *testing.T) {
+	r := matchFile("owner/repo", "assets.zip!inner/main.go", "abc123", []byte("hello world"), "hello", "hello")
+	if r == nil {
+		t.Fatal("expected a match")
+	}
+	if want := "https://github.com/owner/repo/blob/abc123/assets.zip"; r.URL != want {
+		t.Errorf("URL = %q, want %q", r.URL, want)
+	}
+}
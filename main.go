@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// appVersion identifies this build in telemetry reports and anywhere
+// else the running version needs to be surfaced. Overridden at build
+// time via -ldflags "-X main.appVersion=...".
+var appVersion = "dev"
+
+// appCommit and buildDate identify exactly what was built, alongside
+// appVersion. Both are overridden at build time via
+// -ldflags "-X main.appCommit=... -X main.buildDate=...". When a build
+// doesn't pass ldflags (e.g. a plain "go build"), appCommit falls back
+// to the VCS revision the Go toolchain itself embeds.
+var (
+	appCommit = ""
+	buildDate = ""
+)
+
+func init() {
+	if appCommit != "" {
+		return
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			appCommit = setting.Value
+		}
+	}
+}
+
+// userAgent is sent with every outbound request to a backend (GitHub,
+// GitLab, etc.), so operators reading that backend's own access logs
+// can correlate unexpected behavior with a specific git_search release.
+var userAgent = "git_search/" + appVersion
+
+// versionInfo is the response body for GET /version.
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+
+	// LatestRelease and UpdateAvailable are populated once
+	// self_update_check.enabled has completed its first check; both are
+	// omitted while disabled or still pending. SelfUpdateCheckError
+	// reports the last check's failure, if any, without failing the
+	// whole request.
+	LatestRelease        string `json:"latest_release,omitempty"`
+	UpdateAvailable      bool   `json:"update_available,omitempty"`
+	SelfUpdateCheckError string `json:"self_update_check_error,omitempty"`
+}
+
+// currentVersionInfo returns this build's versionInfo.
+func currentVersionInfo() versionInfo {
+	return versionInfo{
+		Version:   appVersion,
+		Commit:    appCommit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// atomicHandler lets the process swap its whole routing tree (a single
+// server's routes, or a profileRouter) at runtime, e.g. when
+// watchConfig detects a config reload.
+type atomicHandler struct {
+	handler atomic.Pointer[http.Handler]
+}
+
+func (h *atomicHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.handler.Load()).ServeHTTP(w, r)
+}
+
+func (h *atomicHandler) Store(handler http.Handler) {
+	h.handler.Store(&handler)
+}
+
+// buildHandler compiles cfg into the routing tree for the whole
+// process: a profileRouter if cfg defines multiple profiles, or a
+// single server's routes otherwise.
+func buildHandler(cfg Config) (http.Handler, error) {
+	if len(cfg.Profiles) > 0 {
+		return newProfileRouter(cfg.Profiles)
+	}
+
+	srv, err := newServer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return srv.routes(), nil
+}
+
+// runSelftest is the "git_search selftest <config_file>" subcommand: a
+// live end-to-end check suited to deploy-time smoke testing. It prints
+// a report and exits non-zero if any check failed.
+func runSelftest(args []string) {
+	fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "usage: git_search selftest <config_file>")
+		os.Exit(1)
+	}
+
+	cfg, err := LoadConfig(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	results := RunSelftest(cfg)
+	fmt.Print(FormatSelftestReport(results))
+	if !SelftestPassed(results) {
+		os.Exit(1)
+	}
+}
+
+// runImportRepos is the "git_search import-repos <config_file>
+// <import_file>" subcommand: it bulk-imports repos from a CSV/JSON
+// export into the config file's repos list, for offline provisioning
+// ahead of a deploy. A running process can accept the same import
+// live via POST /admin/repos/import.
+func runImportRepos(args []string) {
+	fs := flag.NewFlagSet("import-repos", flag.ExitOnError)
+	format := fs.String("format", "csv", "import file format: csv or json")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "usage: git_search import-repos [--format=csv|json] <config_file> <import_file>")
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(fs.Arg(1))
+	if err != nil {
+		log.Fatalf("read import file: %v", err)
+	}
+
+	result, err := MergeRepoImportIntoConfigFile(fs.Arg(0), *format, data)
+	if err != nil {
+		log.Fatalf("import repos: %v", err)
+	}
+
+	fmt.Printf("added %d repo(s), skipped %d already configured\n", len(result.Added), len(result.Skipped))
+	for _, repo := range result.Added {
+		fmt.Println("  +", repo)
+	}
+}
+
+// runMigrateOnly loads and re-saves the state file at cfg.StatePersistPath,
+// running any pending stateMigrations and writing the result back to
+// disk, without starting the server. Suited to a controlled production
+// rollout: run it as a deploy step ahead of shipping code that expects
+// a newer state bundle shape, instead of migrating implicitly on the
+// next server start.
+func runMigrateOnly(cfg Config) {
+	if cfg.StatePersistPath == "" {
+		log.Fatal("--migrate-only requires state_persist_path to be set in the config file")
+	}
+
+	var store StateStore = newFileStateStore(cfg.StatePersistPath)
+	switch {
+	case cfg.KMSProvider != "":
+		kms, err := newKMSProvider(cfg.KMSProvider, cfg)
+		if err != nil {
+			log.Fatalf("init kms_provider: %v", err)
+		}
+		store = newEnvelopeFileStateStore(cfg.StatePersistPath, kms)
+	case len(cfg.StateEncryptionKeys) > 0:
+		keys, err := parseStateEncryptionKeys(cfg.StateEncryptionKeys)
+		if err != nil {
+			log.Fatalf("parse state_encryption_keys: %v", err)
+		}
+		store = newEncryptedFileStateStore(cfg.StatePersistPath, keys)
+	}
+
+	bundle, err := store.Load()
+	if err != nil {
+		log.Fatalf("load state file: %v", err)
+	}
+	if err := store.Save(bundle); err != nil {
+		log.Fatalf("save migrated state file: %v", err)
+	}
+	fmt.Printf("state file at %s is at version %d\n", cfg.StatePersistPath, bundle.Version)
+}
+
+// runBackup is the "git_search backup <config_file> <output_file>"
+// subcommand: it archives the state file and local backend clones into
+// a single gzipped tar file. Uploading that file to object storage is
+// left to the caller, e.g. piping runBackup's own stdout output through
+// an object storage CLI.
+func runBackup(args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "usage: git_search backup <config_file> <output_file>")
+		os.Exit(1)
+	}
+
+	cfg, err := LoadConfig(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	out, err := os.Create(fs.Arg(1))
+	if err != nil {
+		log.Fatalf("create backup file: %v", err)
+	}
+	defer out.Close()
+
+	if err := CreateBackup(cfg, out); err != nil {
+		log.Fatalf("create backup: %v", err)
+	}
+	fmt.Printf("wrote backup to %s\n", fs.Arg(1))
+}
+
+// runRestore is the "git_search restore <config_file> <backup_file>"
+// subcommand: the inverse of runBackup, overwriting the config's state
+// file and local backend clones with the archive's contents.
+func runRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "usage: git_search restore <config_file> <backup_file>")
+		os.Exit(1)
+	}
+
+	cfg, err := LoadConfig(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	in, err := os.Open(fs.Arg(1))
+	if err != nil {
+		log.Fatalf("open backup file: %v", err)
+	}
+	defer in.Close()
+
+	if err := RestoreBackup(cfg, in); err != nil {
+		log.Fatalf("restore backup: %v", err)
+	}
+	fmt.Printf("restored backup from %s\n", fs.Arg(1))
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		runInit(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "tui" {
+		runTUI(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "search" {
+		runSearch(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "completion" {
+		runCompletion(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "selftest" {
+		runSelftest(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-repos" {
+		runImportRepos(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backup" {
+		runBackup(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		runRestore(os.Args[2:])
+		return
+	}
+
+	printConfig := flag.Bool("print-config", false, "print the fully merged effective configuration (secrets redacted) and exit")
+	migrateOnly := flag.Bool("migrate-only", false, "run pending state store migrations and exit, without starting the server")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "usage: git_search [--print-config] [--migrate-only] <config_file>")
+		os.Exit(1)
+	}
+	configPath := flag.Arg(0)
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	if *printConfig {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(cfg.Redacted()); err != nil {
+			log.Fatalf("print config: %v", err)
+		}
+		return
+	}
+
+	if *migrateOnly {
+		runMigrateOnly(cfg)
+		return
+	}
+
+	handler, err := buildHandler(cfg)
+	if err != nil {
+		log.Fatalf("init server: %v", err)
+	}
+
+	var root atomicHandler
+	root.Store(handler)
+
+	if cfg.WatchConfig {
+		interval, _ := time.ParseDuration(cfg.ConfigWatchInterval)
+		go watchConfig(configPath, interval, func(newCfg Config) {
+			newHandler, err := buildHandler(newCfg)
+			if err != nil {
+				log.Printf("config reload from %s failed: %v", configPath, err)
+				return
+			}
+			root.Store(newHandler)
+			log.Printf("config reloaded from %s", configPath)
+		})
+	}
+
+	addr := fmt.Sprintf(":%d", cfg.Port)
+	log.Printf("git_search %s (%s) listening on %s", appVersion, appCommit, addr)
+	srv := &http.Server{
+		Addr:           addr,
+		Handler:        &root,
+		MaxHeaderBytes: cfg.MaxHeaderBytes,
+	}
+	log.Fatal(srv.ListenAndServe())
+}
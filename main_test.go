@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestCurrentVersionInfo(t *testing.T) {
+	oldVersion, oldCommit, oldDate := appVersion, appCommit, buildDate
+	defer func() { appVersion, appCommit, buildDate = oldVersion, oldCommit, oldDate }()
+
+	appVersion = "1.2.3"
+	appCommit = "abc123"
+	buildDate = "2026-08-08"
+
+	info := currentVersionInfo()
+	if info.Version != "1.2.3" || info.Commit != "abc123" || info.BuildDate != "2026-08-08" {
+		t.Errorf("unexpected version info: %+v", info)
+	}
+	if info.GoVersion == "" {
+		t.Error("expected a non-empty GoVersion")
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import "fmt"
+
+// stateMigration upgrades a StateBundle from one version to the next.
+// Registered in stateMigrations under the version it upgrades *from*.
+type stateMigration func(StateBundle) StateBundle
+
+// stateMigrations holds one entry per past StateBundle shape change,
+// keyed by the version it upgrades from. Empty today since
+// stateBundleVersion has never been bumped; the next time a field is
+// added or renamed in a breaking way, add an entry here instead of
+// bumping stateBundleVersion and leaving old bundles unreadable.
+var stateMigrations = map[int]stateMigration{}
+
+// migrateStateBundle walks bundle forward through stateMigrations, one
+// version at a time, until it reaches stateBundleVersion. Called
+// automatically by fileStateStore.Load, and by the --migrate-only CLI
+// mode for a controlled, out-of-band rollout ahead of deploying code
+// that expects the newer shape.
+func migrateStateBundle(bundle StateBundle) (StateBundle, error) {
+	for bundle.Version < stateBundleVersion {
+		migrate, ok := stateMigrations[bundle.Version]
+		if !ok {
+			return StateBundle{}, fmt.Errorf("no migration registered from state bundle version %d to %d", bundle.Version, bundle.Version+1)
+		}
+		bundle = migrate(bundle)
+		bundle.Version++
+	}
+	if bundle.Version > stateBundleVersion {
+		return StateBundle{}, fmt.Errorf("state bundle version %d is newer than this build understands (%d)", bundle.Version, stateBundleVersion)
+	}
+	return bundle, nil
+}
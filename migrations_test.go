@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestMigrateStateBundleAlreadyCurrentIsUnchanged(t *testing.T) {
+	bundle := StateBundle{Version: stateBundleVersion, ImportedRepos: []string{"bjatkin/golf-engine"}}
+
+	got, err := migrateStateBundle(bundle)
+	if err != nil {
+		t.Fatalf("migrateStateBundle() error = %v", err)
+	}
+	if got.Version != stateBundleVersion || len(got.ImportedRepos) != 1 {
+		t.Errorf("migrateStateBundle() = %+v, want unchanged bundle", got)
+	}
+}
+
+func TestMigrateStateBundleUnknownOlderVersionErrors(t *testing.T) {
+	_, err := migrateStateBundle(StateBundle{Version: stateBundleVersion - 1})
+	if err == nil {
+		t.Fatal("expected an error for a version with no registered migration")
+	}
+}
+
+func TestMigrateStateBundleNewerThanKnownErrors(t *testing.T) {
+	_, err := migrateStateBundle(StateBundle{Version: stateBundleVersion + 1})
+	if err == nil {
+		t.Fatal("expected an error for a bundle version newer than this build understands")
+	}
+}
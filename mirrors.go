@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MirrorStatus describes the local backend's clone of one configured
+// repo, for the /admin/mirrors endpoint.
+type MirrorStatus struct {
+	Repo        string    `json:"repo"`
+	Cloned      bool      `json:"cloned"`
+	Paused      bool      `json:"paused"`
+	LastSyncAt  time.Time `json:"last_sync_at,omitempty"`
+	LastSyncErr string    `json:"last_sync_error,omitempty"`
+}
+
+// mirrorManager tracks sync state for the local backend's repo clones,
+// on top of the plain clone-on-first-use behavior localIndex provides on
+// its own.
+type mirrorManager struct {
+	mu     sync.Mutex
+	status map[string]*MirrorStatus
+}
+
+func newMirrorManager() *mirrorManager {
+	return &mirrorManager{status: map[string]*MirrorStatus{}}
+}
+
+// statusFor returns repo's status, creating a default entry the first
+// time it's asked about.
+func (m *mirrorManager) statusFor(repo string) *MirrorStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.status[repo]
+	if !ok {
+		st = &MirrorStatus{Repo: repo}
+		m.status[repo] = st
+	}
+	return st
+}
+
+// List returns the status of every repo configured for idx, in
+// configured order, reflecting whether each has been cloned to disk yet.
+func (m *mirrorManager) List(idx *localIndex, repos []string) []MirrorStatus {
+	var out []MirrorStatus
+	for _, repo := range repos {
+		st := *m.statusFor(repo)
+		_, err := os.Stat(filepath.Join(idx.clonePath(repo), ".git"))
+		st.Cloned = err == nil
+		out = append(out, st)
+	}
+	return out
+}
+
+// SetPaused pauses or resumes syncing for repo. A paused repo is left
+// alone by Sync until resumed.
+func (m *mirrorManager) SetPaused(repo string, paused bool) {
+	m.statusFor(repo).Paused = paused
+}
+
+// Sync clones repo if it isn't present yet, or pulls the latest commits
+// into its existing clone otherwise, recording the outcome. Sync refuses
+// to run on a paused repo.
+func (m *mirrorManager) Sync(idx *localIndex, repo string) error {
+	st := m.statusFor(repo)
+	if st.Paused {
+		return fmt.Errorf("mirror for %s is paused", repo)
+	}
+
+	dir, err := idx.ensureClone(repo)
+	if err == nil {
+		if _, statErr := os.Stat(filepath.Join(dir, ".git")); statErr == nil {
+			if out, pullErr := idx.sandbox.run("git", "-C", dir, "pull", "--ff-only"); pullErr != nil {
+				err = fmt.Errorf("pull %s: %w: %s", repo, pullErr, out)
+			}
+		}
+	}
+
+	m.mu.Lock()
+	st.LastSyncAt = time.Now()
+	if err != nil {
+		st.LastSyncErr = err.Error()
+	} else {
+		st.LastSyncErr = ""
+	}
+	m.mu.Unlock()
+
+	return err
+}
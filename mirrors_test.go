@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestMirrorManagerListUncloned(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	m := newMirrorManager()
+
+	statuses := m.List(idx, []string{"owner/repo"})
+	if len(statuses) != 1 {
+		t.Fatalf("expected 1 status, got %d", len(statuses))
+	}
+	if statuses[0].Cloned {
+		t.Error("expected an unclonded repo to report Cloned = false")
+	}
+	if statuses[0].Paused {
+		t.Error("expected a fresh repo to start unpaused")
+	}
+}
+
+func TestMirrorManagerPauseBlocksSync(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	m := newMirrorManager()
+
+	m.SetPaused("owner/repo", true)
+	if err := m.Sync(idx, "owner/repo"); err == nil {
+		t.Error("expected Sync to refuse to run on a paused repo")
+	}
+
+	m.SetPaused("owner/repo", false)
+	statuses := m.List(idx, []string{"owner/repo"})
+	if statuses[0].Paused {
+		t.Error("expected repo to be unpaused after SetPaused(false)")
+	}
+}
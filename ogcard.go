@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+)
+
+// writeOGCard writes a minimal HTML page carrying Open Graph metadata
+// for a shared result link, so pasting a /r/{id} short link into Slack,
+// iMessage, or another chat app that unfurls links shows a meaningful
+// title and code snippet instead of a bare URL. It immediately
+// meta-refreshes a real browser on to target, since a link-unfurling bot
+// reads the tags without following that refresh.
+func writeOGCard(w io.Writer, target, title, snippet string) {
+	if title == "" {
+		title = target
+	}
+
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="0;url=%s">
+<meta property="og:type" content="website">
+<meta property="og:title" content="%s">
+<meta property="og:description" content="%s">
+<meta property="og:url" content="%s">
+<title>%s</title>
+</head>
+<body>
+<p><a href="%s">%s</a></p>
+<pre>%s</pre>
+</body>
+</html>
+`,
+		html.EscapeString(target),
+		html.EscapeString(title),
+		html.EscapeString(snippet),
+		html.EscapeString(target),
+		html.EscapeString(title),
+		html.EscapeString(target),
+		html.EscapeString(title),
+		html.EscapeString(snippet),
+	)
+}
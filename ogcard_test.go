@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteOGCard(t *testing.T) {
+	var out strings.Builder
+	writeOGCard(&out, "https://github.com/o/r/blob/main/a.go", "o/r a.go", "func main() {}")
+
+	html := out.String()
+	for _, want := range []string{
+		`property="og:title" content="o/r a.go"`,
+		`property="og:description" content="func main() {}"`,
+		`content="0;url=https://github.com/o/r/blob/main/a.go"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("output missing %q, got: %s", want, html)
+		}
+	}
+}
+
+func TestWriteOGCardFallsBackToTargetForEmptyTitle(t *testing.T) {
+	var out strings.Builder
+	writeOGCard(&out, "https://example.com/a.go", "", "")
+
+	if !strings.Contains(out.String(), `property="og:title" content="https://example.com/a.go"`) {
+		t.Errorf("expected og:title to fall back to the target url, got: %s", out.String())
+	}
+}
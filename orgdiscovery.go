@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// githubOrgRepoPrefix and githubUserRepoPrefix mark a repos: entry as a
+// GitHub org or user to auto-discover repos from (e.g. "org:mycompany"
+// or "user:bjatkin"), instead of a literal "owner/name" repo. Mirrors
+// gitlabRepoPrefix's precedent of overloading repos: entries with a
+// prefix rather than adding a whole separate config list.
+const (
+	githubOrgRepoPrefix  = "org:"
+	githubUserRepoPrefix = "user:"
+)
+
+// defaultOrgDiscoveryInterval is how often a discoverer re-lists any
+// org:/user: entries when org_discovery_interval is unset.
+const defaultOrgDiscoveryInterval = time.Hour
+
+// hasOrgDiscoveryPrefix reports whether repo is an org:/user: discovery
+// entry rather than a literal repo.
+func hasOrgDiscoveryPrefix(repo string) bool {
+	return strings.HasPrefix(repo, githubOrgRepoPrefix) || strings.HasPrefix(repo, githubUserRepoPrefix)
+}
+
+// hasAnyOrgDiscoveryPrefix reports whether any entry in repos needs
+// discovering, so callers can skip starting discovery machinery
+// entirely for a config that doesn't use it.
+func hasAnyOrgDiscoveryPrefix(repos []string) bool {
+	for _, repo := range repos {
+		if hasOrgDiscoveryPrefix(repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasGlobPattern reports whether repo is an "owner/pattern" entry (e.g.
+// "bjatkin/tool-*") to resolve against owner's repo list, rather than a
+// literal repo. An org:/user: entry is handled by hasOrgDiscoveryPrefix
+// instead, even though "org:my-*" would also contain glob characters.
+func hasGlobPattern(repo string) bool {
+	if hasOrgDiscoveryPrefix(repo) {
+		return false
+	}
+	return strings.ContainsAny(repo, "*?[")
+}
+
+// needsRepoDiscovery reports whether any entry in repos needs live
+// resolution against the GitHub API (an org:/user: entry or an
+// owner/glob pattern), so callers can skip starting discovery machinery
+// entirely for a config that only lists literal repos.
+func needsRepoDiscovery(repos []string) bool {
+	for _, repo := range repos {
+		if hasOrgDiscoveryPrefix(repo) || hasGlobPattern(repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// githubRepoListing is the subset of GitHub's repo object the
+// list-repos-for-org/list-repos-for-user endpoints need:
+// https://docs.github.com/rest/repos/repos#list-organization-repositories
+type githubRepoListing struct {
+	FullName string `json:"full_name"`
+	Archived bool   `json:"archived"`
+}
+
+// orgRepoDiscoverer expands "org:name"/"user:name" repos: entries into
+// the actual repos GitHub reports for that org/user, keeping its most
+// recent listing per entry so a newly created repo starts appearing in
+// search results the next time Refresh runs, without editing the
+// config file.
+type orgRepoDiscoverer struct {
+	mu       sync.Mutex
+	resolved map[string][]string // discovery entry -> its last known repo listing
+
+	client  *http.Client
+	baseURL string // overridden by tests; defaults to the real GitHub API
+}
+
+func newOrgRepoDiscoverer() *orgRepoDiscoverer {
+	return &orgRepoDiscoverer{
+		resolved: map[string][]string{},
+		client:   &http.Client{Timeout: 30 * time.Second},
+		baseURL:  "https://api.github.com",
+	}
+}
+
+// Expand returns repos with every org:/user: entry and owner/glob
+// pattern replaced by its most recently discovered repos (dropped if
+// nothing has been discovered for it yet), plain entries passed through
+// unchanged, and duplicates (an org/user or glob match also listed
+// literally) collapsed.
+func (d *orgRepoDiscoverer) Expand(repos []string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var out []string
+	seen := map[string]bool{}
+	add := func(repo string) {
+		if !seen[repo] {
+			seen[repo] = true
+			out = append(out, repo)
+		}
+	}
+	for _, repo := range repos {
+		if !hasOrgDiscoveryPrefix(repo) && !hasGlobPattern(repo) {
+			add(repo)
+			continue
+		}
+		for _, resolved := range d.resolved[repo] {
+			add(resolved)
+		}
+	}
+	return out
+}
+
+// Refresh re-resolves every org:/user: entry and owner/glob pattern in
+// repos against the GitHub API, updating what Expand returns for it.
+// token authenticates the request, needed for a private org/user and to
+// avoid the low unauthenticated rate limit. A failed refresh for one
+// entry is returned as part of a combined error but doesn't stop the
+// rest, and leaves that entry's last known listing in place rather than
+// dropping its repos.
+func (d *orgRepoDiscoverer) Refresh(repos []string, token string) error {
+	var errs []string
+	for _, repo := range repos {
+		var (
+			listing []string
+			err     error
+		)
+		switch {
+		case hasOrgDiscoveryPrefix(repo):
+			listing, err = d.list(repo, token)
+		case hasGlobPattern(repo):
+			listing, err = d.listGlob(repo, token)
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		d.mu.Lock()
+		d.resolved[repo] = listing
+		d.mu.Unlock()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("org repo discovery: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// list fetches every page of entry's repos ("org:name" or "user:name")
+// from the GitHub API.
+func (d *orgRepoDiscoverer) list(entry, token string) ([]string, error) {
+	var kind, name string
+	switch {
+	case strings.HasPrefix(entry, githubOrgRepoPrefix):
+		kind, name = "orgs", strings.TrimPrefix(entry, githubOrgRepoPrefix)
+	case strings.HasPrefix(entry, githubUserRepoPrefix):
+		kind, name = "users", strings.TrimPrefix(entry, githubUserRepoPrefix)
+	default:
+		return nil, fmt.Errorf("%q is not an org:/user: entry", entry)
+	}
+	return d.listPaged(kind, name, token)
+}
+
+// listGlob resolves an "owner/pattern" entry by listing every repo
+// owner has and keeping the ones whose name (ignoring the owner/
+// prefix) matches pattern, per path.Match's glob syntax.
+func (d *orgRepoDiscoverer) listGlob(entry, token string) ([]string, error) {
+	owner, pattern, ok := strings.Cut(entry, "/")
+	if !ok {
+		return nil, fmt.Errorf("%q is not an owner/pattern glob", entry)
+	}
+
+	all, err := d.listOwnerRepos(owner, token)
+	if err != nil {
+		return nil, fmt.Errorf("list repos for %s: %w", owner, err)
+	}
+
+	var matched []string
+	for _, full := range all {
+		_, name, ok := strings.Cut(full, "/")
+		if !ok {
+			continue
+		}
+		if match, err := path.Match(pattern, name); err == nil && match {
+			matched = append(matched, full)
+		}
+	}
+	return matched, nil
+}
+
+// listOwnerRepos lists every repo an owner has, trying the org and
+// user list-repos endpoints in turn since a glob entry doesn't say
+// which kind of account it names.
+func (d *orgRepoDiscoverer) listOwnerRepos(owner, token string) ([]string, error) {
+	if repos, err := d.listPaged("orgs", owner, token); err == nil {
+		return repos, nil
+	}
+	return d.listPaged("users", owner, token)
+}
+
+// listPaged fetches every page of kind ("orgs" or "users") name's repos
+// from the GitHub API, skipping archived repos by default since a
+// discovered org/user tends to accumulate archived repos whose stale
+// code is noise in search results.
+func (d *orgRepoDiscoverer) listPaged(kind, name, token string) ([]string, error) {
+	const perPage = 100
+	var repos []string
+	for page := 1; ; page++ {
+		reqURL := fmt.Sprintf("%s/%s/%s/repos?per_page=%d&page=%d", d.baseURL, kind, name, perPage, page)
+		req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("build request for %s/%s: %w", kind, name, err)
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("User-Agent", userAgent)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := d.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("list %s/%s: %w", kind, name, err)
+		}
+		var listing []githubRepoListing
+		decodeErr := json.NewDecoder(resp.Body).Decode(&listing)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("list %s/%s: unexpected status %s", kind, name, resp.Status)
+		}
+		if decodeErr != nil {
+			return nil, fmt.Errorf("decode listing for %s/%s: %w", kind, name, decodeErr)
+		}
+
+		for _, r := range listing {
+			if r.Archived {
+				continue
+			}
+			repos = append(repos, r.FullName)
+		}
+		if len(listing) < perPage {
+			break
+		}
+	}
+	return repos, nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHasOrgDiscoveryPrefix(t *testing.T) {
+	cases := map[string]bool{
+		"org:mycompany":        true,
+		"user:bjatkin":         true,
+		"bjatkin/gitSearch":    false,
+		"gitlab:group/project": false,
+	}
+	for repo, want := range cases {
+		if got := hasOrgDiscoveryPrefix(repo); got != want {
+			t.Errorf("hasOrgDiscoveryPrefix(%q) = %v, want %v", repo, got, want)
+		}
+	}
+}
+
+func TestOrgRepoDiscovererExpandPassesThroughPlainRepos(t *testing.T) {
+	d := newOrgRepoDiscoverer()
+	got := d.Expand([]string{"owner/repo"})
+	if len(got) != 1 || got[0] != "owner/repo" {
+		t.Errorf("Expand = %v, want [owner/repo]", got)
+	}
+}
+
+func TestOrgRepoDiscovererExpandDropsUnresolvedEntries(t *testing.T) {
+	d := newOrgRepoDiscoverer()
+	got := d.Expand([]string{"org:mycompany", "owner/repo"})
+	if len(got) != 1 || got[0] != "owner/repo" {
+		t.Errorf("Expand = %v, want [owner/repo]", got)
+	}
+}
+
+func TestOrgRepoDiscovererRefreshThenExpand(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/mycompany/repos" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode([]githubRepoListing{})
+			return
+		}
+		json.NewEncoder(w).Encode([]githubRepoListing{
+			{FullName: "mycompany/one"},
+			{FullName: "mycompany/two"},
+		})
+	}))
+	defer srv.Close()
+
+	d := newOrgRepoDiscoverer()
+	d.baseURL = srv.URL
+	if err := d.Refresh([]string{"org:mycompany"}, "tok"); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	got := d.Expand([]string{"org:mycompany"})
+	want := []string{"mycompany/one", "mycompany/two"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Expand = %v, want %v", got, want)
+	}
+}
+
+func TestOrgRepoDiscovererRefreshSkipsArchivedRepos(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode([]githubRepoListing{})
+			return
+		}
+		json.NewEncoder(w).Encode([]githubRepoListing{
+			{FullName: "mycompany/active"},
+			{FullName: "mycompany/old", Archived: true},
+		})
+	}))
+	defer srv.Close()
+
+	d := newOrgRepoDiscoverer()
+	d.baseURL = srv.URL
+	if err := d.Refresh([]string{"org:mycompany"}, "tok"); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	got := d.Expand([]string{"org:mycompany"})
+	if len(got) != 1 || got[0] != "mycompany/active" {
+		t.Errorf("Expand = %v, want just the active repo", got)
+	}
+}
+
+func TestHasGlobPattern(t *testing.T) {
+	cases := map[string]bool{
+		"bjatkin/tool-*": true,
+		"bjatkin/tool-1": false,
+		"org:mycompany":  false,
+		"org:my-*":       false,
+	}
+	for repo, want := range cases {
+		if got := hasGlobPattern(repo); got != want {
+			t.Errorf("hasGlobPattern(%q) = %v, want %v", repo, got, want)
+		}
+	}
+}
+
+func TestOrgRepoDiscovererRefreshThenExpandGlob(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/bjatkin/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]githubRepoListing{
+			{FullName: "bjatkin/tool-a"},
+			{FullName: "bjatkin/tool-b"},
+			{FullName: "bjatkin/other"},
+		})
+	}))
+	defer srv.Close()
+
+	d := newOrgRepoDiscoverer()
+	d.baseURL = srv.URL
+	if err := d.Refresh([]string{"bjatkin/tool-*"}, ""); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	got := d.Expand([]string{"bjatkin/tool-*"})
+	want := []string{"bjatkin/tool-a", "bjatkin/tool-b"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Expand = %v, want %v", got, want)
+	}
+}
+
+func TestOrgRepoDiscovererListOwnerReposFallsBackToUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/orgs/bjatkin/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]githubRepoListing{{FullName: "bjatkin/tool-a"}})
+	}))
+	defer srv.Close()
+
+	d := newOrgRepoDiscoverer()
+	d.baseURL = srv.URL
+	if err := d.Refresh([]string{"bjatkin/tool-*"}, ""); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	got := d.Expand([]string{"bjatkin/tool-*"})
+	if len(got) != 1 || got[0] != "bjatkin/tool-a" {
+		t.Errorf("Expand = %v, want [bjatkin/tool-a]", got)
+	}
+}
+
+func TestOrgRepoDiscovererRefreshKeepsStaleListingOnError(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			json.NewEncoder(w).Encode([]githubRepoListing{{FullName: "mycompany/one"}})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	d := newOrgRepoDiscoverer()
+	d.baseURL = srv.URL
+	if err := d.Refresh([]string{"org:mycompany"}, ""); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if err := d.Refresh([]string{"org:mycompany"}, ""); err == nil {
+		t.Fatal("expected Refresh to report the second failure")
+	}
+
+	got := d.Expand([]string{"org:mycompany"})
+	if len(got) != 1 || got[0] != "mycompany/one" {
+		t.Errorf("Expand = %v, want stale [mycompany/one]", got)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ownershipCacheTTL is how long a computed owner list is kept before a
+// repeat lookup re-runs git shortlog, balancing freshness (new commits
+// shift ownership) against not re-walking history on every request.
+const ownershipCacheTTL = 1 * time.Hour
+
+// maxOwners caps how many top committers are returned per file, so a
+// file with hundreds of contributors doesn't dominate a response.
+const maxOwners = 5
+
+// Owner is one contributor's commit count against a file, most-commits
+// first.
+type Owner struct {
+	Name    string `json:"name"`
+	Commits int    `json:"commits"`
+}
+
+// ownershipCache holds computed owner lists in memory, keyed by repo and
+// path, since git shortlog walks the file's entire history.
+type ownershipCache struct {
+	mu      sync.Mutex
+	entries map[string]ownershipCacheEntry
+}
+
+type ownershipCacheEntry struct {
+	owners    []Owner
+	expiresAt time.Time
+}
+
+func newOwnershipCache() *ownershipCache {
+	return &ownershipCache{entries: map[string]ownershipCacheEntry{}}
+}
+
+func ownershipCacheKey(repo, path string) string {
+	return repo + "\x00" + path
+}
+
+func (c *ownershipCache) get(repo, path string) ([]Owner, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := ownershipCacheKey(repo, path)
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.owners, true
+}
+
+func (c *ownershipCache) put(repo, path string, owners []Owner) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[ownershipCacheKey(repo, path)] = ownershipCacheEntry{
+		owners:    owners,
+		expiresAt: time.Now().Add(ownershipCacheTTL),
+	}
+}
+
+// topOwners returns path's top committers in repo's local clone, ranked
+// by commit count via git shortlog, most first. It complements
+// CODEOWNERS-style data for repos that don't maintain that file.
+func (idx *localIndex) topOwners(repo, path string) ([]Owner, error) {
+	if owners, ok := idx.ownership.get(repo, path); ok {
+		return owners, nil
+	}
+
+	dir, err := idx.ensureClone(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := idx.sandbox.run("git", "-C", dir, "shortlog", "-sne", "HEAD", "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("shortlog %s %s: %w: %s", repo, path, err, out)
+	}
+
+	var owners []Owner
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(strings.TrimSpace(line), "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		count, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		owners = append(owners, Owner{Name: fields[1], Commits: count})
+		if len(owners) == maxOwners {
+			break
+		}
+	}
+
+	idx.ownership.put(repo, path, owners)
+	return owners, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestTopOwnersRanksByCommitCount(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+	dir := filepath.Join(dataDir, repo)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(env []string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	alice := []string{"GIT_AUTHOR_NAME=alice", "GIT_AUTHOR_EMAIL=alice@example.com", "GIT_COMMITTER_NAME=alice", "GIT_COMMITTER_EMAIL=alice@example.com"}
+	bob := []string{"GIT_AUTHOR_NAME=bob", "GIT_AUTHOR_EMAIL=bob@example.com", "GIT_COMMITTER_NAME=bob", "GIT_COMMITTER_EMAIL=bob@example.com"}
+
+	run(nil, "init", "-q")
+	run(nil, "config", "user.name", "init")
+	run(nil, "config", "user.email", "init@example.com")
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(alice, "add", "main.go")
+	run(alice, "commit", "-q", "-m", "alice: v1")
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("v2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(alice, "add", "main.go")
+	run(alice, "commit", "-q", "-m", "alice: v2")
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("v3"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(bob, "add", "main.go")
+	run(bob, "commit", "-q", "-m", "bob: v3")
+
+	idx := newLocalIndex(dataDir)
+	owners, err := idx.topOwners(repo, "main.go")
+	if err != nil {
+		t.Fatalf("topOwners: %v", err)
+	}
+	if len(owners) != 2 {
+		t.Fatalf("expected 2 owners, got %d: %+v", len(owners), owners)
+	}
+	if owners[0].Commits != 2 {
+		t.Errorf("expected the top owner to have 2 commits, got %d (%+v)", owners[0].Commits, owners[0])
+	}
+	if owners[1].Commits != 1 {
+		t.Errorf("expected the second owner to have 1 commit, got %d (%+v)", owners[1].Commits, owners[1])
+	}
+
+	// cached: a second call must return the same value without re-running git.
+	again, err := idx.topOwners(repo, "main.go")
+	if err != nil {
+		t.Fatalf("topOwners (cached): %v", err)
+	}
+	if len(again) != len(owners) {
+		t.Errorf("expected cached result to match, got %+v want %+v", again, owners)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+)
+
+// PolicyRule is a single pattern the policy checker scans local clones
+// for, e.g. a hardcoded secret shape.
+type PolicyRule struct {
+	ID          string
+	Description string
+	pattern     *regexp.Regexp
+}
+
+// defaultPolicyRules are the built-in secret-shape rules checked by
+// CheckPolicy. They favor recognizable prefixes over broad heuristics to
+// keep false positives low.
+var defaultPolicyRules = []PolicyRule{
+	{ID: "aws-access-key-id", Description: "AWS access key ID", pattern: regexp.MustCompile(`AKIA[0-9A-Z]{16}`)},
+	{ID: "generic-private-key", Description: "PEM-encoded private key", pattern: regexp.MustCompile(`-----BEGIN (RSA |EC |OPENSSH |)PRIVATE KEY-----`)},
+	{ID: "github-token", Description: "GitHub personal access token", pattern: regexp.MustCompile(`gh[pousr]_[0-9A-Za-z]{36}`)},
+	{ID: "slack-token", Description: "Slack API token", pattern: regexp.MustCompile(`xox[baprs]-[0-9A-Za-z-]{10,}`)},
+}
+
+// PolicyFinding is one policy rule match found in a file.
+type PolicyFinding struct {
+	RuleID      string `json:"rule_id"`
+	Description string `json:"description"`
+	Repo        string `json:"repo"`
+	Path        string `json:"path"`
+	Line        int    `json:"line"`
+}
+
+// checkPolicyContent scans data line-by-line against every rule,
+// reporting the (1-indexed) line each rule first or repeatedly matches.
+func checkPolicyContent(rules []PolicyRule, repo, path string, data []byte) []PolicyFinding {
+	var findings []PolicyFinding
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Bytes()
+		for _, rule := range rules {
+			if rule.pattern.Match(line) {
+				findings = append(findings, PolicyFinding{
+					RuleID:      rule.ID,
+					Description: rule.Description,
+					Repo:        repo,
+					Path:        path,
+					Line:        lineNum,
+				})
+			}
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// profileRoute pairs one profile's routes with how requests are matched
+// to it.
+type profileRoute struct {
+	name       string
+	host       string
+	pathPrefix string
+	handler    http.Handler
+}
+
+// profileRouter dispatches requests to one of several isolated search
+// profiles (each with its own repos, tokens, and caches) running in the
+// same process, matched by request Host or by path prefix.
+type profileRouter struct {
+	routes []profileRoute
+}
+
+// newProfileRouter builds a *server (and its own event loop, job queue,
+// etc.) for each profile config and wraps its routes for host/prefix
+// dispatch. Every profile must set at least one of Host or PathPrefix,
+// so a request always has an unambiguous home.
+func newProfileRouter(profiles []Config) (*profileRouter, error) {
+	pr := &profileRouter{}
+	for _, cfg := range profiles {
+		if cfg.Host == "" && cfg.PathPrefix == "" {
+			return nil, fmt.Errorf("profile %q: must set host or path_prefix", cfg.Name)
+		}
+
+		srv, err := newServer(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", cfg.Name, err)
+		}
+
+		handler := srv.routes()
+		if cfg.PathPrefix != "" {
+			handler = http.StripPrefix(strings.TrimSuffix(cfg.PathPrefix, "/"), handler)
+		}
+
+		pr.routes = append(pr.routes, profileRoute{
+			name:       cfg.Name,
+			host:       cfg.Host,
+			pathPrefix: cfg.PathPrefix,
+			handler:    handler,
+		})
+	}
+	return pr, nil
+}
+
+// ServeHTTP matches a request's Host header first, then the longest
+// matching path prefix, so a profile with both set can be reached
+// either way.
+func (pr *profileRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, route := range pr.routes {
+		if route.host != "" && route.host == r.Host {
+			route.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	var best *profileRoute
+	for i, route := range pr.routes {
+		if route.pathPrefix == "" || !strings.HasPrefix(r.URL.Path, route.pathPrefix) {
+			continue
+		}
+		if best == nil || len(route.pathPrefix) > len(best.pathPrefix) {
+			best = &pr.routes[i]
+		}
+	}
+	if best != nil {
+		best.handler.ServeHTTP(w, r)
+		return
+	}
+
+	http.NotFound(w, r)
+}
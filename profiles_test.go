@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testProfileConfig(name, host, pathPrefix string) Config {
+	cfg := Config{Name: name, Host: host, PathPrefix: pathPrefix, Repos: []string{"o/r"}}
+	applyConfigDefaults(&cfg)
+	return cfg
+}
+
+func TestNewProfileRouterRejectsUnroutableProfile(t *testing.T) {
+	_, err := newProfileRouter([]Config{testProfileConfig("no-route", "", "")})
+	if err == nil {
+		t.Fatal("expected error for a profile with no host or path_prefix")
+	}
+}
+
+func TestProfileRouterMatchesHost(t *testing.T) {
+	pr, err := newProfileRouter([]Config{
+		testProfileConfig("staging", "staging.example.com", ""),
+		testProfileConfig("prod", "prod.example.com", ""),
+	})
+	if err != nil {
+		t.Fatalf("newProfileRouter: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "http://staging.example.com/search?q=foo", nil)
+	req.Host = "staging.example.com"
+	w := httptest.NewRecorder()
+	pr.ServeHTTP(w, req)
+
+	if w.Code == http.StatusNotFound {
+		t.Errorf("expected request to be routed, got 404")
+	}
+}
+
+func TestProfileRouterMatchesLongestPathPrefix(t *testing.T) {
+	pr, err := newProfileRouter([]Config{
+		testProfileConfig("all", "", "/v1"),
+		testProfileConfig("staging", "", "/v1/staging"),
+	})
+	if err != nil {
+		t.Fatalf("newProfileRouter: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/v1/staging/search?q=foo", nil)
+	w := httptest.NewRecorder()
+	pr.ServeHTTP(w, req)
+
+	if w.Code == http.StatusNotFound {
+		t.Errorf("expected request under /v1/staging to be routed, got 404")
+	}
+}
+
+func TestProfileRouterUnmatchedReturns404(t *testing.T) {
+	pr, err := newProfileRouter([]Config{
+		testProfileConfig("staging", "", "/v1/staging"),
+	})
+	if err != nil {
+		t.Fatalf("newProfileRouter: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/unknown/search?q=foo", nil)
+	w := httptest.NewRecorder()
+	pr.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unmatched request, got %d", w.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// publicMaxAge is how long a CDN may cache a /public/search response.
+// The URL (query + path) fully determines the response, so it's safe to
+// treat as immutable for this long; a repo whose index changes sooner
+// is purged individually via its Surrogate-Key.
+const publicMaxAge = 24 * 60 * 60 // seconds
+
+// writePublicCacheHeaders marks w as long-lived and publicly cacheable,
+// and tags it with one Surrogate-Key per repo in results so a CDN
+// (Fastly, etc.) can purge just the affected entries when a repo's
+// index is rebuilt, without invalidating the whole cache.
+func writePublicCacheHeaders(w http.ResponseWriter, repos []string) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", publicMaxAge))
+
+	keys := make([]string, 0, len(repos))
+	seen := map[string]bool{}
+	for _, repo := range repos {
+		key := "repo:" + repo
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	if len(keys) > 0 {
+		w.Header().Set("Surrogate-Key", strings.Join(keys, " "))
+	}
+}
+
+// publicSearchResponse is the body of GET /public/search: a read-only,
+// unpaginated view with no request ID or other server-side state, since
+// the URL itself is the cache key.
+type publicSearchResponse struct {
+	Results []Result `json:"results"`
+	Facets  Facets   `json:"facets"`
+}
+
+func resultRepos(results []Result) []string {
+	repos := make([]string, len(results))
+	for i, r := range results {
+		repos[i] = r.Repo
+	}
+	return repos
+}
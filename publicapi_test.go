@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWritePublicCacheHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	writePublicCacheHeaders(w, []string{"o/r1", "o/r2", "o/r1"})
+
+	cc := w.Header().Get("Cache-Control")
+	if cc != "public, max-age=86400, immutable" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+
+	key := w.Header().Get("Surrogate-Key")
+	if key != "repo:o/r1 repo:o/r2" {
+		t.Errorf("Surrogate-Key = %q, want deduped repo keys", key)
+	}
+}
+
+func TestWritePublicCacheHeadersNoResults(t *testing.T) {
+	w := httptest.NewRecorder()
+	writePublicCacheHeaders(w, nil)
+
+	if w.Header().Get("Surrogate-Key") != "" {
+		t.Error("expected no Surrogate-Key header when there are no results")
+	}
+}
+
+func TestResultRepos(t *testing.T) {
+	results := []Result{{Repo: "o/a"}, {Repo: "o/b"}}
+	repos := resultRepos(results)
+	if len(repos) != 2 || repos[0] != "o/a" || repos[1] != "o/b" {
+		t.Errorf("resultRepos = %v", repos)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// compiledPathPenalty is a PathPenalty with its glob pattern precompiled
+// into a regexp, so ranking doesn't re-parse the pattern for every
+// result on every search.
+type compiledPathPenalty struct {
+	pattern string
+	re      *regexp.Regexp
+	penalty float64
+}
+
+// rankConfig is the ranking configuration compiled once at load time
+// instead of being re-interpreted per result.
+type rankConfig struct {
+	recencyHalfLife time.Duration
+	pathPenalties   []compiledPathPenalty
+}
+
+// compileRankConfig validates and precompiles the ranking-related
+// fields of cfg, returning a clear error at startup if any rule is
+// invalid rather than failing silently mid-search.
+func compileRankConfig(cfg Config) (rankConfig, error) {
+	var rc rankConfig
+
+	if cfg.RecencyHalfLife != "" {
+		halfLife, err := time.ParseDuration(cfg.RecencyHalfLife)
+		if err != nil {
+			return rankConfig{}, fmt.Errorf("invalid recency_half_life %q: %w", cfg.RecencyHalfLife, err)
+		}
+		rc.recencyHalfLife = halfLife
+	}
+
+	for _, rule := range cfg.PathPenalties {
+		re, err := globToRegexp(rule.Pattern)
+		if err != nil {
+			return rankConfig{}, fmt.Errorf("invalid path_penalties pattern %q: %w", rule.Pattern, err)
+		}
+		rc.pathPenalties = append(rc.pathPenalties, compiledPathPenalty{
+			pattern: rule.Pattern,
+			re:      re,
+			penalty: rule.Penalty,
+		})
+	}
+
+	return rc, nil
+}
+
+// globToRegexp compiles a filepath.Match-style glob (supporting * and ?)
+// into an anchored regexp matched against a single path segment or the
+// full path.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
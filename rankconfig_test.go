@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestGlobToRegexp(t *testing.T) {
+	re, err := globToRegexp("*_generated.go")
+	if err != nil {
+		t.Fatalf("globToRegexp: %v", err)
+	}
+	if !re.MatchString("foo_generated.go") {
+		t.Errorf("expected match for foo_generated.go")
+	}
+	if re.MatchString("foo_generated.go.bak") {
+		t.Errorf("expected no match for foo_generated.go.bak")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Explain reports how a result's rank score was computed, returned when
+// a search is run with explain=1.
+type Explain struct {
+	Score     float64  `json:"score"`
+	Penalties []string `json:"penalties,omitempty"`
+}
+
+// recencyScore returns a 0-1 multiplier for repo that decays by half
+// every halfLife of age since it was last pushed to. GitHub's code
+// search API doesn't expose a per-file last-commit time, so the repo's
+// pushed_at is used as an approximation. A zero halfLife disables the
+// boost (multiplier of 1 for every repo).
+//
+// Only appendSearchResults (fed by the default GitHub client) actually
+// populates pushedAt; the other backends (GitLab, Bitbucket, Gitea, the
+// local index) have no equivalent field wired up yet, so a repo missing
+// from pushedAt gets no boost or penalty at all (multiplier 1) rather
+// than being scored as though it were pushed at the zero time - which
+// would make it ~2000 years stale and decay to a 0 score, sorting every
+// non-GitHub result to the bottom whenever recency is enabled.
+func recencyScore(repo string, pushedAt map[string]time.Time, halfLife time.Duration) float64 {
+	if halfLife <= 0 {
+		return 1
+	}
+
+	pushed, ok := pushedAt[repo]
+	if !ok || pushed.IsZero() {
+		return 1
+	}
+
+	age := time.Since(pushed)
+	if age < 0 {
+		age = 0
+	}
+	return math.Exp(-math.Ln2 * age.Seconds() / halfLife.Seconds())
+}
+
+// pathPenalty returns the combined multiplier and the patterns that
+// matched, for every compiled path penalty rule that applies to path
+// either as a whole or as one of its segments (so "vendor" matches
+// "vendor/foo.go", not just files named literally "vendor").
+func pathPenalty(path string, rules []compiledPathPenalty) (float64, []string) {
+	score := 1.0
+	var reasons []string
+	segments := strings.Split(path, "/")
+
+	for _, rule := range rules {
+		matched := rule.re.MatchString(path)
+		if !matched {
+			for _, seg := range segments {
+				if rule.re.MatchString(seg) {
+					matched = true
+					break
+				}
+			}
+		}
+		if matched {
+			score *= rule.penalty
+			reasons = append(reasons, rule.pattern)
+		}
+	}
+
+	return score, reasons
+}
+
+// rankResults scores and sorts results by recency and path penalty
+// rules, attaching an Explain to each result when explain is true.
+func rankResults(results []Result, pushedAt map[string]time.Time, rc rankConfig, explain bool) []Result {
+	type scored struct {
+		result Result
+		score  float64
+	}
+
+	all := make([]scored, len(results))
+	for i, r := range results {
+		score := recencyScore(r.Repo, pushedAt, rc.recencyHalfLife)
+		penaltyScore, reasons := pathPenalty(r.Path, rc.pathPenalties)
+		score *= penaltyScore
+
+		if explain {
+			r.Explain = &Explain{Score: score, Penalties: reasons}
+		}
+		all[i] = scored{result: r, score: score}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].score > all[j].score
+	})
+
+	ranked := make([]Result, len(all))
+	for i, s := range all {
+		ranked[i] = s.result
+	}
+	return ranked
+}
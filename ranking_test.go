@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRankResultsRecency(t *testing.T) {
+	now := time.Now()
+	results := []Result{
+		{Repo: "old/repo", Path: "a.go"},
+		{Repo: "new/repo", Path: "b.go"},
+	}
+	pushedAt := map[string]time.Time{
+		"old/repo": now.Add(-365 * 24 * time.Hour),
+		"new/repo": now,
+	}
+	rc, err := compileRankConfig(Config{RecencyHalfLife: "168h"})
+	if err != nil {
+		t.Fatalf("compileRankConfig: %v", err)
+	}
+
+	ranked := rankResults(results, pushedAt, rc, false)
+	if ranked[0].Repo != "new/repo" {
+		t.Errorf("expected new/repo first, got %s", ranked[0].Repo)
+	}
+}
+
+func TestRankResultsRecencyMissingPushedAt(t *testing.T) {
+	now := time.Now()
+	results := []Result{
+		{Repo: "github/repo", Path: "a.go"},
+		{Repo: "gitlab/repo", Path: "b.go"},
+	}
+	pushedAt := map[string]time.Time{
+		"github/repo": now.Add(-365 * 24 * time.Hour),
+	}
+	rc, err := compileRankConfig(Config{RecencyHalfLife: "168h"})
+	if err != nil {
+		t.Fatalf("compileRankConfig: %v", err)
+	}
+
+	ranked := rankResults(results, pushedAt, rc, false)
+	if ranked[0].Repo != "gitlab/repo" {
+		t.Errorf("expected gitlab/repo (no pushedAt entry, no boost) to rank above a stale github/repo, got %s first", ranked[0].Repo)
+	}
+}
+
+func TestRankResultsPathPenalty(t *testing.T) {
+	results := []Result{
+		{Repo: "a", Path: "vendor/lib.go"},
+		{Repo: "a", Path: "main.go"},
+	}
+	rc, err := compileRankConfig(Config{PathPenalties: []PathPenalty{{Pattern: "vendor", Penalty: 0.1}}})
+	if err != nil {
+		t.Fatalf("compileRankConfig: %v", err)
+	}
+
+	ranked := rankResults(results, nil, rc, true)
+	if ranked[0].Path != "main.go" {
+		t.Errorf("expected main.go first, got %s", ranked[0].Path)
+	}
+	if ranked[1].Explain == nil || len(ranked[1].Explain.Penalties) != 1 {
+		t.Errorf("expected vendor/lib.go to explain its penalty, got %+v", ranked[1].Explain)
+	}
+}
+
+func TestRankResultsNoop(t *testing.T) {
+	results := []Result{{Repo: "a"}, {Repo: "b"}}
+	rc, err := compileRankConfig(Config{})
+	if err != nil {
+		t.Fatalf("compileRankConfig: %v", err)
+	}
+
+	ranked := rankResults(results, nil, rc, false)
+	if ranked[0].Repo != "a" || ranked[1].Repo != "b" {
+		t.Errorf("expected order unchanged with no rules, got %+v", ranked)
+	}
+}
+
+func TestCompileRankConfigInvalidPattern(t *testing.T) {
+	_, err := compileRankConfig(Config{RecencyHalfLife: "not-a-duration"})
+	if err == nil {
+		t.Fatal("expected error for invalid recency_half_life")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	reported chan any
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{reported: make(chan any, 1)}
+}
+
+func (s *recordingSink) Report(rec any, stack []byte, r *http.Request) {
+	s.reported <- rec
+}
+
+func TestRecoveryMiddlewareConvertsPanicTo500(t *testing.T) {
+	handler := recoveryMiddleware(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response body isn't JSON: %v (%s)", err, w.Body.String())
+	}
+	if body["error"] == "" {
+		t.Error("expected a non-empty error field")
+	}
+}
+
+func TestRecoveryMiddlewarePassesThroughWithoutPanic(t *testing.T) {
+	handler := recoveryMiddleware(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRecoveryMiddlewareReportsToSink(t *testing.T) {
+	sink := newRecordingSink()
+	handler := recoveryMiddleware(sink, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	select {
+	case rec := <-sink.reported:
+		if rec != "boom" {
+			t.Errorf("reported value = %v, want boom", rec)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the sink to be reported to")
+	}
+}
+
+func TestParseSentryDSN(t *testing.T) {
+	dsn, err := parseSentryDSN("https://public@o1.ingest.sentry.io/12345")
+	if err != nil {
+		t.Fatalf("parseSentryDSN: %v", err)
+	}
+	if dsn.publicKey != "public" {
+		t.Errorf("publicKey = %q, want public", dsn.publicKey)
+	}
+	if want := "https://o1.ingest.sentry.io/api/12345/store/"; dsn.storeURL != want {
+		t.Errorf("storeURL = %q, want %q", dsn.storeURL, want)
+	}
+}
+
+func TestParseSentryDSNRejectsMissingProjectID(t *testing.T) {
+	if _, err := parseSentryDSN("https://public@o1.ingest.sentry.io/"); err == nil {
+		t.Error("expected an error for a DSN missing a project id")
+	}
+}
+
+func TestParseSentryDSNRejectsMissingPublicKey(t *testing.T) {
+	if _, err := parseSentryDSN("https://o1.ingest.sentry.io/12345"); err == nil {
+		t.Error("expected an error for a DSN missing a public key")
+	}
+}
+
+func TestNewErrorReportSinkNilWhenUnconfigured(t *testing.T) {
+	sink, err := newErrorReportSink(Config{})
+	if err != nil {
+		t.Fatalf("newErrorReportSink: %v", err)
+	}
+	if sink != nil {
+		t.Errorf("expected a nil sink with no sentry_dsn configured, got %v", sink)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldForMatch normalizes s to NFC and lowercases it, so accented
+// identifiers and CJK text typed with a different (but canonically
+// equivalent) Unicode form still match.
+func foldForMatch(s string) string {
+	return strings.ToLower(norm.NFC.String(s))
+}
+
+// refineResults filters a cached result set down to results that still
+// have at least one line containing term, without re-querying any
+// backend. Matching is a case-insensitive, Unicode-normalized substring
+// match, mirroring a simple additional AND term on top of the original
+// query.
+func refineResults(results []Result, term string) []Result {
+	needle := foldForMatch(term)
+
+	var filtered []Result
+	for _, result := range results {
+		var lines []Line
+		for _, line := range result.Lines {
+			if strings.Contains(foldForMatch(line.Text), needle) {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			refined := result
+			refined.Lines = lines
+			filtered = append(filtered, refined)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestRefineResults(t *testing.T) {
+	results := []Result{
+		{Path: "a.go", Lines: []Line{{Text: "func Foo() {}"}, {Text: "func Bar() {}"}}},
+		{Path: "b.go", Lines: []Line{{Text: "func Baz() {}"}}},
+	}
+
+	refined := refineResults(results, "bar")
+	if len(refined) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(refined))
+	}
+	if refined[0].Path != "a.go" {
+		t.Errorf("Path = %q, want a.go", refined[0].Path)
+	}
+	if len(refined[0].Lines) != 1 || refined[0].Lines[0].Text != "func Bar() {}" {
+		t.Errorf("unexpected lines: %+v", refined[0].Lines)
+	}
+}
+
+func TestRefineResultsUnicodeNormalization(t *testing.T) {
+	nfd := "café note" // "e" + combining acute accent (NFD)
+	nfc := "café"       // precomposed "e with acute" (NFC)
+	results := []Result{
+		{Path: "a.go", Lines: []Line{{Text: nfd}}},
+	}
+
+	refined := refineResults(results, nfc)
+	if len(refined) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(refined))
+	}
+}
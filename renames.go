@@ -0,0 +1,76 @@
+package main
+
+import "sync"
+
+// RepoRename records a repo rename or ownership transfer detected from
+// GitHub search results: the repo was queried as OldRepo, but the API
+// reported the matching file living under NewRepo.
+type RepoRename struct {
+	OldRepo string `json:"old_repo"`
+	NewRepo string `json:"new_repo"`
+}
+
+// renameTracker records detected repo renames so operators can see them
+// (via /admin/events) instead of searches silently returning fewer
+// results as a configured repo name goes stale, and so later requests
+// for the old name keep resolving to the repo's current location.
+type renameTracker struct {
+	mu      sync.Mutex
+	overlay map[string]string
+	history []RepoRename
+}
+
+func newRenameTracker() *renameTracker {
+	return &renameTracker{overlay: map[string]string{}}
+}
+
+// Record notes that oldRepo now lives at newRepo. It returns true the
+// first time this exact rename is recorded, and false on a repeat, so
+// callers can avoid re-emitting an event for a rename they've already
+// reported.
+func (t *renameTracker) Record(oldRepo, newRepo string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.overlay[oldRepo] == newRepo {
+		return false
+	}
+	t.overlay[oldRepo] = newRepo
+	t.history = append(t.history, RepoRename{OldRepo: oldRepo, NewRepo: newRepo})
+	return true
+}
+
+// Resolve follows any recorded rename chain for repo, returning its
+// current name (or repo unchanged if no rename was ever recorded).
+func (t *renameTracker) Resolve(repo string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := map[string]bool{}
+	for {
+		next, ok := t.overlay[repo]
+		if !ok || seen[next] {
+			return repo
+		}
+		seen[next] = true
+		repo = next
+	}
+}
+
+// Import restores rename history and overlay entries from a state
+// export bundle.
+func (t *renameTracker) Import(renames []RepoRename) {
+	for _, rename := range renames {
+		t.Record(rename.OldRepo, rename.NewRepo)
+	}
+}
+
+// List returns every rename recorded so far, oldest first.
+func (t *renameTracker) List() []RepoRename {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]RepoRename, len(t.history))
+	copy(out, t.history)
+	return out
+}
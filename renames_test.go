@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestRenameTrackerRecordAndResolve(t *testing.T) {
+	tr := newRenameTracker()
+
+	if !tr.Record("bjatkin/old-name", "bjatkin/new-name") {
+		t.Fatal("expected first Record of a rename to return true")
+	}
+	if tr.Record("bjatkin/old-name", "bjatkin/new-name") {
+		t.Error("expected repeat Record of the same rename to return false")
+	}
+
+	if got := tr.Resolve("bjatkin/old-name"); got != "bjatkin/new-name" {
+		t.Errorf("Resolve(bjatkin/old-name) = %q, want bjatkin/new-name", got)
+	}
+	if got := tr.Resolve("bjatkin/untouched"); got != "bjatkin/untouched" {
+		t.Errorf("Resolve(bjatkin/untouched) = %q, want input unchanged", got)
+	}
+}
+
+func TestRenameTrackerResolveFollowsChain(t *testing.T) {
+	tr := newRenameTracker()
+	tr.Record("bjatkin/a", "bjatkin/b")
+	tr.Record("bjatkin/b", "bjatkin/c")
+
+	if got := tr.Resolve("bjatkin/a"); got != "bjatkin/c" {
+		t.Errorf("Resolve(bjatkin/a) = %q, want bjatkin/c", got)
+	}
+}
+
+func TestRenameTrackerList(t *testing.T) {
+	tr := newRenameTracker()
+	tr.Record("bjatkin/a", "bjatkin/b")
+
+	renames := tr.List()
+	if len(renames) != 1 || renames[0] != (RepoRename{OldRepo: "bjatkin/a", NewRepo: "bjatkin/b"}) {
+		t.Errorf("List() = %+v, want a single bjatkin/a -> bjatkin/b rename", renames)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import "fmt"
+
+// RepoAlias assigns a short, stable alias to a configured repo, so
+// client integrations (query params, saved links, dashboards) can refer
+// to a repo by a name that survives the repo itself being renamed.
+type RepoAlias struct {
+	Alias string `yaml:"alias"`
+	Repo  string `yaml:"repo"`
+}
+
+// repoAliasResolver maps between configured repo aliases and their full
+// repo names.
+type repoAliasResolver struct {
+	toRepo  map[string]string
+	toAlias map[string]string
+}
+
+// newRepoAliasResolver validates aliases and builds a repoAliasResolver.
+// Each alias and each repo may appear at most once, so lookups in either
+// direction are unambiguous.
+func newRepoAliasResolver(aliases []RepoAlias) (*repoAliasResolver, error) {
+	r := &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}}
+	for _, a := range aliases {
+		if _, ok := r.toRepo[a.Alias]; ok {
+			return nil, fmt.Errorf("repo_aliases: alias %q is already assigned", a.Alias)
+		}
+		if _, ok := r.toAlias[a.Repo]; ok {
+			return nil, fmt.Errorf("repo_aliases: repo %q already has an alias", a.Repo)
+		}
+		r.toRepo[a.Alias] = a.Repo
+		r.toAlias[a.Repo] = a.Alias
+	}
+	return r, nil
+}
+
+// ResolveRepo returns the repo a query param or path segment refers to:
+// input itself if it names a repo directly, or the repo an alias points
+// at if input is a known alias.
+func (r *repoAliasResolver) ResolveRepo(input string) string {
+	if repo, ok := r.toRepo[input]; ok {
+		return repo
+	}
+	return input
+}
+
+// AliasFor returns the alias assigned to repo, if any.
+func (r *repoAliasResolver) AliasFor(repo string) (string, bool) {
+	alias, ok := r.toAlias[repo]
+	return alias, ok
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestRepoAliasResolverResolvesAliasAndPassesThroughUnknown(t *testing.T) {
+	r, err := newRepoAliasResolver([]RepoAlias{{Alias: "payments", Repo: "org/payments-service"}})
+	if err != nil {
+		t.Fatalf("newRepoAliasResolver: %v", err)
+	}
+
+	if got := r.ResolveRepo("payments"); got != "org/payments-service" {
+		t.Errorf("ResolveRepo(payments) = %q, want org/payments-service", got)
+	}
+	if got := r.ResolveRepo("org/other-service"); got != "org/other-service" {
+		t.Errorf("ResolveRepo(org/other-service) = %q, want input unchanged", got)
+	}
+
+	alias, ok := r.AliasFor("org/payments-service")
+	if !ok || alias != "payments" {
+		t.Errorf("AliasFor(org/payments-service) = %q, %v, want payments, true", alias, ok)
+	}
+	if _, ok := r.AliasFor("org/other-service"); ok {
+		t.Error("expected no alias for an unaliased repo")
+	}
+}
+
+func TestNewRepoAliasResolverRejectsDuplicateAlias(t *testing.T) {
+	_, err := newRepoAliasResolver([]RepoAlias{
+		{Alias: "payments", Repo: "org/payments-service"},
+		{Alias: "payments", Repo: "org/other-service"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a duplicate alias")
+	}
+}
+
+func TestNewRepoAliasResolverRejectsDuplicateRepo(t *testing.T) {
+	_, err := newRepoAliasResolver([]RepoAlias{
+		{Alias: "payments", Repo: "org/payments-service"},
+		{Alias: "pay", Repo: "org/payments-service"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a repo with two aliases")
+	}
+}
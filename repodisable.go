@@ -0,0 +1,91 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// DisabledRepo describes a repo currently hidden from search results.
+type DisabledRepo struct {
+	Repo   string    `json:"repo"`
+	Reason string    `json:"reason,omitempty"`
+	Until  time.Time `json:"until,omitempty"`
+}
+
+// repoDisableStore tracks repos temporarily hidden from search results
+// (their local index is left untouched) via the admin API, so a repo
+// implicated in an incident can be pulled from results immediately
+// without a config change or restart, and optionally re-enabled
+// automatically after Until passes.
+type repoDisableStore struct {
+	mu       sync.Mutex
+	disabled map[string]DisabledRepo
+}
+
+func newRepoDisableStore() *repoDisableStore {
+	return &repoDisableStore{disabled: map[string]DisabledRepo{}}
+}
+
+// Disable hides repo from search results. A zero until disables it
+// indefinitely, until an explicit Enable call.
+func (s *repoDisableStore) Disable(repo, reason string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disabled[repo] = DisabledRepo{Repo: repo, Reason: reason, Until: until}
+}
+
+// Enable removes any disable entry for repo, returning true if one was
+// removed.
+func (s *repoDisableStore) Enable(repo string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.disabled[repo]; !ok {
+		return false
+	}
+	delete(s.disabled, repo)
+	return true
+}
+
+// IsDisabled reports whether repo is currently hidden from results. A
+// disable entry whose Until has passed is dropped and treated as
+// enabled, so a scheduled re-enable takes effect on the next search
+// without a background sweep.
+func (s *repoDisableStore) IsDisabled(repo string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.disabled[repo]
+	if !ok {
+		return false
+	}
+	if !entry.Until.IsZero() && !entry.Until.After(time.Now()) {
+		delete(s.disabled, repo)
+		return false
+	}
+	return true
+}
+
+// Import restores disable entries from a state export bundle.
+func (s *repoDisableStore) Import(entries []DisabledRepo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range entries {
+		s.disabled[entry.Repo] = entry
+	}
+}
+
+// List returns every repo currently disabled.
+func (s *repoDisableStore) List() []DisabledRepo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]DisabledRepo, 0, len(s.disabled))
+	for repo, entry := range s.disabled {
+		if !entry.Until.IsZero() && !entry.Until.After(time.Now()) {
+			delete(s.disabled, repo)
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRepoDisableStoreDisableEnable(t *testing.T) {
+	s := newRepoDisableStore()
+
+	if s.IsDisabled("bjatkin/golf-engine") {
+		t.Fatal("expected repo to start enabled")
+	}
+
+	s.Disable("bjatkin/golf-engine", "incident-123", time.Time{})
+	if !s.IsDisabled("bjatkin/golf-engine") {
+		t.Error("expected repo to be disabled")
+	}
+
+	if !s.Enable("bjatkin/golf-engine") {
+		t.Error("expected Enable to report a removed entry")
+	}
+	if s.IsDisabled("bjatkin/golf-engine") {
+		t.Error("expected repo to be enabled again")
+	}
+	if s.Enable("bjatkin/golf-engine") {
+		t.Error("expected repeat Enable to report nothing removed")
+	}
+}
+
+func TestRepoDisableStoreAutoReenablesAfterUntil(t *testing.T) {
+	s := newRepoDisableStore()
+	s.Disable("bjatkin/golf-engine", "scheduled maintenance", time.Now().Add(-time.Minute))
+
+	if s.IsDisabled("bjatkin/golf-engine") {
+		t.Error("expected a repo whose until has passed to read as enabled")
+	}
+}
+
+func TestRepoDisableStoreList(t *testing.T) {
+	s := newRepoDisableStore()
+	s.Disable("bjatkin/golf-engine", "incident-123", time.Time{})
+
+	list := s.List()
+	if len(list) != 1 || list[0].Repo != "bjatkin/golf-engine" || list[0].Reason != "incident-123" {
+		t.Errorf("List() = %+v, want a single bjatkin/golf-engine entry", list)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoImportResult reports what a bulk repo import did, so a caller can
+// tell an operator which rows were actually new.
+type RepoImportResult struct {
+	Added   []string `json:"added"`
+	Skipped []string `json:"skipped"`
+}
+
+// ParseRepoImport extracts repo names from a CSV or JSON export, such as
+// an asset inventory system's dump. format is "csv" or "json".
+//
+// CSV input must have a header row with a "repo" column (case
+// insensitive); other columns are ignored. JSON input may be either a
+// flat array of repo name strings, or an array of objects each with a
+// "repo" field.
+func ParseRepoImport(data []byte, format string) ([]string, error) {
+	switch format {
+	case "csv":
+		return parseRepoImportCSV(data)
+	case "json":
+		return parseRepoImportJSON(data)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q: must be csv or json", format)
+	}
+}
+
+func parseRepoImportCSV(data []byte) ([]string, error) {
+	r := csv.NewReader(strings.NewReader(string(data)))
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	col := -1
+	for i, header := range rows[0] {
+		if strings.EqualFold(strings.TrimSpace(header), "repo") {
+			col = i
+			break
+		}
+	}
+	if col == -1 {
+		return nil, fmt.Errorf("csv import must have a %q header column", "repo")
+	}
+
+	var repos []string
+	for _, row := range rows[1:] {
+		if col >= len(row) {
+			continue
+		}
+		if repo := strings.TrimSpace(row[col]); repo != "" {
+			repos = append(repos, repo)
+		}
+	}
+	return repos, nil
+}
+
+func parseRepoImportJSON(data []byte) ([]string, error) {
+	var flat []string
+	if err := json.Unmarshal(data, &flat); err == nil {
+		return flat, nil
+	}
+
+	var rows []struct {
+		Repo string `json:"repo"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("parse json: must be an array of repo name strings or {\"repo\": ...} objects: %w", err)
+	}
+
+	repos := make([]string, len(rows))
+	for i, row := range rows {
+		repos[i] = row.Repo
+	}
+	return repos, nil
+}
+
+// repoImportStore holds repos merged in via bulk import, on top of
+// whatever the config file lists, so they survive a config file reload
+// (unlike the file's own Repos, which is replaced wholesale on reload).
+type repoImportStore struct {
+	mu    sync.Mutex
+	repos map[string]bool
+}
+
+func newRepoImportStore() *repoImportStore {
+	return &repoImportStore{repos: map[string]bool{}}
+}
+
+// Merge validates and adds repos not already known (from either an
+// earlier import or existing, so a repo the config file already lists
+// isn't reported as newly added).
+func (s *repoImportStore) Merge(repos []string, existing []string) (RepoImportResult, error) {
+	known := make(map[string]bool, len(existing))
+	for _, repo := range existing {
+		known[repo] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result RepoImportResult
+	for _, repo := range repos {
+		if err := validateRepoName(repo); err != nil {
+			return RepoImportResult{}, err
+		}
+		if known[repo] || s.repos[repo] {
+			result.Skipped = append(result.Skipped, repo)
+			continue
+		}
+		s.repos[repo] = true
+		result.Added = append(result.Added, repo)
+	}
+	return result, nil
+}
+
+// Import restores repos from a state export bundle, without the
+// existing/already-known distinction Merge reports for a fresh import.
+func (s *repoImportStore) Import(repos []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, repo := range repos {
+		s.repos[repo] = true
+	}
+}
+
+// List returns every repo merged in via import, in no particular order.
+func (s *repoImportStore) List() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repos := make([]string, 0, len(s.repos))
+	for repo := range s.repos {
+		repos = append(repos, repo)
+	}
+	return repos
+}
+
+// MergeRepoImportIntoConfigFile is the "git_search import-repos" CLI
+// command's core: it parses importData, validates and merges the repos
+// it names into path's repos list, and writes them back to the config
+// file itself (unlike the /admin/repos/import endpoint, which merges
+// into a running process's in-memory config) so the import survives a
+// restart.
+func MergeRepoImportIntoConfigFile(path, format string, importData []byte) (RepoImportResult, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return RepoImportResult{}, err
+	}
+
+	repos, err := ParseRepoImport(importData, format)
+	if err != nil {
+		return RepoImportResult{}, err
+	}
+
+	result, err := newRepoImportStore().Merge(repos, cfg.Repos)
+	if err != nil {
+		return RepoImportResult{}, err
+	}
+
+	if len(result.Added) == 0 {
+		return result, nil
+	}
+
+	if err := appendReposToConfigFile(path, result.Added); err != nil {
+		return RepoImportResult{}, err
+	}
+	return result, nil
+}
+
+// appendReposToConfigFile adds repos to the repos: list of the config
+// file at path, editing it as a yaml.Node tree rather than
+// unmarshal-modify-marshal through Config, so comments and formatting
+// elsewhere in the file are left untouched.
+func appendReposToConfigFile(path string, repos []string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("config file has no content")
+	}
+
+	root := doc.Content[0]
+	var seq *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "repos" {
+			seq = root.Content[i+1]
+			break
+		}
+	}
+	if seq == nil {
+		seq = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "repos"}, seq)
+	}
+
+	for _, repo := range repos {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: repo})
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("encode config file: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+	return nil
+}
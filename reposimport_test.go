@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseRepoImportCSV(t *testing.T) {
+	data := []byte("name,repo,owner_team\nfoo,bjatkin/golf-engine,platform\nbar,bjatkin/checkSUM,security\n")
+	repos, err := ParseRepoImport(data, "csv")
+	if err != nil {
+		t.Fatalf("ParseRepoImport: %v", err)
+	}
+	want := []string{"bjatkin/golf-engine", "bjatkin/checkSUM"}
+	if !reflect.DeepEqual(repos, want) {
+		t.Errorf("got %v, want %v", repos, want)
+	}
+}
+
+func TestParseRepoImportCSVMissingHeader(t *testing.T) {
+	if _, err := ParseRepoImport([]byte("name\nfoo\n"), "csv"); err == nil {
+		t.Fatal("expected an error for a csv with no repo column")
+	}
+}
+
+func TestParseRepoImportJSONFlatArray(t *testing.T) {
+	repos, err := ParseRepoImport([]byte(`["bjatkin/golf-engine", "bjatkin/checkSUM"]`), "json")
+	if err != nil {
+		t.Fatalf("ParseRepoImport: %v", err)
+	}
+	want := []string{"bjatkin/golf-engine", "bjatkin/checkSUM"}
+	if !reflect.DeepEqual(repos, want) {
+		t.Errorf("got %v, want %v", repos, want)
+	}
+}
+
+func TestParseRepoImportJSONObjectArray(t *testing.T) {
+	data := []byte(`[{"repo": "bjatkin/golf-engine", "owner": "platform"}, {"repo": "bjatkin/checkSUM"}]`)
+	repos, err := ParseRepoImport(data, "json")
+	if err != nil {
+		t.Fatalf("ParseRepoImport: %v", err)
+	}
+	want := []string{"bjatkin/golf-engine", "bjatkin/checkSUM"}
+	if !reflect.DeepEqual(repos, want) {
+		t.Errorf("got %v, want %v", repos, want)
+	}
+}
+
+func TestParseRepoImportUnsupportedFormat(t *testing.T) {
+	if _, err := ParseRepoImport([]byte("[]"), "xml"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
+
+func TestRepoImportStoreMergeSkipsExistingAndDuplicates(t *testing.T) {
+	store := newRepoImportStore()
+
+	result, err := store.Merge([]string{"bjatkin/golf-engine", "bjatkin/checkSUM"}, []string{"bjatkin/checkSUM"})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if !reflect.DeepEqual(result.Added, []string{"bjatkin/golf-engine"}) {
+		t.Errorf("Added = %v, want [bjatkin/golf-engine]", result.Added)
+	}
+	if !reflect.DeepEqual(result.Skipped, []string{"bjatkin/checkSUM"}) {
+		t.Errorf("Skipped = %v, want [bjatkin/checkSUM]", result.Skipped)
+	}
+
+	// importing the same repo again should now be skipped too
+	result, err = store.Merge([]string{"bjatkin/golf-engine"}, nil)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if len(result.Added) != 0 || len(result.Skipped) != 1 {
+		t.Errorf("expected a repeat import to be fully skipped, got %+v", result)
+	}
+}
+
+func TestRepoImportStoreMergeRejectsInvalidRepoName(t *testing.T) {
+	store := newRepoImportStore()
+	if _, err := store.Merge([]string{"not-a-repo"}, nil); err == nil {
+		t.Fatal("expected an error for a malformed repo name")
+	}
+}
+
+func TestMergeRepoImportIntoConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	original := "port: 8000\n\n# repos is the list of repos to search.\nrepos:\n  - bjatkin/KISSjs\n"
+	if err := os.WriteFile(configPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := MergeRepoImportIntoConfigFile(configPath, "json", []byte(`["bjatkin/golf-engine", "bjatkin/KISSjs"]`))
+	if err != nil {
+		t.Fatalf("MergeRepoImportIntoConfigFile: %v", err)
+	}
+	if !reflect.DeepEqual(result.Added, []string{"bjatkin/golf-engine"}) {
+		t.Errorf("Added = %v, want [bjatkin/golf-engine]", result.Added)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig after import: %v", err)
+	}
+	got := append([]string{}, cfg.Repos...)
+	sort.Strings(got)
+	want := []string{"bjatkin/KISSjs", "bjatkin/golf-engine"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("repos after import = %v, want %v", got, want)
+	}
+
+	rewritten, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(rewritten), "# repos is the list of repos to search.") {
+		t.Error("expected the existing comment above repos: to survive the rewrite")
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signRequest(secret, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + nonce + "." + hex.EncodeToString(bodyHash[:])))
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyServiceSignatureAcceptsValidSignature(t *testing.T) {
+	body := []byte(`{"q":"foo"}`)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := signRequest("secret", ts, "nonce-1", body)
+
+	if err := verifyServiceSignature("secret", ts, "nonce-1", sig, body, newNonceCache()); err != nil {
+		t.Fatalf("verifyServiceSignature: %v", err)
+	}
+}
+
+func TestVerifyServiceSignatureRejectsBadSignature(t *testing.T) {
+	body := []byte("hello")
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	err := verifyServiceSignature("secret", ts, "nonce-1", "v1=deadbeef", body, newNonceCache())
+	if err == nil {
+		t.Fatal("expected an error for a mismatched signature")
+	}
+}
+
+func TestVerifyServiceSignatureRejectsStaleTimestamp(t *testing.T) {
+	body := []byte("hello")
+	ts := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	sig := signRequest("secret", ts, "nonce-1", body)
+
+	err := verifyServiceSignature("secret", ts, "nonce-1", sig, body, newNonceCache())
+	if err == nil {
+		t.Fatal("expected an error for a stale timestamp")
+	}
+}
+
+func TestVerifyServiceSignatureRejectsReplayedNonce(t *testing.T) {
+	body := []byte("hello")
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := signRequest("secret", ts, "nonce-1", body)
+	nonces := newNonceCache()
+
+	if err := verifyServiceSignature("secret", ts, "nonce-1", sig, body, nonces); err != nil {
+		t.Fatalf("first request: verifyServiceSignature: %v", err)
+	}
+	if err := verifyServiceSignature("secret", ts, "nonce-1", sig, body, nonces); err == nil {
+		t.Fatal("expected an error for a replayed nonce")
+	}
+}
+
+func TestRequestSigningMiddlewareAllowsUnsignedWhenSecretUnset(t *testing.T) {
+	empty := ""
+	handler := requestSigningMiddleware(func() *string { return &empty }, newNonceCache(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRequestSigningMiddlewareRejectsMissingSignature(t *testing.T) {
+	secret := "secret"
+	handler := requestSigningMiddleware(func() *string { return &secret }, newNonceCache(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRequestSigningMiddlewareAcceptsValidSignatureAndPreservesBody(t *testing.T) {
+	secret := "secret"
+	body := `{"q":"foo"}`
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := signRequest(secret, ts, "nonce-1", []byte(body))
+
+	var gotBody string
+	handler := requestSigningMiddleware(func() *string { return &secret }, newNonceCache(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, len(body))
+		n, _ := r.Body.Read(buf)
+		gotBody = string(buf[:n])
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(body))
+	req.Header.Set(serviceSignatureTimestampHeader, ts)
+	req.Header.Set(serviceSignatureNonceHeader, "nonce-1")
+	req.Header.Set(serviceSignatureHeader, sig)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if gotBody != body {
+		t.Errorf("downstream body = %q, want %q", gotBody, body)
+	}
+}
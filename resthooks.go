@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// restHookEventNewMatch is the only trigger this service supports:
+// firing when a search turns up results for a subscribed term. Named to
+// match the REST Hooks convention Zapier/IFTTT integrations expect.
+const restHookEventNewMatch = "new_search_match"
+
+// restHookTimeout bounds how long a subscriber's endpoint is given to
+// accept a delivery, so a slow or dead target can't back up delivery of
+// later events.
+const restHookTimeout = 10 * time.Second
+
+// RESTHookSubscription is one Zapier/IFTTT-style REST hook: TargetURL is
+// POSTed a JSON payload whenever a /search for Term (a substring match
+// against the searched term; empty matches every search) finds results.
+type RESTHookSubscription struct {
+	ID        string `json:"id"`
+	Event     string `json:"event"`
+	Term      string `json:"term"`
+	TargetURL string `json:"target_url"`
+}
+
+// restHookStore tracks subscriptions and delivers events to them.
+type restHookStore struct {
+	mu   sync.Mutex
+	subs map[string]*RESTHookSubscription
+
+	client *http.Client
+}
+
+func newRESTHookStore() *restHookStore {
+	return &restHookStore{
+		subs:   map[string]*RESTHookSubscription{},
+		client: &http.Client{Timeout: restHookTimeout},
+	}
+}
+
+// Subscribe registers targetURL to receive event deliveries, optionally
+// scoped to searches whose term contains term.
+func (s *restHookStore) Subscribe(event, term, targetURL string) (*RESTHookSubscription, error) {
+	if event != restHookEventNewMatch {
+		return nil, fmt.Errorf("unsupported event: %q", event)
+	}
+	if targetURL == "" {
+		return nil, fmt.Errorf("target_url is required")
+	}
+
+	id, err := newRESTHookID()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &RESTHookSubscription{ID: id, Event: event, Term: term, TargetURL: targetURL}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[id] = sub
+	return sub, nil
+}
+
+// Unsubscribe removes a subscription, reporting whether it existed.
+func (s *restHookStore) Unsubscribe(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subs[id]; !ok {
+		return false
+	}
+	delete(s.subs, id)
+	return true
+}
+
+// List returns every active subscription.
+func (s *restHookStore) List() []RESTHookSubscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := make([]RESTHookSubscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, *sub)
+	}
+	return subs
+}
+
+// Import restores subscriptions from a state export bundle, preserving
+// their original IDs so any integration that saved one keeps working.
+func (s *restHookStore) Import(subs []RESTHookSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sub := range subs {
+		sub := sub
+		s.subs[sub.ID] = &sub
+	}
+}
+
+// NotifySearch delivers a new_search_match payload to every subscription
+// whose term matches evt, when it found at least one result.
+func (s *restHookStore) NotifySearch(evt searchExecutedEvent) {
+	if len(evt.Results) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	var matched []*RESTHookSubscription
+	for _, sub := range s.subs {
+		if sub.Term == "" || strings.Contains(evt.Term, sub.Term) {
+			matched = append(matched, sub)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, sub := range matched {
+		go s.deliver(sub, evt)
+	}
+}
+
+func (s *restHookStore) deliver(sub *RESTHookSubscription, evt searchExecutedEvent) {
+	body, err := json.Marshal(struct {
+		Event   string   `json:"event"`
+		Term    string   `json:"term"`
+		Results []Result `json:"results"`
+	}{Event: restHookEventNewMatch, Term: evt.Term, Results: evt.Results})
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sub.TargetURL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+func newRESTHookID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate rest hook id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
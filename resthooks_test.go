@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRESTHookStoreSubscribeUnsubscribe(t *testing.T) {
+	s := newRESTHookStore()
+
+	sub, err := s.Subscribe(restHookEventNewMatch, "foo", "https://example.com/hook")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if len(s.List()) != 1 {
+		t.Fatalf("expected 1 subscription, got %d", len(s.List()))
+	}
+
+	if !s.Unsubscribe(sub.ID) {
+		t.Fatal("expected Unsubscribe to succeed")
+	}
+	if len(s.List()) != 0 {
+		t.Fatalf("expected 0 subscriptions after unsubscribe, got %d", len(s.List()))
+	}
+}
+
+func TestRESTHookStoreSubscribeRejectsUnknownEvent(t *testing.T) {
+	s := newRESTHookStore()
+	if _, err := s.Subscribe("unknown_event", "", "https://example.com/hook"); err == nil {
+		t.Fatal("expected error for unsupported event")
+	}
+}
+
+func TestRESTHookStoreNotifySearchDeliversMatchingTerm(t *testing.T) {
+	delivered := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Event string `json:"event"`
+			Term  string `json:"term"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodyJSON, _ := json.Marshal(body)
+		delivered <- bodyJSON
+	}))
+	defer srv.Close()
+
+	s := newRESTHookStore()
+	if _, err := s.Subscribe(restHookEventNewMatch, "auth", srv.URL); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	s.NotifySearch(searchExecutedEvent{Term: "auth token", Results: []Result{{Path: "a.go"}}})
+
+	select {
+	case body := <-delivered:
+		var got struct {
+			Event string `json:"event"`
+			Term  string `json:"term"`
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("unmarshal delivered body: %v", err)
+		}
+		if got.Event != restHookEventNewMatch || got.Term != "auth token" {
+			t.Errorf("delivered = %+v, want event=%s term=auth token", got, restHookEventNewMatch)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for hook delivery")
+	}
+}
+
+func TestRESTHookStoreNotifySearchSkipsNonMatchingTerm(t *testing.T) {
+	delivered := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer srv.Close()
+
+	s := newRESTHookStore()
+	s.Subscribe(restHookEventNewMatch, "billing", srv.URL)
+
+	s.NotifySearch(searchExecutedEvent{Term: "auth token", Results: []Result{{Path: "a.go"}}})
+
+	select {
+	case <-delivered:
+		t.Fatal("expected no delivery for a non-matching term")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestRESTHookStoreNotifySearchSkipsEmptyResults(t *testing.T) {
+	delivered := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer srv.Close()
+
+	s := newRESTHookStore()
+	s.Subscribe(restHookEventNewMatch, "", srv.URL)
+
+	s.NotifySearch(searchExecutedEvent{Term: "auth", Results: nil})
+
+	select {
+	case <-delivered:
+		t.Fatal("expected no delivery when there are no results")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// safeRelPath validates that rel (a path read from an untrusted repo or
+// archive, using "/" separators) stays inside its root once cleaned: no
+// ".." traversal and no absolute path. It returns the cleaned,
+// OS-native form of rel.
+func safeRelPath(rel string) (string, error) {
+	if rel == "" {
+		return "", fmt.Errorf("empty path")
+	}
+
+	cleaned := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path escapes root: %q", rel)
+	}
+
+	return cleaned, nil
+}
+
+// caseFolder tracks paths by their case-folded form so a repo can't hide
+// a second file at a path that only differs by case, which would
+// silently overwrite or shadow the first on a case-insensitive
+// filesystem (e.g. macOS, Windows).
+type caseFolder struct {
+	seen map[string]string // lowercased path -> first path seen at it
+}
+
+func newCaseFolder() *caseFolder {
+	return &caseFolder{seen: map[string]string{}}
+}
+
+// collides reports whether path collides (case-insensitively) with a
+// path already recorded, and records path if it doesn't.
+func (c *caseFolder) collides(path string) bool {
+	key := strings.ToLower(path)
+	if _, ok := c.seen[key]; ok {
+		return true
+	}
+	c.seen[key] = path
+	return false
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestSafeRelPath(t *testing.T) {
+	cases := []struct {
+		in      string
+		wantErr bool
+	}{
+		{"inner/notes.txt", false},
+		{"./inner/notes.txt", false},
+		{"../escape.txt", true},
+		{"inner/../../escape.txt", true},
+		{"/etc/passwd", true},
+		{"", true},
+	}
+
+	for _, c := range cases {
+		_, err := safeRelPath(c.in)
+		if (err != nil) != c.wantErr {
+			t.Errorf("safeRelPath(%q) error = %v, wantErr %v", c.in, err, c.wantErr)
+		}
+	}
+}
+
+func TestCaseFolderCollides(t *testing.T) {
+	c := newCaseFolder()
+
+	if c.collides("Notes.txt") {
+		t.Error("expected first occurrence not to collide")
+	}
+	if !c.collides("notes.txt") {
+		t.Error("expected case-insensitive collision to be detected")
+	}
+	if c.collides("other.txt") {
+		t.Error("expected unrelated path not to collide")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCloneTimeout bounds a clone/index subprocess when the config
+// doesn't set clone_timeout.
+const defaultCloneTimeout = 5 * time.Minute
+
+// sandboxLimits bounds the resources a clone/index subprocess may use,
+// so a pathological repo (huge files, a zip bomb) can't take down the
+// serving process.
+type sandboxLimits struct {
+	timeout       time.Duration
+	maxCPUSeconds int
+	maxMemoryMB   int
+}
+
+// compileSandboxLimits validates and precompiles the sandbox-related
+// fields of cfg, returning a clear error at startup if the timeout is
+// malformed rather than failing silently mid-clone.
+func compileSandboxLimits(cfg Config) (sandboxLimits, error) {
+	limits := sandboxLimits{
+		timeout:       defaultCloneTimeout,
+		maxCPUSeconds: cfg.CloneMaxCPUSeconds,
+		maxMemoryMB:   cfg.CloneMaxMemoryMB,
+	}
+
+	if cfg.CloneTimeout != "" {
+		timeout, err := time.ParseDuration(cfg.CloneTimeout)
+		if err != nil {
+			return sandboxLimits{}, fmt.Errorf("invalid clone_timeout %q: %w", cfg.CloneTimeout, err)
+		}
+		limits.timeout = timeout
+	}
+
+	return limits, nil
+}
+
+// run executes name with args under the sandbox's limits: a hard
+// timeout via context cancellation, plus CPU and memory ceilings
+// enforced with ulimit in a wrapping shell so a runaway subprocess is
+// killed by the kernel rather than exhausting the host.
+func (l sandboxLimits) run(name string, args ...string) ([]byte, error) {
+	return l.runEnv(nil, name, args...)
+}
+
+// runEnv is run, additionally setting the given "KEY=value" environment
+// variables (on top of the parent process's environment) for the
+// subprocess, e.g. GIT_SSH_COMMAND for a deploy-key clone.
+func (l sandboxLimits) runEnv(env []string, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	shellCmd := shellQuote(name)
+	for _, a := range args {
+		shellCmd += " " + shellQuote(a)
+	}
+
+	var ulimits string
+	if l.maxCPUSeconds > 0 {
+		ulimits += "ulimit -t " + strconv.Itoa(l.maxCPUSeconds) + "; "
+	}
+	if l.maxMemoryMB > 0 {
+		ulimits += "ulimit -v " + strconv.Itoa(l.maxMemoryMB*1024) + "; "
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", ulimits+"exec "+shellCmd)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return out, fmt.Errorf("%s: timed out after %s", name, l.timeout)
+	}
+	return out, err
+}
+
+// shellQuote wraps s in single quotes for safe inclusion in a shell -c
+// string, escaping any single quotes it contains.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
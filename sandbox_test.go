@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompileSandboxLimitsDefaults(t *testing.T) {
+	limits, err := compileSandboxLimits(Config{})
+	if err != nil {
+		t.Fatalf("compileSandboxLimits: %v", err)
+	}
+	if limits.timeout != defaultCloneTimeout {
+		t.Errorf("timeout = %s, want default %s", limits.timeout, defaultCloneTimeout)
+	}
+}
+
+func TestCompileSandboxLimitsInvalidTimeout(t *testing.T) {
+	if _, err := compileSandboxLimits(Config{CloneTimeout: "not-a-duration"}); err == nil {
+		t.Error("expected an error for an invalid clone_timeout")
+	}
+}
+
+func TestSandboxLimitsRun(t *testing.T) {
+	limits := sandboxLimits{timeout: time.Second}
+	out, err := limits.run("echo", "hello")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !strings.Contains(string(out), "hello") {
+		t.Errorf("output = %q, want it to contain %q", out, "hello")
+	}
+}
+
+func TestSandboxLimitsRunTimeout(t *testing.T) {
+	limits := sandboxLimits{timeout: 10 * time.Millisecond}
+	if _, err := limits.run("sleep", "1"); err == nil {
+		t.Error("expected an error when the subprocess exceeds its timeout")
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("it's a test"); got != `'it'\''s a test'` {
+		t.Errorf("shellQuote = %q, want %q", got, `'it'\''s a test'`)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+// sarifVersion and sarifSchema identify the SARIF format version this
+// output targets, per https://sarifweb.azurewebsites.net/.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+)
+
+// sarifLog is the top-level SARIF document.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// buildSARIF converts policy findings into a SARIF log, so they can be
+// uploaded to GitHub code scanning or consumed by standard security
+// tooling.
+func buildSARIF(findings []PolicyFinding) sarifLog {
+	ruleSeen := map[string]bool{}
+	var rules []sarifRule
+	var results []sarifResult
+
+	for _, f := range findings {
+		if !ruleSeen[f.RuleID] {
+			ruleSeen[f.RuleID] = true
+			rules = append(rules, sarifRule{ID: f.RuleID, Name: f.Description})
+		}
+
+		results = append(results, sarifResult{
+			RuleID:  f.RuleID,
+			Message: sarifMessage{Text: f.Description + " in " + f.Repo},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: f.Path},
+					Region:           sarifRegion{StartLine: f.Line},
+				},
+			}},
+		})
+	}
+
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "gitSearch", Rules: rules}},
+			Results: results,
+		}},
+	}
+}
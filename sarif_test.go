@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestBuildSARIF(t *testing.T) {
+	findings := []PolicyFinding{
+		{RuleID: "aws-access-key-id", Description: "AWS access key ID", Repo: "owner/repo", Path: "config.go", Line: 3},
+	}
+
+	log := buildSARIF(findings)
+	if log.Version != sarifVersion {
+		t.Errorf("Version = %q, want %q", log.Version, sarifVersion)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(log.Runs))
+	}
+	if len(log.Runs[0].Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(log.Runs[0].Results))
+	}
+	result := log.Runs[0].Results[0]
+	if result.RuleID != "aws-access-key-id" {
+		t.Errorf("RuleID = %q, want aws-access-key-id", result.RuleID)
+	}
+	if result.Locations[0].PhysicalLocation.Region.StartLine != 3 {
+		t.Errorf("StartLine = %d, want 3", result.Locations[0].PhysicalLocation.Region.StartLine)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// defaultSchedulerTick is how often syncScheduler wakes up to check
+// whether any repo is due for a sync. It's independent of sync_interval
+// itself; a shorter sync_interval than this tick still fires, just with
+// coarser timing precision.
+const defaultSchedulerTick = 30 * time.Second
+
+// syncScheduler periodically enqueues a JobPriorityScheduled index job
+// for each configured repo whose sync interval has elapsed since it was
+// last enqueued, so local clones (and the trigram/symbol indexes built
+// from them) stay fresh without an operator polling /admin/mirrors and
+// triggering a sync by hand. The actual sync still runs through
+// jobQueue, so a scheduled job shares the same concurrency limit and
+// retry behavior as a webhook-triggered or manually triggered one.
+// /admin/mirrors already reports each repo's LastSyncAt/LastSyncError,
+// so it doubles as the scheduler's per-repo status endpoint.
+type syncScheduler struct {
+	mu      sync.Mutex
+	repos   []string
+	def     time.Duration
+	perRepo map[string]time.Duration
+	lastRun map[string]time.Time
+}
+
+func newSyncScheduler() *syncScheduler {
+	return &syncScheduler{lastRun: map[string]time.Time{}}
+}
+
+// Reconfigure recompiles cfg's sync_interval and repo_sync_intervals,
+// called on every config reload. A repo with no interval configured
+// (the default) is never scheduled; sync_interval sets the default for
+// every repo, and repo_sync_intervals overrides it per repo.
+func (s *syncScheduler) Reconfigure(cfg Config) error {
+	var def time.Duration
+	if cfg.SyncInterval != "" {
+		d, err := time.ParseDuration(cfg.SyncInterval)
+		if err != nil {
+			return fmt.Errorf("sync_interval: %w", err)
+		}
+		def = d
+	}
+
+	perRepo := map[string]time.Duration{}
+	for repo, raw := range cfg.RepoSyncIntervals {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("repo_sync_intervals[%s]: %w", repo, err)
+		}
+		perRepo[repo] = d
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos = cfg.Repos
+	s.def = def
+	s.perRepo = perRepo
+	return nil
+}
+
+// intervalFor returns repo's configured sync interval, or 0 if syncing
+// isn't scheduled for it.
+func (s *syncScheduler) intervalFor(repo string) time.Duration {
+	if d, ok := s.perRepo[repo]; ok {
+		return d
+	}
+	return s.def
+}
+
+// Run ticks every defaultSchedulerTick for the life of the process,
+// enqueueing a sync (via enqueue) for every repo due for one. Each due
+// repo is enqueued after a few seconds of random jitter so a fleet of
+// repos that all became due at once doesn't fetch simultaneously.
+// Intended to be started once with "go scheduler.Run(...)".
+func (s *syncScheduler) Run(enqueue func(repo string)) {
+	for now := range time.Tick(defaultSchedulerTick) {
+		for _, repo := range s.due(now) {
+			jitter := time.Duration(rand.Int63n(int64(defaultSchedulerTick)))
+			time.AfterFunc(jitter, func(repo string) func() {
+				return func() { enqueue(repo) }
+			}(repo))
+		}
+	}
+}
+
+// due returns every configured repo whose sync interval has elapsed as
+// of now, recording now as that repo's last run so it isn't returned
+// again until its interval elapses once more.
+func (s *syncScheduler) due(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []string
+	for _, repo := range s.repos {
+		interval := s.intervalFor(repo)
+		if interval <= 0 {
+			continue
+		}
+		if !s.lastRun[repo].IsZero() && now.Sub(s.lastRun[repo]) < interval {
+			continue
+		}
+		s.lastRun[repo] = now
+		due = append(due, repo)
+	}
+	return due
+}
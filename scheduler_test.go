@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyncSchedulerReconfigureRejectsInvalidDuration(t *testing.T) {
+	s := newSyncScheduler()
+	if err := s.Reconfigure(Config{SyncInterval: "not-a-duration"}); err == nil {
+		t.Error("expected an error for an invalid sync_interval")
+	}
+	if err := s.Reconfigure(Config{Repos: []string{"owner/repo"}, RepoSyncIntervals: map[string]string{"owner/repo": "nope"}}); err == nil {
+		t.Error("expected an error for an invalid repo_sync_intervals entry")
+	}
+}
+
+func TestSyncSchedulerIntervalForFallsBackToDefault(t *testing.T) {
+	s := newSyncScheduler()
+	cfg := Config{
+		Repos:             []string{"owner/a", "owner/b"},
+		SyncInterval:      "1h",
+		RepoSyncIntervals: map[string]string{"owner/b": "5m"},
+	}
+	if err := s.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+
+	if got := s.intervalFor("owner/a"); got != time.Hour {
+		t.Errorf("intervalFor(owner/a) = %v, want 1h", got)
+	}
+	if got := s.intervalFor("owner/b"); got != 5*time.Minute {
+		t.Errorf("intervalFor(owner/b) = %v, want 5m", got)
+	}
+}
+
+func TestSyncSchedulerTickEnqueuesDueReposOnly(t *testing.T) {
+	s := newSyncScheduler()
+	cfg := Config{
+		Repos:        []string{"owner/scheduled", "owner/unscheduled"},
+		SyncInterval: "1h",
+	}
+	if err := s.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	// owner/unscheduled has no sync interval of its own, so drop the
+	// default's fallback for it by giving it an explicit zero override.
+	s.perRepo["owner/unscheduled"] = 0
+
+	now := time.Now()
+	if due := s.due(now); len(due) != 1 || due[0] != "owner/scheduled" {
+		t.Fatalf("due = %v, want only owner/scheduled", due)
+	}
+
+	// Checking again immediately shouldn't re-surface it before the
+	// interval elapses.
+	if due := s.due(now.Add(time.Minute)); len(due) != 0 {
+		t.Fatalf("due = %v, want none before the interval elapses", due)
+	}
+
+	if due := s.due(now.Add(2 * time.Hour)); len(due) != 1 || due[0] != "owner/scheduled" {
+		t.Fatalf("due = %v, want owner/scheduled once the interval elapses again", due)
+	}
+}
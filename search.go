@@ -0,0 +1,730 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Location identifies one place a file (identified by blob SHA) lives.
+type Location struct {
+	Repo string `json:"repo"`
+	Path string `json:"path"`
+	URL  string `json:"url"`
+}
+
+// Result is a single file match returned to search clients. Repo, Path
+// and URL describe the first location the file was found at; Locations
+// lists every configured repo the same blob (by SHA) was found in, so
+// forks and mirrors collapse into one result instead of duplicates.
+type Result struct {
+	Repo      string     `json:"repo"`
+	Alias     string     `json:"alias,omitempty"`
+	Path      string     `json:"path"`
+	URL       string     `json:"url"`
+	SHA       string     `json:"sha,omitempty"`
+	Locations []Location `json:"locations,omitempty"`
+
+	// Provider is the backend kind (see RepoBackend.Kind, backendGithub
+	// et al.) that this result's Repo was searched with, so a merged,
+	// multi-provider result set can be filtered or grouped by origin.
+	Provider string   `json:"provider"`
+	FileType FileType `json:"file_type"`
+	Lines    []Line   `json:"lines"`
+	Explain  *Explain `json:"explain,omitempty"`
+
+	// Introduced is the commit and date the matching term was first
+	// introduced at, set only when a caller opts in via firstIntroduced,
+	// since computing it walks the file's entire history.
+	Introduced *Introduction `json:"introduced,omitempty"`
+
+	// Owners lists the file's top committers by commit count, set only
+	// when a caller opts in via owners, complementing CODEOWNERS data
+	// for repos that don't maintain that file.
+	Owners []Owner `json:"owners,omitempty"`
+
+	// ID identifies this result behind /v1/click?result_id=..., so a
+	// client-side result list can route link clicks through it for
+	// relevance tracking before following the real URL. Set only by
+	// handleSearch (see clickTracker), never by Search itself.
+	ID string `json:"id,omitempty"`
+}
+
+// searcher runs searches for code across the repos configured for the
+// service.
+type searcher struct {
+	cfg         Config
+	rank        rankConfig
+	backends    *backendRouter
+	local       *localIndex
+	urlRewrites []compiledURLRewrite
+	aliases     *repoAliasResolver
+	disabled    *repoDisableStore
+	ab          *abExperiment
+}
+
+// newSearcher builds a searcher for cfg, compiling and validating its
+// ranking rules up front so a bad rule fails fast at startup instead of
+// mid-search. disabled is shared with the owning server so a repo
+// disabled via the admin API stays disabled across config reloads.
+// imports is likewise shared so repos merged in via bulk import stay
+// configured across config reloads, even though they aren't in the
+// config file itself. ab is likewise shared so its assignment and
+// outcome counts accumulate across config reloads instead of resetting
+// every time cfg changes.
+func newSearcher(cfg Config, disabled *repoDisableStore, imports *repoImportStore, ab *abExperiment) (*searcher, error) {
+	rank, err := compileRankConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if ab == nil {
+		ab = newABExperiment()
+	}
+	if err := ab.Reconfigure(cfg); err != nil {
+		return nil, err
+	}
+
+	sandbox, err := compileSandboxLimits(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	backends, err := newBackendRouter(cfg.RepoBackends, cfg.GithubBaseURL, cfg.GithubToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateLocalIndexBackend(cfg.LocalIndexBackend); err != nil {
+		return nil, err
+	}
+
+	if err := validateRoles(cfg.Roles); err != nil {
+		return nil, err
+	}
+
+	urlRewrites, err := compileURLRewriteRules(cfg.URLRewriteRules)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, err := newRepoAliasResolver(cfg.RepoAliases)
+	if err != nil {
+		return nil, err
+	}
+
+	if disabled == nil {
+		disabled = newRepoDisableStore()
+	}
+
+	if imports != nil {
+		seen := make(map[string]bool, len(cfg.Repos))
+		merged := make([]string, 0, len(cfg.Repos))
+		for _, repo := range cfg.Repos {
+			if !seen[repo] {
+				seen[repo] = true
+				merged = append(merged, repo)
+			}
+		}
+		for _, repo := range imports.List() {
+			if !seen[repo] {
+				seen[repo] = true
+				merged = append(merged, repo)
+			}
+		}
+		cfg.Repos = merged
+	}
+
+	local := newLocalIndex(cfg.LocalDataDir)
+	local.archiveExtensions = cfg.ArchiveExtensions
+	local.archiveMaxBytes = cfg.ArchiveMaxBytes
+	local.lfsMode = cfg.LFSMode
+	local.sandbox = sandbox
+	local.cloneDepth = cfg.CloneDepth
+	local.cloneFilter = cfg.CloneFilter
+	local.diskQuotaBytes = cfg.LocalDiskQuotaMB << 20
+	if cfg.RegexSearchTimeout != "" {
+		timeout, err := time.ParseDuration(cfg.RegexSearchTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex_search_timeout %q: %w", cfg.RegexSearchTimeout, err)
+		}
+		local.regexSearchTimeout = timeout
+	}
+	if len(cfg.CloneOptions) > 0 {
+		local.cloneOptions = make(map[string]RepoCloneOptions, len(cfg.CloneOptions))
+		for _, opt := range cfg.CloneOptions {
+			local.cloneOptions[opt.Repo] = opt
+		}
+	}
+
+	return &searcher{
+		cfg:         cfg,
+		rank:        rank,
+		backends:    backends,
+		local:       local,
+		urlRewrites: urlRewrites,
+		aliases:     aliases,
+		disabled:    disabled,
+		ab:          ab,
+	}, nil
+}
+
+// SearchHex searches every configured repo's local clone for the byte
+// sequence encoded by hexPattern.
+func (s *searcher) SearchHex(hexPattern string) ([]HexMatch, error) {
+	var matches []HexMatch
+	for _, repo := range s.cfg.Repos {
+		if s.disabled.IsDisabled(repo) {
+			continue
+		}
+		m, err := s.local.searchHex(repo, hexPattern)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m...)
+	}
+	return matches, nil
+}
+
+// SearchHexAtRef is SearchHex as of a historical ref (a SHA or tag)
+// rather than each repo's default branch.
+func (s *searcher) SearchHexAtRef(hexPattern, ref string) ([]HexMatch, error) {
+	var matches []HexMatch
+	for _, repo := range s.cfg.Repos {
+		if s.disabled.IsDisabled(repo) {
+			continue
+		}
+		m, err := s.local.searchHexAtRef(repo, ref, hexPattern)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m...)
+	}
+	return matches, nil
+}
+
+// SearchLocal searches every configured repo's local clone for term,
+// flattening structured and minified files first. Unlike Search, this
+// reads real file content on disk rather than querying GitHub's code
+// search API. firstIntroduced additionally computes, per result, the
+// commit and date term was first introduced at, via a pickaxe search;
+// owners additionally computes each result's top committers via git
+// shortlog. Both are opt-in since they walk the file's entire history.
+func (s *searcher) SearchLocal(term string, firstIntroduced, owners bool) ([]Result, error) {
+	var results []Result
+	for _, repo := range s.cfg.Repos {
+		if s.disabled.IsDisabled(repo) {
+			continue
+		}
+		r, err := s.local.searchText(repo, term)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	s.tagAliases(results)
+	if firstIntroduced {
+		if err := s.tagIntroductions(results, term); err != nil {
+			return nil, err
+		}
+	}
+	if owners {
+		if err := s.tagOwners(results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// SearchLocalAtRef is SearchLocal against every configured repo as of a
+// historical ref (a SHA or tag) instead of its default branch, so an
+// audit can answer "did this string exist in v1.2.0?".
+func (s *searcher) SearchLocalAtRef(term, ref string, firstIntroduced, owners bool) ([]Result, error) {
+	var results []Result
+	for _, repo := range s.cfg.Repos {
+		if s.disabled.IsDisabled(repo) {
+			continue
+		}
+		r, err := s.local.searchTextAtRef(repo, ref, term)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	s.tagAliases(results)
+	if firstIntroduced {
+		if err := s.tagIntroductions(results, term); err != nil {
+			return nil, err
+		}
+	}
+	if owners {
+		if err := s.tagOwners(results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// SearchLocalRegex is SearchLocal, but pattern is a regular expression
+// compiled safely (see compileSearchRegex) rather than a literal
+// substring, so a caller can express patterns GitHub's code search API
+// has no equivalent for at all (anchors, character classes,
+// alternation) - one of the local index's key differentiators over the
+// default API-backed search. It doesn't support firstIntroduced or
+// owners, both of which assume a literal pickaxe term.
+func (s *searcher) SearchLocalRegex(pattern string) ([]Result, error) {
+	var results []Result
+	for _, repo := range s.cfg.Repos {
+		if s.disabled.IsDisabled(repo) {
+			continue
+		}
+		r, err := s.local.searchRegex(repo, pattern)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	s.tagAliases(results)
+	return results, nil
+}
+
+// SearchLocalRegexAtRef is SearchLocalRegex against every configured
+// repo as of a historical ref (a SHA or tag) instead of its default
+// branch.
+func (s *searcher) SearchLocalRegexAtRef(pattern, ref string) ([]Result, error) {
+	var results []Result
+	for _, repo := range s.cfg.Repos {
+		if s.disabled.IsDisabled(repo) {
+			continue
+		}
+		r, err := s.local.searchRegexAtRef(repo, ref, pattern)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	s.tagAliases(results)
+	return results, nil
+}
+
+// SearchRepo searches a single repo's local clone for term. It's used by
+// integrations that already know which repo they care about, such as the
+// Backstage catalog endpoint, rather than fanning out across every
+// configured repo.
+func (s *searcher) SearchRepo(repo, term string) ([]Result, error) {
+	if s.disabled.IsDisabled(repo) {
+		return nil, nil
+	}
+
+	results, err := s.local.searchText(repo, term)
+	if err != nil {
+		return nil, err
+	}
+	s.tagAliases(results)
+	return results, nil
+}
+
+// tagAliases fills in each result's Alias from its Repo, so clients that
+// prefer a repo's short alias don't have to look it up separately.
+func (s *searcher) tagAliases(results []Result) {
+	for i := range results {
+		if alias, ok := s.aliases.AliasFor(results[i].Repo); ok {
+			results[i].Alias = alias
+		}
+	}
+}
+
+// tagIntroductions fills in each result's Introduced from a pickaxe
+// search for term over its path's history, caching results so repeated
+// look-ups against the same repo/path/term don't re-run git log.
+func (s *searcher) tagIntroductions(results []Result, term string) error {
+	for i := range results {
+		path, _, _ := strings.Cut(results[i].Path, "!") // archive members: pickaxe the archive file itself
+		intro, err := s.local.firstIntroduced(results[i].Repo, path, term)
+		if err != nil {
+			return err
+		}
+		results[i].Introduced = intro
+	}
+	return nil
+}
+
+// tagOwners fills in each result's Owners with its file's top committers
+// from git shortlog, complementing CODEOWNERS data for repos that don't
+// maintain that file.
+func (s *searcher) tagOwners(results []Result) error {
+	for i := range results {
+		path, _, _ := strings.Cut(results[i].Path, "!") // archive members: attribute the archive file itself
+		owners, err := s.local.topOwners(results[i].Repo, path)
+		if err != nil {
+			return err
+		}
+		results[i].Owners = owners
+	}
+	return nil
+}
+
+// CheckPolicy scans every configured repo's local clone against the
+// built-in secret-shape policy rules.
+func (s *searcher) CheckPolicy() ([]PolicyFinding, error) {
+	var findings []PolicyFinding
+	for _, repo := range s.cfg.Repos {
+		f, err := s.local.checkPolicy(repo)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, f...)
+	}
+	return findings, nil
+}
+
+// SearchPage carries pagination state and metadata for a Search call:
+// the page and perPage that were applied, and, once results are back,
+// GitHub's reported TotalCount and whether any searched repo has more
+// results beyond this page.
+type SearchPage struct {
+	Page       int  `json:"page"`
+	PerPage    int  `json:"per_page"`
+	TotalCount int  `json:"total_count"`
+	HasNext    bool `json:"has_next"`
+}
+
+// SearchCost reports how expensive a search request was, so API consumers
+// can monitor their own efficiency: how many backend requests it made (0
+// if served entirely from the result cache), how long its backend-fan-out
+// and result-ranking stages each took, and how much of the default
+// GitHub client's rate limit budget it spent. GithubRateLimitSpent is 0
+// if the default backend doesn't expose a rate limit (e.g. it's never
+// made a request yet). CacheHit is set by handlers that can serve a
+// request from resultCache instead of calling Search, such as refine;
+// Search itself never sets it, since it always computes fresh results.
+// RankArm is the RankExperiment arm ("a" or "b") that scored this
+// search's results, always "a" when no experiment is configured.
+// Warnings reports, per provider (an override repo's RepoBackend.Kind),
+// how long that provider's request took and whether it failed; a
+// provider failing doesn't fail the whole search, since the other
+// providers' results are still useful.
+type SearchCost struct {
+	BackendCalls         int              `json:"backend_calls"`
+	CacheHit             bool             `json:"cache_hit"`
+	GithubRateLimitSpent int              `json:"github_rate_limit_spent"`
+	StageLatencyMS       map[string]int64 `json:"stage_latency_ms"`
+	RankArm              string           `json:"rank_arm"`
+	Warnings             []SearchWarning  `json:"warnings,omitempty"`
+}
+
+// SearchWarning reports one override-backend provider's outcome during a
+// fan-out search: Repo is the specific repo queried, Provider is its
+// RepoBackend.Kind, LatencyMS is how long the request took, and Error is
+// set if the request failed for that provider (its results are simply
+// omitted from the search, rather than failing the whole request).
+type SearchWarning struct {
+	Repo      string `json:"repo"`
+	Provider  string `json:"provider"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// rateLimitReporter is implemented by codeSearchClients that track a
+// rate limit budget (currently only githubClient), letting Search report
+// how much of it a call spent without forcing every backend to have the
+// concept.
+type rateLimitReporter interface {
+	RateLimit() githubRateLimit
+}
+
+// Search looks up term across every repo configured for the service and
+// returns the matching files with per-line highlight offsets. contextLines
+// bounds how many lines of surrounding context are kept around each
+// match; it is capped by the service's configured max_context_lines.
+// page and perPage are forwarded to each repo's own backend query; since
+// a search fans out across every configured repo, the returned
+// SearchPage's TotalCount sums each repo's own total, and HasNext is set
+// if any single repo has more results beyond the current page.
+//
+// Repos without a RepoBackend override share the default GitHub client,
+// which can search several repos in one request; those are batched into
+// as few requests as GitHub's query length limit allows and run
+// concurrently, rather than one request per repo. Rename detection isn't
+// attempted for a batched request, since a redirect in its response
+// can't be unambiguously attributed back to one of several queried repo
+// names. Repos with an override are queried concurrently regardless of
+// how many distinct providers they span (GitHub, GitLab, Bitbucket,
+// Gitea, ...), each checked for renames individually; a provider that
+// fails doesn't fail the whole search, but is instead reported back as a
+// SearchCost.Warnings entry. Every result is annotated with the provider
+// (see Result.Provider) it came from, and results found identically by
+// more than one provider (e.g. a repo mirrored to both GitHub and an
+// internal GitLab) are deduped by FileURL (see collapseDuplicates).
+//
+// apiKey sticks the caller to a RankExperiment arm (see abExperiment.Assign);
+// which arm actually scored the results is reported back in the
+// returned SearchCost's RankArm field.
+//
+// group, if non-empty, scopes the search to the repos config.RepoGroups
+// assigns to that name, instead of every configured repo.
+//
+// excludeForks and excludeArchived append fork:false / archived:false
+// qualifiers to term, so forked or archived repos are left out of the
+// results; callers pass s.cfg.ExcludeForks / s.cfg.ExcludeArchived to
+// get the config's default, or their own value to override it for one
+// request.
+//
+// lang, if non-empty, appends a language: qualifier to term and, since
+// not every backend honors that qualifier, also drops any result whose
+// detected FileType.Language doesn't match it (see filterByLanguage).
+// Callers should reject an unrecognized lang up front with
+// isKnownLanguage, since an unrecognized qualifier just silently returns
+// nothing.
+//
+// pathFilter, if non-empty, appends a path: qualifier to term and drops
+// any result whose Path doesn't start with it (see filterByPathPrefix),
+// since GitHub's path: qualifier matches anywhere in the path and the
+// local index doesn't understand it at all.
+func (s *searcher) Search(term string, contextLines int, explain bool, page, perPage int, apiKey, group string, excludeForks, excludeArchived bool, lang, pathFilter string) ([]Result, []RepoRename, SearchPage, SearchCost, error) {
+	if contextLines < 0 || contextLines > s.cfg.MaxContextLines {
+		contextLines = s.cfg.MaxContextLines
+	}
+	if excludeForks {
+		term += " fork:false"
+	}
+	if excludeArchived {
+		term += " archived:false"
+	}
+	if lang != "" {
+		term += " language:" + strings.ToLower(lang)
+	}
+	if pathFilter != "" {
+		term += " path:" + pathFilter
+	}
+
+	meta := SearchPage{Page: page, PerPage: perPage}
+	addTotal := func(total int) {
+		meta.TotalCount += total
+		if perPage > 0 && page*perPage < total {
+			meta.HasNext = true
+		}
+	}
+
+	cost := SearchCost{StageLatencyMS: map[string]int64{}}
+	rateLimitBefore := 0
+	if rl, ok := s.backends.def.(rateLimitReporter); ok {
+		rateLimitBefore = rl.RateLimit().Remaining
+	}
+
+	var results []Result
+	var renames []RepoRename
+	seenRenames := map[string]bool{}
+	pushedAt := map[string]time.Time{}
+
+	var defaultRepos, overrideRepos []string
+	for _, repo := range reposInGroup(s.cfg, group) {
+		if s.disabled.IsDisabled(repo) {
+			continue
+		}
+		if _, ok := s.backends.byRepo[repo]; ok {
+			overrideRepos = append(overrideRepos, repo)
+		} else {
+			defaultRepos = append(defaultRepos, repo)
+		}
+	}
+
+	backendStart := time.Now()
+
+	if multi, ok := s.backends.def.(multiRepoSearchClient); ok && len(defaultRepos) > 0 {
+		chunks := chunkReposForQuery(term, defaultRepos)
+		cost.BackendCalls += len(chunks)
+
+		items, total, err := searchChunksConcurrently(multi, term, defaultRepos, page, perPage)
+		if err != nil {
+			return nil, nil, SearchPage{}, SearchCost{}, err
+		}
+		addTotal(total)
+		appendSearchResults(items, contextLines, s.urlRewrites, s.cfg.GithubHTMLBaseURL, backendGithub, &results, pushedAt)
+	} else {
+		for _, repo := range defaultRepos {
+			items, total, err := s.backends.def.searchCode(term, repo, page, perPage)
+			if err != nil {
+				return nil, nil, SearchPage{}, SearchCost{}, err
+			}
+			cost.BackendCalls++
+			addTotal(total)
+			detectRepoRename(repo, items, seenRenames, &renames)
+			appendSearchResults(items, contextLines, s.urlRewrites, s.cfg.GithubHTMLBaseURL, backendGithub, &results, pushedAt)
+		}
+	}
+
+	overrideResults, overrideTotal, warnings := s.searchOverrideRepos(overrideRepos, term, page, perPage, contextLines, seenRenames, &renames, pushedAt)
+	cost.BackendCalls += len(overrideRepos)
+	addTotal(overrideTotal)
+	results = append(results, overrideResults...)
+	cost.Warnings = warnings
+	cost.StageLatencyMS["backend"] = time.Since(backendStart).Milliseconds()
+
+	rankStart := time.Now()
+	results = collapseDuplicates(results)
+	if lang != "" {
+		results = filterByLanguage(results, lang)
+	}
+	if pathFilter != "" {
+		results = filterByPathPrefix(results, pathFilter)
+	}
+	s.tagAliases(results)
+	arm := s.ab.Assign(apiKey)
+	s.ab.RecordAssignment(arm)
+	ranked := rankResults(results, pushedAt, s.ab.RankConfigFor(arm, s.rank), explain)
+	cost.StageLatencyMS["rank"] = time.Since(rankStart).Milliseconds()
+	cost.RankArm = arm
+
+	if rl, ok := s.backends.def.(rateLimitReporter); ok {
+		if after := rl.RateLimit().Remaining; rateLimitBefore > after {
+			cost.GithubRateLimitSpent = rateLimitBefore - after
+		}
+	}
+
+	return ranked, renames, meta, cost, nil
+}
+
+// searchOverrideRepos queries every repo with a RepoBackend override
+// concurrently, since they may span several distinct providers (GitLab,
+// Bitbucket, Gitea, another GHE instance) with no benefit to querying
+// them one at a time. A provider failing is recorded as a SearchWarning
+// rather than failing the whole search, since the other providers'
+// results are still useful. Each repo's push times are merged into
+// pushedAt after every goroutine finishes, so the map itself is never
+// written concurrently.
+func (s *searcher) searchOverrideRepos(repos []string, term string, page, perPage, contextLines int, seenRenames map[string]bool, renames *[]RepoRename, pushedAt map[string]time.Time) ([]Result, int, []SearchWarning) {
+	type outcome struct {
+		results  []Result
+		total    int
+		pushedAt map[string]time.Time
+		warning  *SearchWarning
+	}
+
+	outcomes := make([]outcome, len(repos))
+	var wg sync.WaitGroup
+	var mu sync.Mutex // guards seenRenames/renames, shared across goroutines
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo string) {
+			defer wg.Done()
+
+			provider := s.backends.kindFor(repo)
+			start := time.Now()
+			items, total, err := s.backends.clientFor(repo).searchCode(term, repo, page, perPage)
+			latency := time.Since(start).Milliseconds()
+			if err != nil {
+				outcomes[i] = outcome{warning: &SearchWarning{Repo: repo, Provider: provider, LatencyMS: latency, Error: err.Error()}}
+				return
+			}
+
+			mu.Lock()
+			detectRepoRename(repo, items, seenRenames, renames)
+			mu.Unlock()
+
+			results := []Result{}
+			local := map[string]time.Time{}
+			appendSearchResults(items, contextLines, s.urlRewrites, "", provider, &results, local)
+			outcomes[i] = outcome{results: results, total: total, pushedAt: local, warning: &SearchWarning{Repo: repo, Provider: provider, LatencyMS: latency}}
+		}(i, repo)
+	}
+	wg.Wait()
+
+	var results []Result
+	var total int
+	var warnings []SearchWarning
+	for _, o := range outcomes {
+		results = append(results, o.results...)
+		total += o.total
+		for repo, t := range o.pushedAt {
+			pushedAt[repo] = t
+		}
+		if o.warning != nil {
+			warnings = append(warnings, *o.warning)
+		}
+	}
+	return results, total, warnings
+}
+
+// detectRepoRename records a RepoRename the first time repo's search
+// results come back under a different Repository.FullName, e.g. after a
+// GitHub rename or ownership transfer.
+func detectRepoRename(repo string, items []githubSearchItem, seen map[string]bool, renames *[]RepoRename) {
+	for _, item := range items {
+		if newRepo := item.Repository.FullName; newRepo != "" && !strings.EqualFold(newRepo, repo) && !seen[repo] {
+			seen[repo] = true
+			*renames = append(*renames, RepoRename{OldRepo: repo, NewRepo: newRepo})
+		}
+	}
+}
+
+// appendSearchResults converts GitHub search items into Results,
+// appending them to *results and recording each item's repo push time in
+// pushedAt. githubHTMLBase, when non-empty, overrides the scheme and
+// host of each item's click-through URL before urlRewrites run (see
+// Config.GithubHTMLBaseURL); pass "" for items from a repo backend other
+// than the default GitHub client, which already reports its own correct
+// URL. provider is stamped onto every Result (see Result.Provider).
+func appendSearchResults(items []githubSearchItem, contextLines int, urlRewrites []compiledURLRewrite, githubHTMLBase, provider string, results *[]Result, pushedAt map[string]time.Time) {
+	for _, item := range items {
+		pushedAt[item.Repository.FullName] = item.Repository.PushedAt
+
+		var lines []Line
+		var content strings.Builder
+		for _, tm := range item.TextMatches {
+			lines = append(lines, splitFragment(tm.Fragment, []githubTextMatch{tm})...)
+			content.WriteString(tm.Fragment)
+		}
+
+		url := rewriteGithubHTMLHost(item.HTMLURL, githubHTMLBase)
+		*results = append(*results, Result{
+			Repo:     item.Repository.FullName,
+			Path:     item.Path,
+			URL:      rewriteURL(url, urlRewrites),
+			SHA:      item.SHA,
+			Provider: provider,
+			FileType: detectFileType(item.Path, content.String()),
+			Lines:    trimContext(mergeLines(lines), contextLines),
+		})
+	}
+}
+
+// filterByPathPrefix keeps only results whose Path starts with prefix, a
+// backstop for pathFilter (see Search), since not every backend honors
+// the path: qualifier appended to the query and the local index doesn't
+// understand it at all.
+func filterByPathPrefix(results []Result, prefix string) []Result {
+	var out []Result
+	for _, r := range results {
+		if strings.HasPrefix(r.Path, prefix) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// rewriteGithubHTMLHost replaces rawURL's scheme and host with base's,
+// keeping its path and query unchanged, so a GHE deployment whose API
+// endpoint isn't itself a browsable web UI can still produce a usable
+// click-through link (see Config.GithubHTMLBaseURL). Returns rawURL
+// unchanged if base is empty or either URL fails to parse.
+func rewriteGithubHTMLHost(rawURL, base string) string {
+	if base == "" || rawURL == "" {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return rawURL
+	}
+
+	u.Scheme = b.Scheme
+	u.Host = b.Host
+	return u.String()
+}
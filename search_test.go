@@ -0,0 +1,306 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSearchDetectsRepoRename(t *testing.T) {
+	stub := &stubSearchClient{items: []githubSearchItem{
+		{
+			Name: "main.go",
+			Path: "main.go",
+			Repository: struct {
+				FullName string    `json:"full_name"`
+				PushedAt time.Time `json:"pushed_at"`
+			}{FullName: "bjatkin/new-name"},
+			TextMatches: []githubTextMatch{{Fragment: "func main() {}"}},
+		},
+	}}
+
+	s := &searcher{
+		cfg:      Config{Repos: []string{"bjatkin/old-name"}, MaxContextLines: 10},
+		backends: &backendRouter{def: stub, byRepo: map[string]codeSearchClient{}},
+		aliases:  &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}},
+		disabled: newRepoDisableStore(),
+		ab:       newABExperiment(),
+	}
+
+	results, renames, _, _, err := s.Search("main", 5, false, 1, defaultSearchPerPage, "", "", false, false, "", "")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if len(renames) != 1 || renames[0] != (RepoRename{OldRepo: "bjatkin/old-name", NewRepo: "bjatkin/new-name"}) {
+		t.Errorf("renames = %+v, want a single bjatkin/old-name -> bjatkin/new-name rename", renames)
+	}
+}
+
+func TestSearchAggregatesPagingMetadataAcrossRepos(t *testing.T) {
+	stubA := &stubSearchClient{items: []githubSearchItem{{Name: "a1.go", Path: "a1.go"}, {Name: "a2.go", Path: "a2.go"}}}
+	stubB := &stubSearchClient{items: []githubSearchItem{{Name: "b.go", Path: "b.go"}}}
+
+	s := &searcher{
+		cfg: Config{Repos: []string{"bjatkin/repo-a", "bjatkin/repo-b"}, MaxContextLines: 10},
+		backends: &backendRouter{
+			def: stubA,
+			byRepo: map[string]codeSearchClient{
+				"bjatkin/repo-a": stubA,
+				"bjatkin/repo-b": stubB,
+			},
+		},
+		aliases:  &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}},
+		disabled: newRepoDisableStore(),
+		ab:       newABExperiment(),
+	}
+
+	_, _, page, _, err := s.Search("main", 5, false, 1, 1, "", "", false, false, "", "")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if page.Page != 1 || page.PerPage != 1 {
+		t.Errorf("page = %+v, want Page=1 PerPage=1", page)
+	}
+	if page.TotalCount != 3 {
+		t.Errorf("page.TotalCount = %d, want 3 (2 + 1, summed across repos)", page.TotalCount)
+	}
+	if !page.HasNext {
+		t.Error("page.HasNext = false, want true: repo-a's stubbed total (2) exceeds page*per_page (1)")
+	}
+}
+
+func TestSearchReportsCost(t *testing.T) {
+	stubA := &stubSearchClient{items: []githubSearchItem{{Name: "a.go", Path: "a.go"}}}
+	stubB := &stubSearchClient{items: []githubSearchItem{{Name: "b.go", Path: "b.go"}}}
+
+	s := &searcher{
+		cfg: Config{Repos: []string{"bjatkin/repo-a", "bjatkin/repo-b"}, MaxContextLines: 10},
+		backends: &backendRouter{
+			def: stubA,
+			byRepo: map[string]codeSearchClient{
+				"bjatkin/repo-a": stubA,
+				"bjatkin/repo-b": stubB,
+			},
+		},
+		aliases:  &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}},
+		disabled: newRepoDisableStore(),
+		ab:       newABExperiment(),
+	}
+
+	_, _, _, cost, err := s.Search("main", 5, false, 1, defaultSearchPerPage, "", "", false, false, "", "")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if cost.BackendCalls != 2 {
+		t.Errorf("cost.BackendCalls = %d, want 2 (one call per repo, both overrides)", cost.BackendCalls)
+	}
+	if cost.CacheHit {
+		t.Error("cost.CacheHit = true, want false: Search always computes fresh results")
+	}
+	if _, ok := cost.StageLatencyMS["backend"]; !ok {
+		t.Error("cost.StageLatencyMS missing \"backend\" stage")
+	}
+	if _, ok := cost.StageLatencyMS["rank"]; !ok {
+		t.Error("cost.StageLatencyMS missing \"rank\" stage")
+	}
+}
+
+func TestSearchRewritesDefaultBackendHTMLBase(t *testing.T) {
+	stub := &stubSearchClient{items: []githubSearchItem{
+		{Name: "main.go", Path: "main.go", HTMLURL: "https://internal-ghe-api.example.com/bjatkin/repo/blob/main/main.go"},
+	}}
+
+	s := &searcher{
+		cfg:      Config{Repos: []string{"bjatkin/repo"}, MaxContextLines: 10, GithubHTMLBaseURL: "https://ghe.example.com"},
+		backends: &backendRouter{def: stub, byRepo: map[string]codeSearchClient{}},
+		aliases:  &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}},
+		disabled: newRepoDisableStore(),
+		ab:       newABExperiment(),
+	}
+
+	results, _, _, _, err := s.Search("main", 5, false, 1, defaultSearchPerPage, "", "", false, false, "", "")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if want := "https://ghe.example.com/bjatkin/repo/blob/main/main.go"; results[0].URL != want {
+		t.Errorf("results[0].URL = %q, want %q", results[0].URL, want)
+	}
+}
+
+func TestSearchFansOutAcrossProvidersAndReportsWarnings(t *testing.T) {
+	glStub := &stubSearchClient{items: []githubSearchItem{{Name: "gl.go", Path: "gl.go", HTMLURL: "https://gitlab.example.com/o/r/-/blob/main/gl.go"}}}
+	bbStub := &stubSearchClient{err: errors.New("bitbucket unreachable")}
+
+	s := &searcher{
+		cfg: Config{Repos: []string{"o/gl-repo", "o/bb-repo"}, MaxContextLines: 10},
+		backends: &backendRouter{
+			def:        &stubSearchClient{},
+			byRepo:     map[string]codeSearchClient{"o/gl-repo": glStub, "o/bb-repo": bbStub},
+			kindByRepo: map[string]string{"o/gl-repo": backendGitlab, "o/bb-repo": backendBitbucket},
+		},
+		aliases:  &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}},
+		disabled: newRepoDisableStore(),
+		ab:       newABExperiment(),
+	}
+
+	results, _, _, cost, err := s.Search("main", 5, false, 1, defaultSearchPerPage, "", "", false, false, "", "")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result from the healthy provider, got %d", len(results))
+	}
+	if results[0].Provider != backendGitlab {
+		t.Errorf("results[0].Provider = %q, want %q", results[0].Provider, backendGitlab)
+	}
+
+	if len(cost.Warnings) != 2 {
+		t.Fatalf("expected 2 warnings (one per override repo), got %d: %+v", len(cost.Warnings), cost.Warnings)
+	}
+	var sawFailure bool
+	for _, w := range cost.Warnings {
+		if w.Repo == "o/bb-repo" {
+			sawFailure = true
+			if w.Provider != backendBitbucket {
+				t.Errorf("failing warning Provider = %q, want %q", w.Provider, backendBitbucket)
+			}
+			if w.Error == "" {
+				t.Error("expected the failing provider's warning to carry an Error")
+			}
+		}
+	}
+	if !sawFailure {
+		t.Error("expected a warning for the failing bitbucket repo")
+	}
+}
+
+func TestSearchScopesToGroup(t *testing.T) {
+	stubA := &stubSearchClient{items: []githubSearchItem{{Name: "a.go", Path: "a.go"}}}
+	stubB := &stubSearchClient{items: []githubSearchItem{{Name: "b.go", Path: "b.go"}}}
+
+	s := &searcher{
+		cfg: Config{
+			Repos:           []string{"bjatkin/repo-a", "bjatkin/repo-b"},
+			RepoGroups:      map[string][]string{"a-only": {"bjatkin/repo-a"}},
+			MaxContextLines: 10,
+		},
+		backends: &backendRouter{
+			def: stubA,
+			byRepo: map[string]codeSearchClient{
+				"bjatkin/repo-a": stubA,
+				"bjatkin/repo-b": stubB,
+			},
+		},
+		aliases:  &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}},
+		disabled: newRepoDisableStore(),
+		ab:       newABExperiment(),
+	}
+
+	results, _, _, _, err := s.Search("main", 5, false, 1, defaultSearchPerPage, "", "a-only", false, false, "", "")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].Path != "a.go" {
+		t.Errorf("results = %+v, want just repo-a's match", results)
+	}
+}
+
+func TestSearchAppendsForkAndArchivedQualifiers(t *testing.T) {
+	stub := &stubSearchClient{items: []githubSearchItem{{Name: "a.go", Path: "a.go"}}}
+
+	s := &searcher{
+		cfg: Config{
+			Repos:           []string{"bjatkin/repo-a"},
+			MaxContextLines: 10,
+		},
+		backends: &backendRouter{def: stub, byRepo: map[string]codeSearchClient{}},
+		aliases:  &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}},
+		disabled: newRepoDisableStore(),
+		ab:       newABExperiment(),
+	}
+
+	if _, _, _, _, err := s.Search("main", 5, false, 1, defaultSearchPerPage, "", "", true, true, "", ""); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if want := "main fork:false archived:false"; stub.gotTerm != want {
+		t.Errorf("gotTerm = %q, want %q", stub.gotTerm, want)
+	}
+}
+
+func TestSearchFiltersByLanguage(t *testing.T) {
+	stub := &stubSearchClient{items: []githubSearchItem{
+		{Name: "main.go", Path: "main.go"},
+		{Name: "readme.md", Path: "readme.md"},
+	}}
+
+	s := &searcher{
+		cfg: Config{
+			Repos:           []string{"bjatkin/repo"},
+			MaxContextLines: 10,
+		},
+		backends: &backendRouter{def: stub, byRepo: map[string]codeSearchClient{}},
+		aliases:  &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}},
+		disabled: newRepoDisableStore(),
+		ab:       newABExperiment(),
+	}
+
+	results, _, _, _, err := s.Search("main", 5, false, 1, defaultSearchPerPage, "", "", false, false, "go", "")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].Path != "main.go" {
+		t.Errorf("results = %+v, want just main.go", results)
+	}
+	if want := "main language:go"; stub.gotTerm != want {
+		t.Errorf("gotTerm = %q, want %q", stub.gotTerm, want)
+	}
+}
+
+func TestSearchFiltersByPathPrefix(t *testing.T) {
+	stub := &stubSearchClient{items: []githubSearchItem{
+		{Name: "main.go", Path: "cmd/main.go"},
+		{Name: "config.go", Path: "internal/config.go"},
+	}}
+
+	s := &searcher{
+		cfg: Config{
+			Repos:           []string{"bjatkin/repo"},
+			MaxContextLines: 10,
+		},
+		backends: &backendRouter{def: stub, byRepo: map[string]codeSearchClient{}},
+		aliases:  &repoAliasResolver{toRepo: map[string]string{}, toAlias: map[string]string{}},
+		disabled: newRepoDisableStore(),
+		ab:       newABExperiment(),
+	}
+
+	results, _, _, _, err := s.Search("main", 5, false, 1, defaultSearchPerPage, "", "", false, false, "", "cmd/")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].Path != "cmd/main.go" {
+		t.Errorf("results = %+v, want just cmd/main.go", results)
+	}
+	if want := "main path:cmd/"; stub.gotTerm != want {
+		t.Errorf("gotTerm = %q, want %q", stub.gotTerm, want)
+	}
+}
+
+func TestRewriteGithubHTMLHostKeepsPathAndQuery(t *testing.T) {
+	got := rewriteGithubHTMLHost("https://internal-api.example.com/bjatkin/repo/blob/main/main.go?plain=1", "https://ghe.example.com")
+	if want := "https://ghe.example.com/bjatkin/repo/blob/main/main.go?plain=1"; got != want {
+		t.Errorf("rewriteGithubHTMLHost() = %q, want %q", got, want)
+	}
+}
+
+func TestRewriteGithubHTMLHostNoopWhenBaseEmpty(t *testing.T) {
+	const original = "https://api.github.com/bjatkin/repo/blob/main/main.go"
+	if got := rewriteGithubHTMLHost(original, ""); got != original {
+		t.Errorf("rewriteGithubHTMLHost() = %q, want unchanged %q", got, original)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurityHeadersMiddlewareSetsDefaults(t *testing.T) {
+	handler := securityHeadersMiddleware(SecurityHeadersConfig{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+	}
+	if got := w.Header().Get("Content-Security-Policy"); got != defaultContentSecurityPolicy {
+		t.Errorf("Content-Security-Policy = %q, want %q", got, defaultContentSecurityPolicy)
+	}
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("expected no HSTS header over plain HTTP, got %q", got)
+	}
+}
+
+func TestSecurityHeadersMiddlewareSetsHSTSOverTLS(t *testing.T) {
+	handler := securityHeadersMiddleware(SecurityHeadersConfig{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Strict-Transport-Security"); got == "" {
+		t.Error("expected an HSTS header when X-Forwarded-Proto is https")
+	}
+}
+
+func TestSecurityHeadersMiddlewareRejectsTrace(t *testing.T) {
+	handler := securityHeadersMiddleware(SecurityHeadersConfig{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodTrace, "/search", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestSecurityHeadersMiddlewareDisabled(t *testing.T) {
+	handler := securityHeadersMiddleware(SecurityHeadersConfig{Disable: true}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodTrace, "/search", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d: disabled should let TRACE through untouched", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get("X-Content-Type-Options"); got != "" {
+		t.Errorf("expected no headers when disabled, got X-Content-Type-Options = %q", got)
+	}
+}
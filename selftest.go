@@ -0,0 +1,75 @@
+package main
+
+import "fmt"
+
+// SelftestResult is the outcome of one selftest check.
+type SelftestResult struct {
+	Name  string
+	OK    bool
+	Error string
+}
+
+// RunSelftest performs a live end-to-end check of cfg suited to
+// deploy-time smoke testing: it builds a searcher against the
+// configured backends, runs a trivial search, and round-trips the
+// result cache. Every check runs regardless of earlier failures (except
+// backend_init, which later checks depend on), so a single report
+// covers everything that's broken rather than just the first thing.
+func RunSelftest(cfg Config) []SelftestResult {
+	var results []SelftestResult
+
+	if len(cfg.Repos) == 0 {
+		results = append(results, SelftestResult{Name: "config", OK: false, Error: "no repos configured"})
+	} else {
+		results = append(results, SelftestResult{Name: "config", OK: true})
+	}
+
+	snap, err := newSearcher(cfg, nil, nil, nil)
+	if err != nil {
+		results = append(results, SelftestResult{Name: "backend_init", OK: false, Error: err.Error()})
+		return results
+	}
+	results = append(results, SelftestResult{Name: "backend_init", OK: true})
+
+	if _, err := snap.SearchLocal("selftest", false, false); err != nil {
+		results = append(results, SelftestResult{Name: "trivial_search", OK: false, Error: err.Error()})
+	} else {
+		results = append(results, SelftestResult{Name: "trivial_search", OK: true})
+	}
+
+	cache := newResultCache()
+	id, err := cache.Put([]Result{})
+	if err != nil {
+		results = append(results, SelftestResult{Name: "cache_store", OK: false, Error: err.Error()})
+	} else if _, ok := cache.Get(id); !ok {
+		results = append(results, SelftestResult{Name: "cache_store", OK: false, Error: "round-trip lookup failed"})
+	} else {
+		results = append(results, SelftestResult{Name: "cache_store", OK: true})
+	}
+
+	return results
+}
+
+// SelftestPassed reports whether every check in results succeeded.
+func SelftestPassed(results []SelftestResult) bool {
+	for _, r := range results {
+		if !r.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatSelftestReport renders results as a human-readable report, one
+// line per check, suited to printing from the selftest subcommand.
+func FormatSelftestReport(results []SelftestResult) string {
+	report := ""
+	for _, r := range results {
+		status := "ok"
+		if !r.OK {
+			status = fmt.Sprintf("FAIL: %s", r.Error)
+		}
+		report += fmt.Sprintf("%-16s %s\n", r.Name, status)
+	}
+	return report
+}
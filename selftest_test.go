@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestRunSelftestReportsNoReposConfigured(t *testing.T) {
+	results := RunSelftest(Config{MaxContextLines: 10, ArchiveMaxBytes: 1, LFSMode: lfsModePointer, CloneTimeout: "1s"})
+
+	names := map[string]bool{}
+	for _, r := range results {
+		names[r.Name] = r.OK
+	}
+
+	if ok, seen := names["config"]; !seen || ok {
+		t.Errorf("expected config check to fail with no repos configured, got seen=%v ok=%v", seen, ok)
+	}
+	if ok, seen := names["backend_init"]; !seen || !ok {
+		t.Errorf("expected backend_init to succeed, got seen=%v ok=%v", seen, ok)
+	}
+	if ok, seen := names["trivial_search"]; !seen || !ok {
+		t.Errorf("expected trivial_search to succeed with no repos to search, got seen=%v ok=%v", seen, ok)
+	}
+	if ok, seen := names["cache_store"]; !seen || !ok {
+		t.Errorf("expected cache_store to succeed, got seen=%v ok=%v", seen, ok)
+	}
+}
+
+func TestSelftestPassed(t *testing.T) {
+	if !SelftestPassed([]SelftestResult{{Name: "a", OK: true}}) {
+		t.Error("expected SelftestPassed to be true when every check passed")
+	}
+	if SelftestPassed([]SelftestResult{{Name: "a", OK: true}, {Name: "b", OK: false}}) {
+		t.Error("expected SelftestPassed to be false when a check failed")
+	}
+}
+
+func TestFormatSelftestReportIncludesFailureReason(t *testing.T) {
+	report := FormatSelftestReport([]SelftestResult{{Name: "config", OK: false, Error: "no repos configured"}})
+	if report == "" {
+		t.Fatal("expected a non-empty report")
+	}
+}
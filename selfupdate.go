@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultSelfUpdateRepo is the GitHub repo selfUpdateChecker checks
+// releases against when SelfUpdateCheckConfig.Repo is unset.
+const defaultSelfUpdateRepo = "bjatkin/gitSearch"
+
+// defaultSelfUpdateInterval is how often selfUpdateChecker re-checks
+// when SelfUpdateCheckConfig.Interval is unset.
+const defaultSelfUpdateInterval = 24 * time.Hour
+
+// selfUpdateChecker periodically compares the running appVersion
+// against this project's latest GitHub release, so operators see "a
+// newer version is available" in the logs and at GET /version without
+// anything being installed automatically.
+type selfUpdateChecker struct {
+	mu     sync.Mutex
+	latest string
+	err    string
+
+	client *http.Client
+
+	// fetchLatestReleaseURL overrides the GitHub API URL fetchLatestRelease
+	// hits, for tests to point at an httptest.Server instead of the real
+	// GitHub API. Empty uses the real API.
+	fetchLatestReleaseURL string
+}
+
+func newSelfUpdateChecker() *selfUpdateChecker {
+	return &selfUpdateChecker{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// githubRelease is the subset of GitHub's release object this checker
+// needs: https://docs.github.com/rest/releases/releases#get-the-latest-release
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+}
+
+// checkOnce fetches repo's latest release and records its tag name (or
+// the error, if the request failed), logging when a newer version than
+// appVersion is found.
+func (c *selfUpdateChecker) checkOnce(repo string) {
+	release, err := c.fetchLatestRelease(repo)
+
+	c.mu.Lock()
+	if err != nil {
+		c.err = err.Error()
+	} else {
+		c.err = ""
+		c.latest = release.TagName
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		log.Printf("self update check: %v", err)
+		return
+	}
+	if release.TagName != "" && release.TagName != appVersion {
+		log.Printf("new version available: %s (running %s)", release.TagName, appVersion)
+	}
+}
+
+func (c *selfUpdateChecker) fetchLatestRelease(repo string) (githubRelease, error) {
+	reqURL := c.fetchLatestReleaseURL
+	if reqURL == "" {
+		reqURL = fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
+	}
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return githubRelease{}, fmt.Errorf("build release check request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return githubRelease{}, fmt.Errorf("check latest release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return githubRelease{}, fmt.Errorf("check latest release: unexpected status %s", resp.Status)
+	}
+
+	var release githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return githubRelease{}, fmt.Errorf("decode latest release: %w", err)
+	}
+	return release, nil
+}
+
+// Status reports the latest release checkOnce has seen (empty until the
+// first check completes), whether it's newer than appVersion, and the
+// last check's error, if any.
+func (c *selfUpdateChecker) Status() (latest string, updateAvailable bool, checkErr string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latest, c.latest != "" && c.latest != appVersion, c.err
+}
+
+// startSelfUpdateChecker starts a background goroutine that calls
+// checkOnce immediately and then on cfg.SelfUpdateCheck.Interval,
+// returning nil (no goroutine started) when the check is disabled.
+func startSelfUpdateChecker(cfg Config) *selfUpdateChecker {
+	if !cfg.SelfUpdateCheck.Enabled {
+		return nil
+	}
+
+	repo := cfg.SelfUpdateCheck.Repo
+	if repo == "" {
+		repo = defaultSelfUpdateRepo
+	}
+	interval, err := time.ParseDuration(cfg.SelfUpdateCheck.Interval)
+	if err != nil || interval <= 0 {
+		interval = defaultSelfUpdateInterval
+	}
+
+	c := newSelfUpdateChecker()
+	go func() {
+		c.checkOnce(repo)
+		for range time.Tick(interval) {
+			c.checkOnce(repo)
+		}
+	}()
+	return c
+}
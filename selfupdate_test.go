@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSelfUpdateCheckerCheckOnceRecordsLatestRelease(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(githubRelease{TagName: "v9.9.9"})
+	}))
+	defer srv.Close()
+
+	c := newSelfUpdateChecker()
+	c.fetchLatestReleaseURL = srv.URL
+	c.checkOnce("owner/repo")
+
+	latest, updateAvailable, checkErr := c.Status()
+	if latest != "v9.9.9" {
+		t.Errorf("latest = %q, want v9.9.9", latest)
+	}
+	if !updateAvailable {
+		t.Error("expected an update to be available")
+	}
+	if checkErr != "" {
+		t.Errorf("checkErr = %q, want empty", checkErr)
+	}
+}
+
+func TestSelfUpdateCheckerCheckOnceRecordsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newSelfUpdateChecker()
+	c.fetchLatestReleaseURL = srv.URL
+	c.checkOnce("owner/repo")
+
+	_, updateAvailable, checkErr := c.Status()
+	if updateAvailable {
+		t.Error("expected no update available after a failed check")
+	}
+	if checkErr == "" {
+		t.Error("expected a non-empty check error")
+	}
+}
+
+func TestStartSelfUpdateCheckerDisabledByDefault(t *testing.T) {
+	if c := startSelfUpdateChecker(Config{}); c != nil {
+		t.Errorf("expected a nil checker when self_update_check.enabled is false, got %v", c)
+	}
+}
@@ -0,0 +1,1392 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// server serves the search HTTP API. searcher is held as an atomic
+// snapshot so a future config reload can swap it out without any lock,
+// and never leaves an in-flight search reading a half-updated config.
+type server struct {
+	searcher      atomic.Pointer[searcher]
+	cache         *resultCache
+	mirrors       *mirrorManager
+	jobs          *jobQueue
+	events        *eventBus
+	webhooks      *webhookInbox
+	shortlinks    *shortLinkStore
+	resthooks     *restHookStore
+	renames       *renameTracker
+	disabled      *repoDisableStore
+	imports       *repoImportStore
+	usage         *usageTracker
+	slo           *sloTracker
+	flags         *featureFlagStore
+	ab            *abExperiment
+	clicks        *clickTracker
+	store         StateStore
+	ipAccess      atomic.Pointer[ipAccessPolicy]
+	signingSecret atomic.Pointer[string]
+	nonces        *nonceCache
+	roles         atomic.Pointer[map[string]string]
+	errorSink     atomic.Pointer[errorReportSink]
+	scheduler     *syncScheduler
+	selfUpdate    *selfUpdateChecker
+	orgDiscovery  *orgRepoDiscoverer
+	rawRepos      []string
+}
+
+func newServer(cfg Config) (*server, error) {
+	s := &server{
+		cache:        newResultCache(),
+		mirrors:      newMirrorManager(),
+		events:       newEventBus(),
+		webhooks:     newWebhookInbox(),
+		shortlinks:   newShortLinkStore(),
+		resthooks:    newRESTHookStore(),
+		renames:      newRenameTracker(),
+		disabled:     newRepoDisableStore(),
+		imports:      newRepoImportStore(),
+		usage:        newUsageTracker(),
+		slo:          newSLOTracker(),
+		flags:        newFeatureFlagStore(cfg.Flags),
+		ab:           newABExperiment(),
+		clicks:       newClickTracker(),
+		nonces:       newNonceCache(),
+		scheduler:    newSyncScheduler(),
+		selfUpdate:   startSelfUpdateChecker(cfg),
+		orgDiscovery: newOrgRepoDiscoverer(),
+	}
+	s.rawRepos = cfg.Repos
+	if needsRepoDiscovery(cfg.Repos) {
+		if err := s.orgDiscovery.Refresh(cfg.Repos, cfg.GithubToken); err != nil {
+			log.Printf("org repo discovery: %v", err)
+		}
+		cfg.Repos = s.orgDiscovery.Expand(cfg.Repos)
+	}
+	if err := s.reload(cfg); err != nil {
+		return nil, err
+	}
+
+	telemetryInterval, _ := time.ParseDuration(cfg.TelemetryInterval)
+	go runTelemetry(cfg, s.usage, telemetryInterval)
+
+	if cfg.StatePersistPath != "" {
+		if err := validateStateBackend(cfg.StateBackend); err != nil {
+			return nil, err
+		}
+		if err := ensureStateDir(cfg.StatePersistPath); err != nil {
+			return nil, fmt.Errorf("prepare state persist path: %w", err)
+		}
+
+		switch {
+		case cfg.KMSProvider != "":
+			kms, err := newKMSProvider(cfg.KMSProvider, cfg)
+			if err != nil {
+				return nil, fmt.Errorf("init kms_provider: %w", err)
+			}
+			s.store = newEnvelopeFileStateStore(cfg.StatePersistPath, kms)
+		case len(cfg.StateEncryptionKeys) > 0:
+			keys, err := parseStateEncryptionKeys(cfg.StateEncryptionKeys)
+			if err != nil {
+				return nil, fmt.Errorf("parse state_encryption_keys: %w", err)
+			}
+			s.store = newEncryptedFileStateStore(cfg.StatePersistPath, keys)
+		default:
+			s.store = newFileStateStore(cfg.StatePersistPath)
+		}
+
+		bundle, err := s.store.Load()
+		if err != nil {
+			return nil, fmt.Errorf("load persisted state: %w", err)
+		}
+		if err := s.importState(bundle); err != nil {
+			return nil, fmt.Errorf("restore persisted state: %w", err)
+		}
+	}
+
+	searchEvents, _ := s.events.Subscribe()
+	go func() {
+		for evt := range searchEvents {
+			if payload, ok := evt.Data.(searchExecutedEvent); ok {
+				s.resthooks.NotifySearch(payload)
+			}
+		}
+	}()
+
+	s.jobs = newJobQueue(cfg.JobConcurrency, func(job *Job) error {
+		err := s.mirrors.Sync(s.searcher.Load().local, job.Repo)
+		if job.DeliveryID != "" {
+			s.webhooks.MarkProcessed(job.DeliveryID, err)
+		}
+		if err != nil {
+			s.events.Publish("index_job_failed", job)
+		} else {
+			s.events.Publish("index_job_finished", job)
+		}
+		return err
+	})
+	go s.scheduler.Run(func(repo string) {
+		s.jobs.Enqueue(repo, JobPriorityScheduled)
+	})
+
+	// Re-list any org:/user: entries on an interval, so a repo created
+	// after startup starts appearing in search results without editing
+	// the config. Skipped entirely for a config with no such entries.
+	if needsRepoDiscovery(s.rawRepos) {
+		interval, err := time.ParseDuration(cfg.OrgDiscoveryInterval)
+		if err != nil || interval <= 0 {
+			interval = defaultOrgDiscoveryInterval
+		}
+		go func() {
+			for range time.Tick(interval) {
+				if err := s.orgDiscovery.Refresh(s.rawRepos, s.searcher.Load().cfg.GithubToken); err != nil {
+					log.Printf("org repo discovery: %v", err)
+				}
+				next := s.searcher.Load().cfg
+				next.Repos = s.orgDiscovery.Expand(s.rawRepos)
+				if err := s.reload(next); err != nil {
+					log.Printf("org repo discovery: reload after refresh: %v", err)
+				}
+			}
+		}()
+	}
+	return s, nil
+}
+
+// reload compiles cfg into a new searcher and atomically swaps it in.
+func (s *server) reload(cfg Config) error {
+	searcher, err := newSearcher(cfg, s.disabled, s.imports, s.ab)
+	if err != nil {
+		return err
+	}
+	ipAccess, err := compileIPAccessPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	errorSink, err := newErrorReportSink(cfg)
+	if err != nil {
+		return err
+	}
+	if err := s.scheduler.Reconfigure(cfg); err != nil {
+		return err
+	}
+	s.searcher.Store(searcher)
+	s.usage.Reconfigure(cfg)
+	s.slo.Reconfigure(cfg)
+	s.ipAccess.Store(&ipAccess)
+	secret := cfg.ServiceSigningSecret
+	s.signingSecret.Store(&secret)
+	roles := cfg.Roles
+	s.roles.Store(&roles)
+	s.errorSink.Store(&errorSink)
+	return nil
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/search/hex", s.handleSearchHex)
+	mux.HandleFunc("/search/local", s.handleSearchLocal)
+	mux.HandleFunc("/symbols", s.handleSymbols)
+	mux.HandleFunc("/version", s.handleVersion)
+	mux.HandleFunc("/admin/mirrors", s.handleMirrors)
+	mux.HandleFunc("/admin/jobs", s.handleJobs)
+	mux.HandleFunc("/admin/events", s.handleEvents)
+	mux.HandleFunc("/webhooks/github", s.handleGithubWebhook)
+	mux.HandleFunc("/admin/webhooks", s.handleWebhooks)
+	mux.HandleFunc("/policy/check", s.handlePolicyCheck)
+	mux.HandleFunc("/integrations/backstage/search", s.handleBackstageSearch)
+	mux.HandleFunc("/integrations/vscode/search", s.handleVSCodeSearch)
+	mux.HandleFunc("/integrations/slack/command", s.handleSlackCommand)
+	mux.HandleFunc("/integrations/teams/message", s.handleTeamsMessage)
+	mux.HandleFunc("/r", s.handleShortLinkCreate)
+	mux.HandleFunc("/r/", s.handleShortLinkRedirect)
+	mux.HandleFunc("/hooks/subscribe", s.handleRESTHookSubscribe)
+	mux.HandleFunc("/admin/config", s.handleAdminConfig)
+	mux.HandleFunc("/admin/export", s.handleAdminExport)
+	mux.HandleFunc("/admin/import", s.handleAdminImport)
+	mux.HandleFunc("/admin/repos", s.handleAdminRepos)
+	mux.HandleFunc("/admin/repos/import", s.handleAdminReposImport)
+	mux.HandleFunc("/admin/usage", s.handleAdminUsage)
+	mux.HandleFunc("/admin/slo", s.handleAdminSLO)
+	mux.HandleFunc("/admin/purge", s.handleAdminPurge)
+	mux.HandleFunc("/admin/flags", s.handleAdminFlags)
+	mux.HandleFunc("/admin/ab_test", s.handleAdminABTest)
+	mux.HandleFunc("/admin/ab_test/outcome", s.handleAdminABTestOutcome)
+	mux.HandleFunc("/v1/click", s.handleClick)
+	if s.searcher.Load().cfg.PublicAPI {
+		mux.HandleFunc("/public/search", s.handlePublicSearch)
+	}
+	loadRoles := func() map[string]string {
+		if roles := s.roles.Load(); roles != nil {
+			return *roles
+		}
+		return nil
+	}
+	hardened := securityHeadersMiddleware(s.searcher.Load().cfg.SecurityHeaders, mux)
+	authed := ipAccessMiddleware(s.ipAccess.Load, authzMiddleware(loadRoles, requestSigningMiddleware(s.signingSecret.Load, s.nonces, hardened)))
+	return recoveryMiddleware(*s.errorSink.Load(), authed)
+}
+
+// searchResponse is the JSON body returned by /search. RequestID can be
+// passed back via the refine query parameter to drill down into these
+// results without re-querying the backends.
+type searchResponse struct {
+	RequestID string      `json:"request_id"`
+	Results   []Result    `json:"results,omitempty"`
+	Groups    []RepoGroup `json:"groups,omitempty"`
+	Facets    Facets      `json:"facets"`
+
+	// Page is populated only for responses backed by a paginated
+	// backend search (currently just /search); it is omitted entirely
+	// for callers like /search/local that don't page.
+	Page *SearchPage `json:"page,omitempty"`
+
+	// Cost is populated only for a /search request that opted in with
+	// meta=1, since gathering it (harmless as it is) still isn't free
+	// for a caller that doesn't want it in every response body.
+	Cost *SearchCost `json:"cost,omitempty"`
+}
+
+// searchExecutedEvent is published on the event bus after every /search
+// request. restHookStore listens for it to fire "new search match" REST
+// hooks. RankArm is the RankExperiment arm that scored Results, so an
+// /admin/events subscriber can correlate which arm served each query
+// without needing meta=1 on the search itself.
+type searchExecutedEvent struct {
+	Term    string   `json:"term"`
+	Results []Result `json:"results"`
+	RankArm string   `json:"rank_arm"`
+}
+
+func (s *server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	term := r.URL.Query().Get("q")
+	if err := validateSearchTerm(term); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if refineID := r.URL.Query().Get("refine"); refineID != "" {
+		s.handleRefine(w, r, refineID, term)
+		return
+	}
+
+	snap := s.searcher.Load()
+	contextLines := snap.cfg.MaxContextLines
+	if raw := r.URL.Query().Get("context_lines"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid context_lines parameter", http.StatusBadRequest)
+			return
+		}
+		contextLines = n
+	}
+
+	explain := r.URL.Query().Get("explain") == "1"
+
+	page, perPage := 1, defaultSearchPerPage
+	if raw := r.URL.Query().Get("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "invalid page parameter", http.StatusBadRequest)
+			return
+		}
+		page = n
+	}
+	if raw := r.URL.Query().Get("per_page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "invalid per_page parameter", http.StatusBadRequest)
+			return
+		}
+		perPage = n
+	}
+	if err := validateSearchPage(page, perPage); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	group := r.URL.Query().Get("group")
+
+	excludeForks := snap.cfg.ExcludeForks
+	if raw := r.URL.Query().Get("exclude_forks"); raw != "" {
+		excludeForks = raw == "1"
+	}
+	excludeArchived := snap.cfg.ExcludeArchived
+	if raw := r.URL.Query().Get("exclude_archived"); raw != "" {
+		excludeArchived = raw == "1"
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang != "" && !isKnownLanguage(lang) {
+		http.Error(w, fmt.Sprintf("unknown lang %q", lang), http.StatusBadRequest)
+		return
+	}
+
+	pathFilter := r.URL.Query().Get("path")
+
+	searchStart := time.Now()
+	results, renames, pageMeta, costMeta, err := snap.Search(term, contextLines, explain, page, perPage, apiKeyFromRequest(r), group, excludeForks, excludeArchived, lang, pathFilter)
+	s.slo.Record(err == nil, time.Since(searchStart))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	for _, rename := range renames {
+		if s.renames.Record(rename.OldRepo, rename.NewRepo) {
+			s.events.Publish("repo_renamed", rename)
+		}
+	}
+
+	s.events.Publish("search_executed", searchExecutedEvent{Term: term, Results: results, RankArm: costMeta.RankArm})
+	s.usage.Record(apiKeyFromRequest(r), costMeta.GithubRateLimitSpent)
+
+	for i := range results {
+		id, err := s.clicks.Register(results[i].URL, costMeta.RankArm)
+		if err != nil {
+			log.Printf("search: register click id: %v", err)
+			continue
+		}
+		results[i].ID = id
+	}
+
+	var cost *SearchCost
+	if r.URL.Query().Get("meta") == "1" {
+		cost = &costMeta
+	}
+	s.respond(w, r, results, &pageMeta, cost)
+}
+
+// handleSearchHex runs a binary-safe search of the local backend's
+// clones for a hex-encoded byte pattern, e.g. /search/hex?pattern=DEADBEEF.
+// An optional ref query parameter (a SHA or tag) searches the repos as
+// of that historical ref instead of their default branch.
+func (s *server) handleSearchHex(w http.ResponseWriter, r *http.Request) {
+	pattern := r.URL.Query().Get("pattern")
+	if pattern == "" {
+		http.Error(w, "missing required query parameter: pattern", http.StatusBadRequest)
+		return
+	}
+
+	snap := s.searcher.Load()
+	var matches []HexMatch
+	var err error
+	if ref := r.URL.Query().Get("ref"); ref != "" {
+		matches, err = snap.SearchHexAtRef(pattern, ref)
+	} else {
+		matches, err = snap.SearchHex(pattern)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.writeJSON(w, struct {
+		Matches []HexMatch `json:"matches"`
+	}{Matches: matches})
+}
+
+// handleSearchLocal runs a search against the local backend's clones
+// rather than GitHub's code search API, flattening structured and
+// minified files first so matches land on meaningful lines. An optional
+// ref query parameter (a SHA or tag) searches the repos as of that
+// historical ref instead of their default branch. An optional
+// first_introduced=1 additionally computes, per result, the commit and
+// date the matching term was first introduced at via a pickaxe search.
+// An optional owners=1 additionally computes each result's top
+// committers via git shortlog, complementing CODEOWNERS data for repos
+// that don't maintain that file.
+//
+// An optional regex=1 treats q as a regular expression instead of a
+// literal substring - GitHub's code search API has no equivalent, so
+// this is one of the local index's key differentiators. The pattern is
+// compiled safely (see compileSearchRegex: length-limited, and the scan
+// itself is time-boxed by Config.RegexSearchTimeout) rather than handed
+// to regexp.Compile unbounded. Regex mode doesn't support
+// first_introduced or owners, both of which assume a literal pickaxe
+// term.
+func (s *server) handleSearchLocal(w http.ResponseWriter, r *http.Request) {
+	term := r.URL.Query().Get("q")
+	if err := validateSearchTerm(term); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snap := s.searcher.Load()
+	ref := r.URL.Query().Get("ref")
+	var results []Result
+	var err error
+
+	if r.URL.Query().Get("regex") == "1" {
+		if ref != "" {
+			results, err = snap.SearchLocalRegexAtRef(term, ref)
+		} else {
+			results, err = snap.SearchLocalRegex(term)
+		}
+	} else {
+		firstIntroduced := r.URL.Query().Get("first_introduced") == "1"
+		owners := r.URL.Query().Get("owners") == "1"
+		if ref != "" {
+			results, err = snap.SearchLocalAtRef(term, ref, firstIntroduced, owners)
+		} else {
+			results, err = snap.SearchLocal(term, firstIntroduced, owners)
+		}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	s.respond(w, r, results, nil, nil)
+}
+
+// handleSymbols answers GET /symbols?q=... with every function, type,
+// class, or other named definition (across every configured repo's
+// local clone) whose name contains q, case-insensitively. An optional
+// repo query parameter narrows results to one repo. Code search is
+// often really symbol lookup, which the GitHub code search API can't
+// answer directly; this is backed by universal-ctags rather than that
+// API.
+func (s *server) handleSymbols(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		http.Error(w, "missing required query parameter: q", http.StatusBadRequest)
+		return
+	}
+
+	snap := s.searcher.Load()
+	symbols, err := snap.SearchSymbols(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if repo := r.URL.Query().Get("repo"); repo != "" {
+		filtered := symbols[:0]
+		for _, sym := range symbols {
+			if sym.Repo == repo {
+				filtered = append(filtered, sym)
+			}
+		}
+		symbols = filtered
+	}
+
+	s.writeJSON(w, struct {
+		Symbols []Symbol `json:"symbols"`
+	}{Symbols: symbols})
+}
+
+// handleVersion answers GET /version with this build's version, commit,
+// build date, and Go toolchain version, so support can correlate a
+// deployment's behavior with the exact release running it.
+func (s *server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	info := currentVersionInfo()
+	if s.selfUpdate != nil {
+		info.LatestRelease, info.UpdateAvailable, info.SelfUpdateCheckError = s.selfUpdate.Status()
+	}
+	s.writeJSON(w, info)
+}
+
+// handleBackstageSearch runs a repo-scoped search shaped for the
+// Backstage code search plugin. The entity's repo (and, for a "tree"
+// URL, its subpath) is derived from a backstage.io/source-location
+// annotation value passed as the source_location query parameter, e.g.
+// "url:https://github.com/owner/repo/tree/main/service".
+func (s *server) handleBackstageSearch(w http.ResponseWriter, r *http.Request) {
+	term := r.URL.Query().Get("q")
+	if err := validateSearchTerm(term); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sourceLocation := r.URL.Query().Get("source_location")
+	if sourceLocation == "" {
+		http.Error(w, "missing required query parameter: source_location", http.StatusBadRequest)
+		return
+	}
+
+	repo, path, err := parseSourceLocation(sourceLocation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snap := s.searcher.Load()
+	if !slices.Contains(snap.cfg.Repos, repo) {
+		http.Error(w, fmt.Sprintf("repo not configured: %s", repo), http.StatusNotFound)
+		return
+	}
+
+	results, err := snap.SearchRepo(repo, term)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	s.writeJSON(w, struct {
+		Entities []BackstageEntity `json:"entities"`
+	}{Entities: buildBackstageEntities(results, path)})
+}
+
+// handleVSCodeSearch runs a search and reshapes the results for a thin VS
+// Code extension: the repeatable workspace query parameter
+// (repo=/local/path) tells it where each repo is checked out locally, so
+// a result under a mapped repo gets a file:// URI the extension can open
+// directly, instead of only a remote GitHub URL.
+func (s *server) handleVSCodeSearch(w http.ResponseWriter, r *http.Request) {
+	term := r.URL.Query().Get("q")
+	if err := validateSearchTerm(term); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workspace, err := parseWorkspaceMapping(r.URL.Query()["workspace"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang != "" && !isKnownLanguage(lang) {
+		http.Error(w, fmt.Sprintf("unknown lang %q", lang), http.StatusBadRequest)
+		return
+	}
+
+	snap := s.searcher.Load()
+	results, _, _, _, err := snap.Search(term, snap.cfg.MaxContextLines, false, 1, defaultSearchPerPage, apiKeyFromRequest(r), "", snap.cfg.ExcludeForks, snap.cfg.ExcludeArchived, lang, r.URL.Query().Get("path"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	s.writeJSON(w, struct {
+		Results []VSCodeResult `json:"results"`
+	}{Results: buildVSCodeResults(results, workspace)})
+}
+
+// handleSlackCommand accepts a Slack slash-command payload (e.g.
+// "/codesearch term"), verifies its signature, runs the search against
+// the local backend, and responds with a Block Kit formatted message.
+func (s *server) handleSlackCommand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "read request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snap := s.searcher.Load()
+	if snap.cfg.SlackSigningSecret != "" {
+		err := verifySlackSignature(
+			snap.cfg.SlackSigningSecret,
+			r.Header.Get("X-Slack-Request-Timestamp"),
+			r.Header.Get("X-Slack-Signature"),
+			body,
+		)
+		if err != nil {
+			http.Error(w, "invalid signature: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	term := values.Get("text")
+	if err := validateSearchTerm(term); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := snap.SearchLocal(term, false, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	s.writeJSON(w, buildSlackResponse(term, results))
+}
+
+// handleTeamsMessage accepts a Microsoft Teams Bot Framework Activity,
+// runs its text as a search, and responds with an Activity carrying the
+// results as an Adaptive Card attachment.
+func (s *server) handleTeamsMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var activity teamsActivity
+	if err := json.NewDecoder(r.Body).Decode(&activity); err != nil {
+		http.Error(w, "invalid activity payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	term := activity.Text
+	if err := validateSearchTerm(term); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := s.searcher.Load().SearchLocal(term, false, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	s.writeJSON(w, buildTeamsResponse(term, results))
+}
+
+// handleShortLinkCreate takes a long result URL via the url query
+// parameter and returns a short /r/{id} link that redirects to it, for
+// chat integrations and emails that need a compact, trackable link.
+// title and snippet are optional (e.g. a result's repo/path and matched
+// line) and, when given, are rendered as an Open Graph preview card when
+// the short link is unfurled (see handleShortLinkRedirect).
+func (s *server) handleShortLinkCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	target := r.URL.Query().Get("url")
+	if target == "" {
+		http.Error(w, "missing required query parameter: url", http.StatusBadRequest)
+		return
+	}
+
+	id, err := s.shortlinks.Create(target, r.URL.Query().Get("title"), r.URL.Query().Get("snippet"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.persistState()
+
+	s.writeJSON(w, struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}{ID: id, URL: "/r/" + id})
+}
+
+// handleShortLinkRedirect resolves a short link created by
+// handleShortLinkCreate and sends the caller on to the long URL it
+// stands for, recording a hit for click tracking. A link with a title
+// or snippet is served as a small HTML page carrying Open Graph
+// metadata plus a meta-refresh to the target (see ogcard.go), so a chat
+// app unfurling the link shows a real preview card instead of a bare
+// URL; a link with neither is a plain redirect.
+func (s *server) handleShortLinkRedirect(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/r/")
+	if id == "" {
+		http.Error(w, "missing short link id", http.StatusBadRequest)
+		return
+	}
+
+	target, title, snippet, ok := s.shortlinks.Resolve(id)
+	if !ok {
+		http.Error(w, "unknown or expired short link", http.StatusNotFound)
+		return
+	}
+
+	if title == "" && snippet == "" {
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writeOGCard(w, target, title, snippet)
+}
+
+// restHookSubscribeRequest is the JSON body POSTed to /hooks/subscribe,
+// matching the shape Zapier/IFTTT REST Hooks send.
+type restHookSubscribeRequest struct {
+	Event     string `json:"event"`
+	Term      string `json:"term"`
+	TargetURL string `json:"target_url"`
+}
+
+// handleRESTHookSubscribe implements the Zapier/IFTTT REST Hooks
+// contract: GET lists active subscriptions, POST subscribes a
+// target_url to an event (currently only "new_search_match"), and
+// DELETE with an id query parameter unsubscribes it.
+func (s *server) handleRESTHookSubscribe(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, struct {
+			Subscriptions []RESTHookSubscription `json:"subscriptions"`
+		}{Subscriptions: s.resthooks.List()})
+
+	case http.MethodPost:
+		var req restHookSubscribeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sub, err := s.resthooks.Subscribe(req.Event, req.Term, req.TargetURL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.persistState()
+		s.writeJSON(w, sub)
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			http.Error(w, "missing required query parameter: id", http.StatusBadRequest)
+			return
+		}
+		if !s.resthooks.Unsubscribe(id) {
+			http.Error(w, "unknown subscription id", http.StatusNotFound)
+			return
+		}
+		s.persistState()
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePublicSearch serves a read-only, CDN-cacheable subset of
+// /search for public open-source deployments: no request ID, refine, or
+// event publishing, just the results for a fully URL-determined query,
+// tagged for long-lived caching.
+func (s *server) handlePublicSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	term := r.URL.Query().Get("q")
+	if err := validateSearchTerm(term); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := s.searcher.Load().SearchLocal(term, false, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	writePublicCacheHeaders(w, resultRepos(results))
+	s.writeJSON(w, publicSearchResponse{Results: results, Facets: buildFacets(results)})
+}
+
+// handleAdminConfig serves the fully merged effective configuration
+// (config file plus applied defaults) with secrets redacted, so
+// operators can verify what the service actually loaded.
+func (s *server) handleAdminConfig(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, s.searcher.Load().cfg.Redacted())
+}
+
+// handleAdminExport serves the full set of this service's runtime state
+// (short links, REST hook subscriptions, disabled repos, imported
+// repos, and detected renames) as a single versioned JSON bundle, for
+// migrating an accumulated deployment's state to a fresh instance via
+// POST /admin/import there.
+func (s *server) handleAdminExport(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, s.exportState())
+}
+
+// handleAdminImport restores a bundle produced by GET /admin/export,
+// merging it into this instance's existing runtime state rather than
+// replacing it.
+func (s *server) handleAdminImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var bundle StateBundle
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		http.Error(w, "invalid state bundle: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.importState(bundle); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.persistState()
+
+	s.writeJSON(w, s.exportState())
+}
+
+// handleAdminRepos serves the list of currently disabled repos on GET,
+// and on POST disables or re-enables one repo via the repo and action
+// query parameters. Disabling a repo hides it from search results
+// immediately without touching its local index, useful during incident
+// response. An optional until query parameter (RFC3339) schedules an
+// automatic re-enable; omitted, the repo stays disabled until an
+// explicit enable call.
+func (s *server) handleAdminRepos(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.writeJSON(w, struct {
+			Disabled []DisabledRepo `json:"disabled"`
+		}{Disabled: s.disabled.List()})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	repo := s.searcher.Load().aliases.ResolveRepo(r.URL.Query().Get("repo"))
+	if repo == "" {
+		http.Error(w, "missing required query parameter: repo", http.StatusBadRequest)
+		return
+	}
+
+	switch action := r.URL.Query().Get("action"); action {
+	case "disable":
+		var until time.Time
+		if raw := r.URL.Query().Get("until"); raw != "" {
+			var err error
+			until, err = time.Parse(time.RFC3339, raw)
+			if err != nil {
+				http.Error(w, "invalid until parameter: must be RFC3339", http.StatusBadRequest)
+				return
+			}
+		}
+		s.disabled.Disable(repo, r.URL.Query().Get("reason"), until)
+		s.events.Publish("repo_disabled", DisabledRepo{Repo: repo, Reason: r.URL.Query().Get("reason"), Until: until})
+		s.persistState()
+	case "enable":
+		s.disabled.Enable(repo)
+		s.events.Publish("repo_enabled", repo)
+		s.persistState()
+	default:
+		http.Error(w, "invalid action parameter: must be disable or enable", http.StatusBadRequest)
+		return
+	}
+
+	s.writeJSON(w, struct {
+		Disabled []DisabledRepo `json:"disabled"`
+	}{Disabled: s.disabled.List()})
+}
+
+// handleAdminReposImport bulk-imports repos from a CSV or JSON export
+// (e.g. from an asset inventory system) into the active configuration.
+// The format query parameter selects "csv" or "json"; the request body
+// is the export itself. Imported repos are merged with whatever the
+// config file already lists and survive later config file reloads,
+// though restarting the process without re-importing forgets them,
+// since they're never written back to the config file.
+func (s *server) handleAdminReposImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "read request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repos, err := ParseRepoImport(body, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snap := s.searcher.Load()
+	result, err := s.imports.Merge(repos, snap.cfg.Repos)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(result.Added) > 0 {
+		if err := s.reload(snap.cfg); err != nil {
+			http.Error(w, "reload after import: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.events.Publish("repos_imported", result)
+		s.persistState()
+	}
+
+	s.writeJSON(w, result)
+}
+
+// handleAdminUsage serves daily/monthly search volume, GitHub rate-limit
+// consumption, and top API-key consumers, so an operator running this
+// service for several teams can chargeback/showback usage. See
+// apiKeyFromRequest for how a caller identifies itself.
+func (s *server) handleAdminUsage(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, s.usage.Report())
+}
+
+// handleAdminSLO serves each config-defined SLO's live standing (see
+// SLOConfig, sloTracker): its actual availability against its target
+// over its window, and how fast its error budget is burning, so an
+// operator gets early warning before users complain instead of finding
+// out from a support ticket.
+func (s *server) handleAdminSLO(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, struct {
+		SLOs []SLOStatus `json:"slos"`
+	}{SLOs: s.slo.Report()})
+}
+
+// handleAdminPurge deletes usage/query history on demand (see
+// usageTracker.Purge), for a deployment that needs to honor a
+// data-retention request faster than Config.Retention.UsageDays would
+// get to it on its own. api_key, before, or both must be given: api_key
+// restricts the purge to that key, before (an RFC 3339 timestamp)
+// restricts it to history strictly before that time.
+func (s *server) handleAdminPurge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	apiKey := r.URL.Query().Get("api_key")
+	var before time.Time
+	if raw := r.URL.Query().Get("before"); raw != "" {
+		var err error
+		before, err = time.Parse(time.RFC3339, raw)
+		if err != nil {
+			http.Error(w, "invalid before parameter: must be RFC 3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if apiKey == "" && before.IsZero() {
+		http.Error(w, "missing required query parameter: api_key, before, or both", http.StatusBadRequest)
+		return
+	}
+
+	purged := s.usage.Purge(apiKey, before)
+	s.events.Publish("usage_purged", struct {
+		APIKey string `json:"api_key,omitempty"`
+		Before string `json:"before,omitempty"`
+		Purged int    `json:"purged"`
+	}{APIKey: apiKey, Before: r.URL.Query().Get("before"), Purged: purged})
+
+	s.writeJSON(w, struct {
+		Purged int `json:"purged"`
+	}{Purged: purged})
+}
+
+// handleAdminFlags serves the current state of every feature flag on
+// GET, and on POST enables or disables one via the flag and action
+// query parameters, so an experimental behavior can be rolled out (or
+// rolled back) without a config change and restart.
+func (s *server) handleAdminFlags(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.writeJSON(w, struct {
+			Flags map[string]bool `json:"flags"`
+		}{Flags: s.flags.List()})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flag := r.URL.Query().Get("flag")
+	if flag == "" {
+		http.Error(w, "missing required query parameter: flag", http.StatusBadRequest)
+		return
+	}
+
+	switch action := r.URL.Query().Get("action"); action {
+	case "enable":
+		s.flags.Set(flag, true)
+	case "disable":
+		s.flags.Set(flag, false)
+	default:
+		http.Error(w, "invalid action parameter: must be enable or disable", http.StatusBadRequest)
+		return
+	}
+
+	s.events.Publish("flag_changed", struct {
+		Flag   string `json:"flag"`
+		Action string `json:"action"`
+	}{Flag: flag, Action: r.URL.Query().Get("action")})
+	s.persistState()
+
+	s.writeJSON(w, struct {
+		Flags map[string]bool `json:"flags"`
+	}{Flags: s.flags.List()})
+}
+
+// handleAdminABTest serves the current rank_experiment's assignment and
+// outcome counts, so operators can compare how arm a and arm b are
+// performing without querying the event stream by hand.
+func (s *server) handleAdminABTest(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, s.ab.Report())
+}
+
+// handleAdminABTestOutcome records one outcome (e.g. a click-through or
+// a piece of explicit feedback) against a rank_experiment arm via the
+// required arm and outcome query parameters, so a caller with its own
+// notion of engagement (a redirect endpoint, a feedback widget) can feed
+// it back into the experiment.
+func (s *server) handleAdminABTestOutcome(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	arm := r.URL.Query().Get("arm")
+	outcome := r.URL.Query().Get("outcome")
+	if arm == "" || outcome == "" {
+		http.Error(w, "missing required query parameters: arm, outcome", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.ab.RecordOutcome(arm, outcome); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.writeJSON(w, s.ab.Report())
+}
+
+// clickedEvent is published on the event bus after a click-through
+// redirect, so relevance analytics can be built from the event stream
+// without a dedicated query endpoint.
+type clickedEvent struct {
+	ResultID string `json:"result_id"`
+	URL      string `json:"url"`
+	RankArm  string `json:"rank_arm,omitempty"`
+}
+
+// handleClick records a search result's selection and 302-redirects to
+// its URL. result_id must be one issued in a prior /search response's
+// results[].id; the caller follows that link instead of the result's
+// own URL directly, so the click is counted before the browser leaves.
+// If the result was scored by a RankExperiment arm, the click also
+// counts as that arm's outcome.
+func (s *server) handleClick(w http.ResponseWriter, r *http.Request) {
+	resultID := r.URL.Query().Get("result_id")
+	if resultID == "" {
+		http.Error(w, "missing required query parameter: result_id", http.StatusBadRequest)
+		return
+	}
+
+	url, rankArm, ok := s.clicks.Resolve(resultID)
+	if !ok {
+		http.Error(w, "unknown result_id", http.StatusNotFound)
+		return
+	}
+
+	if rankArm != "" {
+		if err := s.ab.RecordOutcome(rankArm, "click"); err != nil {
+			log.Printf("click: record ab outcome: %v", err)
+		}
+	}
+	s.events.Publish("result_clicked", clickedEvent{ResultID: resultID, URL: url, RankArm: rankArm})
+
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// handleMirrors serves the local backend's per-repo clone status on GET,
+// and on POST triggers a sync, pause, or resume for one repo via the
+// action and repo query parameters.
+func (s *server) handleMirrors(w http.ResponseWriter, r *http.Request) {
+	snap := s.searcher.Load()
+
+	if r.Method == http.MethodGet {
+		s.writeJSON(w, struct {
+			Mirrors []MirrorStatus `json:"mirrors"`
+		}{Mirrors: s.mirrors.List(snap.local, snap.cfg.Repos)})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	repo := snap.aliases.ResolveRepo(r.URL.Query().Get("repo"))
+	if repo == "" {
+		http.Error(w, "missing required query parameter: repo", http.StatusBadRequest)
+		return
+	}
+
+	switch action := r.URL.Query().Get("action"); action {
+	case "sync":
+		if err := s.mirrors.Sync(snap.local, repo); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	case "pause":
+		s.mirrors.SetPaused(repo, true)
+	case "resume":
+		s.mirrors.SetPaused(repo, false)
+	default:
+		http.Error(w, "invalid action parameter: must be sync, pause, or resume", http.StatusBadRequest)
+		return
+	}
+
+	s.writeJSON(w, *s.mirrors.statusFor(repo))
+}
+
+// jobPriorityByName maps the priority query parameter to a JobPriority.
+var jobPriorityByName = map[string]JobPriority{
+	"webhook":   JobPriorityWebhook,
+	"scheduled": JobPriorityScheduled,
+	"rebuild":   JobPriorityRebuild,
+}
+
+// handleJobs serves the index job queue's state on GET, and on POST
+// enqueues a new sync job for the repo and priority query parameters.
+func (s *server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.writeJSON(w, struct {
+			Jobs []Job `json:"jobs"`
+		}{Jobs: s.jobs.List()})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	repo := s.searcher.Load().aliases.ResolveRepo(r.URL.Query().Get("repo"))
+	if repo == "" {
+		http.Error(w, "missing required query parameter: repo", http.StatusBadRequest)
+		return
+	}
+
+	priority := JobPriorityScheduled
+	if raw := r.URL.Query().Get("priority"); raw != "" {
+		p, ok := jobPriorityByName[raw]
+		if !ok {
+			http.Error(w, "invalid priority parameter: must be webhook, scheduled, or rebuild", http.StatusBadRequest)
+			return
+		}
+		priority = p
+	}
+
+	job, err := s.jobs.Enqueue(repo, priority)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, job)
+}
+
+// handleEvents streams the service's activity as server-sent events:
+// searches executed, index jobs finishing, and the like. The connection
+// stays open until the client disconnects.
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	sub, unsubscribe := s.events.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\n", event.Type)
+			payload, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", payload)
+			flusher.Flush()
+		}
+	}
+}
+
+// handleGithubWebhook accepts a GitHub push webhook, records the
+// delivery, and enqueues a high-priority index job for the pushed repo.
+// When github_webhook_secret is configured, the delivery's
+// X-Hub-Signature-256 header is verified first and an unsigned or
+// mis-signed request is rejected before it's recorded.
+func (s *server) handleGithubWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if secret := s.searcher.Load().cfg.GithubWebhookSecret; secret != "" {
+		if err := verifyGithubWebhookSignature(secret, r.Header.Get("X-Hub-Signature-256"), payload); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	delivery, err := s.webhooks.Receive(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.jobs.EnqueueForDelivery(delivery.Repo, JobPriorityWebhook, delivery.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, delivery)
+}
+
+// handleWebhooks serves recorded webhook deliveries on GET, and on POST
+// replays one (re-enqueuing its index job) via the id query parameter.
+func (s *server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.writeJSON(w, struct {
+			Deliveries []WebhookDelivery `json:"deliveries"`
+		}{Deliveries: s.webhooks.List()})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	delivery, ok := s.webhooks.Get(id)
+	if !ok {
+		http.Error(w, "unknown webhook delivery id", http.StatusNotFound)
+		return
+	}
+
+	if _, err := s.jobs.EnqueueForDelivery(delivery.Repo, JobPriorityWebhook, delivery.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, delivery)
+}
+
+// handlePolicyCheck scans every configured repo's local clone for
+// hardcoded secrets and other policy violations, returning the findings
+// as JSON by default or as SARIF with format=sarif for upload to GitHub
+// code scanning.
+func (s *server) handlePolicyCheck(w http.ResponseWriter, r *http.Request) {
+	findings, err := s.searcher.Load().CheckPolicy()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "sarif":
+		s.writeJSON(w, buildSARIF(findings))
+	case "junit":
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		if err := xml.NewEncoder(w).Encode(buildJUnit(findings)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	default:
+		s.writeJSON(w, struct {
+			Findings []PolicyFinding `json:"findings"`
+		}{Findings: findings})
+	}
+}
+
+func (s *server) handleRefine(w http.ResponseWriter, r *http.Request, refineID, term string) {
+	cached, ok := s.cache.Get(refineID)
+	if !ok {
+		http.Error(w, "unknown or expired refine request id", http.StatusNotFound)
+		return
+	}
+
+	var cost *SearchCost
+	if r.URL.Query().Get("meta") == "1" {
+		cost = &SearchCost{CacheHit: true}
+	}
+	s.respond(w, r, refineResults(cached, term), nil, cost)
+}
+
+// respond caches results and writes them as a searchResponse, honoring
+// the group_by, repo_cap, and format query parameters. page and cost are
+// included in the response as-is when non-nil; callers that don't
+// paginate their backend search, or don't want cost reported, pass nil.
+func (s *server) respond(w http.ResponseWriter, r *http.Request, results []Result, page *SearchPage, cost *SearchCost) {
+	id, err := s.cache.Put(results)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "gha":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writeGHAFormat(w, results)
+		return
+	case "vimgrep":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writeVimgrepFormat(w, results)
+		return
+	}
+
+	resp := searchResponse{RequestID: id, Facets: buildFacets(results), Page: page, Cost: cost}
+
+	if r.URL.Query().Get("group_by") == "repo" {
+		repoCap := defaultGroupByRepoCap
+		if raw := r.URL.Query().Get("repo_cap"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid repo_cap parameter", http.StatusBadRequest)
+				return
+			}
+			repoCap = n
+		}
+		resp.Groups = groupByRepo(results, repoCap)
+	} else {
+		resp.Results = results
+	}
+
+	s.writeJSON(w, resp)
+}
+
+func (s *server) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
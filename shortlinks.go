@@ -0,0 +1,115 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// shortLinkEntry is a single deep link kept behind a short /r/{id} URL.
+// Title and Snippet are optional, caller-supplied context (e.g. a
+// result's repo/path and matched line) rendered as an Open Graph
+// preview card when the link is unfurled (see ogcard.go).
+type shortLinkEntry struct {
+	url     string
+	title   string
+	snippet string
+	hits    int
+}
+
+// shortLinkStore maps short IDs to long result URLs, so chat
+// integrations and emails can embed a compact link that redirects to
+// the real (often very long) GitHub URL, and tracks how many times each
+// one was followed.
+type shortLinkStore struct {
+	mu      sync.Mutex
+	entries map[string]*shortLinkEntry
+}
+
+func newShortLinkStore() *shortLinkStore {
+	return &shortLinkStore{entries: map[string]*shortLinkEntry{}}
+}
+
+// Create stores url, with optional title/snippet for the Open Graph
+// preview card an unfurl of the short link renders, and returns a short
+// ID that resolves back to it via Resolve.
+func (s *shortLinkStore) Create(url, title, snippet string) (string, error) {
+	id, err := newShortLinkID()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[id] = &shortLinkEntry{url: url, title: title, snippet: snippet}
+	return id, nil
+}
+
+// Resolve returns the URL, title and snippet id was created for,
+// recording a hit, or ok=false if id is unknown.
+func (s *shortLinkStore) Resolve(id string) (url, title, snippet string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return "", "", "", false
+	}
+	entry.hits++
+	return entry.url, entry.title, entry.snippet, true
+}
+
+// Hits returns how many times id has been resolved, or ok=false if id
+// is unknown.
+func (s *shortLinkStore) Hits(id string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return 0, false
+	}
+	return entry.hits, true
+}
+
+// ShortLinkExport is one short link as carried in a state export bundle.
+type ShortLinkExport struct {
+	ID      string `json:"id"`
+	URL     string `json:"url"`
+	Title   string `json:"title,omitempty"`
+	Snippet string `json:"snippet,omitempty"`
+	Hits    int    `json:"hits"`
+}
+
+// Export returns every short link for inclusion in a state export
+// bundle.
+func (s *shortLinkStore) Export() []ShortLinkExport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]ShortLinkExport, 0, len(s.entries))
+	for id, entry := range s.entries {
+		out = append(out, ShortLinkExport{ID: id, URL: entry.url, Title: entry.title, Snippet: entry.snippet, Hits: entry.hits})
+	}
+	return out
+}
+
+// Import restores short links from a state export bundle, so imported
+// links keep resolving under their original short IDs.
+func (s *shortLinkStore) Import(entries []ShortLinkExport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range entries {
+		s.entries[entry.ID] = &shortLinkEntry{url: entry.URL, title: entry.Title, snippet: entry.Snippet, hits: entry.Hits}
+	}
+}
+
+func newShortLinkID() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate short link id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestShortLinkStoreCreateAndResolve(t *testing.T) {
+	s := newShortLinkStore()
+
+	id, err := s.Create("https://github.com/o/r/blob/main/a.go", "", "")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	url, _, _, ok := s.Resolve(id)
+	if !ok {
+		t.Fatal("expected Resolve to find the created link")
+	}
+	if url != "https://github.com/o/r/blob/main/a.go" {
+		t.Errorf("Resolve url = %q, want the original URL", url)
+	}
+}
+
+func TestShortLinkStoreCreateAndResolveWithCard(t *testing.T) {
+	s := newShortLinkStore()
+
+	id, err := s.Create("https://github.com/o/r/blob/main/a.go", "o/r a.go", "func main() {}")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	url, title, snippet, ok := s.Resolve(id)
+	if !ok {
+		t.Fatal("expected Resolve to find the created link")
+	}
+	if url != "https://github.com/o/r/blob/main/a.go" || title != "o/r a.go" || snippet != "func main() {}" {
+		t.Errorf("Resolve = (%q, %q, %q), want the original url/title/snippet", url, title, snippet)
+	}
+}
+
+func TestShortLinkStoreResolveUnknown(t *testing.T) {
+	s := newShortLinkStore()
+	if _, _, _, ok := s.Resolve("nope"); ok {
+		t.Error("expected Resolve to fail for an unknown id")
+	}
+}
+
+func TestShortLinkStoreTracksHits(t *testing.T) {
+	s := newShortLinkStore()
+	id, _ := s.Create("https://example.com", "", "")
+
+	s.Resolve(id)
+	s.Resolve(id)
+
+	hits, ok := s.Hits(id)
+	if !ok || hits != 2 {
+		t.Errorf("Hits = %d, %v, want 2, true", hits, ok)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// slackMaxRequestAge rejects Slack slash-command requests whose
+// timestamp is older than this, so a captured request can't be replayed
+// indefinitely.
+const slackMaxRequestAge = 5 * time.Minute
+
+// verifySlackSignature checks a Slack slash-command request against its
+// X-Slack-Signature and X-Slack-Request-Timestamp headers, per Slack's
+// request signing scheme: https://api.slack.com/authentication/verifying-requests-from-slack
+func verifySlackSignature(signingSecret, timestamp, signature string, body []byte) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp: %w", err)
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > slackMaxRequestAge || age < -slackMaxRequestAge {
+		return fmt.Errorf("timestamp too old or too far in the future")
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if subtle.ConstantTimeCompare([]byte(want), []byte(signature)) != 1 {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// slackBlock is one entry in a Slack Block Kit blocks array.
+type slackBlock struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackCommandResponse is the JSON body Slack expects back from a slash
+// command, rendered with Block Kit rather than plain response_text.
+type slackCommandResponse struct {
+	ResponseType string       `json:"response_type"`
+	Blocks       []slackBlock `json:"blocks"`
+}
+
+// buildSlackResponse formats results as a Block Kit message: a header
+// summarizing the match count, then one section per result capped at
+// slackMaxResultBlocks so a large result set doesn't blow past Slack's
+// message size limit.
+const slackMaxResultBlocks = 10
+
+func buildSlackResponse(term string, results []Result) slackCommandResponse {
+	blocks := []slackBlock{
+		{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("*%d result(s) for `%s`*", len(results), term),
+			},
+		},
+	}
+
+	for i, r := range results {
+		if i >= slackMaxResultBlocks {
+			blocks = append(blocks, slackBlock{
+				Type: "section",
+				Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("_and %d more..._", len(results)-slackMaxResultBlocks)},
+			})
+			break
+		}
+
+		var snippet string
+		if len(r.Lines) > 0 {
+			snippet = r.Lines[0].Text
+		}
+
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("<%s|%s/%s>\n```%s```", r.URL, r.Repo, r.Path, strings.TrimSpace(snippet)),
+			},
+		})
+	}
+
+	return slackCommandResponse{ResponseType: "in_channel", Blocks: blocks}
+}
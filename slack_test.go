@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySlackSignatureValid(t *testing.T) {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	body := []byte("command=/codesearch&text=foo")
+	sig := sign("shh", ts, body)
+
+	if err := verifySlackSignature("shh", ts, sig, body); err != nil {
+		t.Fatalf("verifySlackSignature: %v", err)
+	}
+}
+
+func TestVerifySlackSignatureWrongSecret(t *testing.T) {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	body := []byte("command=/codesearch&text=foo")
+	sig := sign("shh", ts, body)
+
+	if err := verifySlackSignature("nope", ts, sig, body); err == nil {
+		t.Fatal("expected signature mismatch error")
+	}
+}
+
+func TestVerifySlackSignatureStaleTimestamp(t *testing.T) {
+	ts := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	body := []byte("command=/codesearch&text=foo")
+	sig := sign("shh", ts, body)
+
+	if err := verifySlackSignature("shh", ts, sig, body); err == nil {
+		t.Fatal("expected stale timestamp error")
+	}
+}
+
+func TestBuildSlackResponseSummary(t *testing.T) {
+	results := []Result{
+		{Repo: "o/r", Path: "a.go", URL: "https://github.com/o/r/blob/main/a.go", Lines: []Line{{Text: "func a() {}"}}},
+	}
+
+	resp := buildSlackResponse("foo", results)
+	if len(resp.Blocks) != 2 {
+		t.Fatalf("expected 2 blocks (summary + 1 result), got %d", len(resp.Blocks))
+	}
+}
+
+func TestBuildSlackResponseCapsBlocks(t *testing.T) {
+	var results []Result
+	for i := 0; i < slackMaxResultBlocks+5; i++ {
+		results = append(results, Result{Repo: "o/r", Path: "a.go", URL: "https://x", Lines: []Line{{Text: "x"}}})
+	}
+
+	resp := buildSlackResponse("foo", results)
+	// summary + slackMaxResultBlocks + "and N more" overflow block
+	if len(resp.Blocks) != slackMaxResultBlocks+2 {
+		t.Fatalf("expected %d blocks, got %d", slackMaxResultBlocks+2, len(resp.Blocks))
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultSLOWindow is used when an SLOConfig doesn't set window, or sets
+// one that fails to parse.
+const defaultSLOWindow = 24 * time.Hour
+
+// sloEvent is one recorded /search request outcome: whether it
+// succeeded and how long it took, timestamped so sloTracker can prune
+// events older than any configured SLO's window.
+type sloEvent struct {
+	at      time.Time
+	ok      bool
+	latency time.Duration
+}
+
+// sloTracker records every /search request's outcome and latency so
+// GET /admin/slo can report, per configured SLOConfig, actual
+// availability against its target along with an error-budget burn
+// rate - how fast the SLO's error budget is being consumed, where 1.0
+// means "exactly on target" and above 1 means burning faster than the
+// budget allows for its window. It's in-memory only and resets on
+// restart, same as usageTracker; SLO health is observational, not state
+// a restart needs to restore.
+type sloTracker struct {
+	mu     sync.Mutex
+	events []sloEvent
+	slos   []SLOConfig
+}
+
+func newSLOTracker() *sloTracker {
+	return &sloTracker{}
+}
+
+// Reconfigure applies cfg.SLOs, called on every config reload so an
+// edited target or window takes effect without a restart.
+func (t *sloTracker) Reconfigure(cfg Config) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.slos = cfg.SLOs
+}
+
+// Record adds one /search request's outcome to the tracker, pruning
+// anything older than the widest configured window so the event slice
+// doesn't grow without bound.
+func (t *sloTracker) Record(ok bool, latency time.Duration) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, sloEvent{at: now, ok: ok, latency: latency})
+	t.pruneLocked(now)
+}
+
+// pruneLocked drops every event older than the widest window across all
+// configured SLOs. The caller must hold t.mu.
+func (t *sloTracker) pruneLocked(now time.Time) {
+	widest := defaultSLOWindow
+	for _, slo := range t.slos {
+		if w, err := time.ParseDuration(slo.Window); err == nil && w > widest {
+			widest = w
+		}
+	}
+
+	cutoff := now.Add(-widest)
+	i := 0
+	for i < len(t.events) && t.events[i].at.Before(cutoff) {
+		i++
+	}
+	t.events = t.events[i:]
+}
+
+// SLOStatus is one configured SLO's live standing: how it's actually
+// performing against its target over its window, and how fast its error
+// budget is burning.
+type SLOStatus struct {
+	Name                 string  `json:"name"`
+	Window               string  `json:"window"`
+	RequestCount         int     `json:"request_count"`
+	TargetAvailability   float64 `json:"target_availability"`
+	ActualAvailability   float64 `json:"actual_availability"`
+	ErrorBudgetRemaining float64 `json:"error_budget_remaining"`
+	BurnRate             float64 `json:"burn_rate"`
+}
+
+// Report evaluates every configured SLO against the events recorded
+// within its own window, sorted by Name. A window with zero requests
+// reports 100% availability and a 0 burn rate, rather than dividing by
+// zero.
+func (t *sloTracker) Report() []SLOStatus {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := make([]SLOStatus, 0, len(t.slos))
+	for _, slo := range t.slos {
+		window, err := time.ParseDuration(slo.Window)
+		if err != nil || window <= 0 {
+			window = defaultSLOWindow
+		}
+		cutoff := now.Add(-window)
+
+		var total, good int
+		for _, e := range t.events {
+			if e.at.Before(cutoff) {
+				continue
+			}
+			total++
+			if e.ok && (slo.LatencyThresholdMS <= 0 || e.latency <= time.Duration(slo.LatencyThresholdMS)*time.Millisecond) {
+				good++
+			}
+		}
+
+		status := SLOStatus{
+			Name:               slo.Name,
+			Window:             window.String(),
+			RequestCount:       total,
+			TargetAvailability: slo.TargetAvailability,
+			ActualAvailability: 1,
+		}
+		if total > 0 {
+			status.ActualAvailability = float64(good) / float64(total)
+		}
+
+		errorBudget := 1 - slo.TargetAvailability
+		if errorBudget > 0 {
+			status.BurnRate = (1 - status.ActualAvailability) / errorBudget
+			status.ErrorBudgetRemaining = 1 - status.BurnRate
+		}
+		report = append(report, status)
+	}
+
+	sort.Slice(report, func(i, j int) bool { return report[i].Name < report[j].Name })
+	return report
+}
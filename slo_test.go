@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSLOTrackerReportComputesAvailabilityAndBurnRate(t *testing.T) {
+	tr := newSLOTracker()
+	tr.Reconfigure(Config{SLOs: []SLOConfig{
+		{Name: "search", TargetAvailability: 0.99, Window: "1h"},
+	}})
+
+	for i := 0; i < 9; i++ {
+		tr.Record(true, time.Millisecond)
+	}
+	tr.Record(false, time.Millisecond)
+
+	report := tr.Report()
+	if len(report) != 1 {
+		t.Fatalf("Report() = %+v, want 1 SLO", report)
+	}
+	got := report[0]
+	if got.RequestCount != 10 {
+		t.Errorf("RequestCount = %d, want 10", got.RequestCount)
+	}
+	if got.ActualAvailability != 0.9 {
+		t.Errorf("ActualAvailability = %v, want 0.9", got.ActualAvailability)
+	}
+	// error budget is 1%; actual error rate is 10%, so burn rate is 10x.
+	if diff := got.BurnRate - 10; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("BurnRate = %v, want 10", got.BurnRate)
+	}
+}
+
+func TestSLOTrackerReportEnforcesLatencyThreshold(t *testing.T) {
+	tr := newSLOTracker()
+	tr.Reconfigure(Config{SLOs: []SLOConfig{
+		{Name: "search", TargetAvailability: 0.99, LatencyThresholdMS: 100, Window: "1h"},
+	}})
+
+	tr.Record(true, 50*time.Millisecond)
+	tr.Record(true, 200*time.Millisecond) // succeeded, but too slow to count as "good"
+
+	got := tr.Report()[0]
+	if got.ActualAvailability != 0.5 {
+		t.Errorf("ActualAvailability = %v, want 0.5 (one of two requests within threshold)", got.ActualAvailability)
+	}
+}
+
+func TestSLOTrackerReportPrunesEventsOutsideWindow(t *testing.T) {
+	tr := newSLOTracker()
+	tr.Reconfigure(Config{SLOs: []SLOConfig{{Name: "search", TargetAvailability: 0.99, Window: "1ms"}}})
+
+	tr.Record(false, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	tr.Record(true, time.Millisecond)
+
+	got := tr.Report()[0]
+	if got.RequestCount != 1 {
+		t.Errorf("RequestCount = %d, want 1 (the stale failure should have been pruned)", got.RequestCount)
+	}
+	if got.ActualAvailability != 1 {
+		t.Errorf("ActualAvailability = %v, want 1", got.ActualAvailability)
+	}
+}
+
+func TestSLOTrackerReportWithNoTrafficIsFullyAvailable(t *testing.T) {
+	tr := newSLOTracker()
+	tr.Reconfigure(Config{SLOs: []SLOConfig{{Name: "search", TargetAvailability: 0.99, Window: "1h"}}})
+
+	got := tr.Report()[0]
+	if got.RequestCount != 0 || got.ActualAvailability != 1 || got.BurnRate != 0 {
+		t.Errorf("Report()[0] = %+v, want a fresh, fully-available SLO", got)
+	}
+}
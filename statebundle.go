@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// stateBundleVersion is bumped whenever StateBundle's shape changes in a
+// way that would break importing an older export, so /admin/import can
+// reject a bundle it doesn't know how to apply instead of silently
+// misreading it.
+const stateBundleVersion = 1
+
+// StateBundle is the full set of this service's runtime state (as
+// opposed to what's loaded from the config file), in one versioned
+// document that GET /admin/export produces and POST /admin/import
+// consumes, so a deployment's accumulated short links, REST hook
+// subscriptions, disabled repos, imported repos, and detected renames
+// can be carried over to a fresh instance.
+type StateBundle struct {
+	Version       int                    `json:"version"`
+	ShortLinks    []ShortLinkExport      `json:"short_links,omitempty"`
+	RESTHooks     []RESTHookSubscription `json:"rest_hooks,omitempty"`
+	DisabledRepos []DisabledRepo         `json:"disabled_repos,omitempty"`
+	ImportedRepos []string               `json:"imported_repos,omitempty"`
+	RepoRenames   []RepoRename           `json:"repo_renames,omitempty"`
+	Flags         map[string]bool        `json:"flags,omitempty"`
+}
+
+// exportState builds a StateBundle from every runtime store the server
+// holds.
+func (s *server) exportState() StateBundle {
+	return StateBundle{
+		Version:       stateBundleVersion,
+		ShortLinks:    s.shortlinks.Export(),
+		RESTHooks:     s.resthooks.List(),
+		DisabledRepos: s.disabled.List(),
+		ImportedRepos: s.imports.List(),
+		RepoRenames:   s.renames.List(),
+		Flags:         s.flags.List(),
+	}
+}
+
+// importState restores bundle into every runtime store the server
+// holds, merging with (rather than replacing) whatever is already
+// there, and reloads the active searcher so any newly imported repo
+// takes effect immediately.
+func (s *server) importState(bundle StateBundle) error {
+	bundle, err := migrateStateBundle(bundle)
+	if err != nil {
+		return fmt.Errorf("unsupported state bundle: %w", err)
+	}
+
+	s.shortlinks.Import(bundle.ShortLinks)
+	s.resthooks.Import(bundle.RESTHooks)
+	s.disabled.Import(bundle.DisabledRepos)
+	s.imports.Import(bundle.ImportedRepos)
+	s.renames.Import(bundle.RepoRenames)
+	s.flags.Import(bundle.Flags)
+
+	if len(bundle.ImportedRepos) > 0 {
+		return s.reload(s.searcher.Load().cfg)
+	}
+	return nil
+}
+
+// persistState saves the current runtime state to s.store, if
+// StatePersistPath configured one. Called after every operation that
+// changes state a restart would otherwise lose. Errors are logged
+// rather than surfaced to the HTTP caller, since a persistence hiccup
+// shouldn't fail the request that triggered it.
+func (s *server) persistState() {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.Save(s.exportState()); err != nil {
+		log.Printf("persist state: %v", err)
+	}
+}
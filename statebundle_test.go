@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExportImportStateRoundTrip(t *testing.T) {
+	src, err := newServer(Config{})
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	src.shortlinks.Import([]ShortLinkExport{{ID: "abc123", URL: "https://example.com", Hits: 3}})
+	if _, err := src.resthooks.Subscribe(restHookEventNewMatch, "auth", "https://example.com/hook"); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	src.disabled.Disable("bjatkin/golf-engine", "incident", time.Time{})
+	if _, err := src.imports.Merge([]string{"bjatkin/checkSUM"}, nil); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	src.renames.Record("bjatkin/old-name", "bjatkin/new-name")
+
+	bundle := src.exportState()
+	if bundle.Version != stateBundleVersion {
+		t.Errorf("Version = %d, want %d", bundle.Version, stateBundleVersion)
+	}
+
+	dst, err := newServer(Config{})
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	if err := dst.importState(bundle); err != nil {
+		t.Fatalf("importState: %v", err)
+	}
+
+	if url, _, _, ok := dst.shortlinks.Resolve("abc123"); !ok || url != "https://example.com" {
+		t.Errorf("short link did not survive import: url=%q ok=%v", url, ok)
+	}
+	if len(dst.resthooks.List()) != 1 {
+		t.Errorf("expected 1 rest hook subscription after import, got %d", len(dst.resthooks.List()))
+	}
+	if !dst.disabled.IsDisabled("bjatkin/golf-engine") {
+		t.Error("expected bjatkin/golf-engine to be disabled after import")
+	}
+	found := false
+	for _, repo := range dst.searcher.Load().cfg.Repos {
+		if repo == "bjatkin/checkSUM" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected bjatkin/checkSUM to be merged into the active config after import")
+	}
+	if got := dst.renames.Resolve("bjatkin/old-name"); got != "bjatkin/new-name" {
+		t.Errorf("Resolve(bjatkin/old-name) = %q, want bjatkin/new-name", got)
+	}
+}
+
+func TestImportStateRejectsUnknownVersion(t *testing.T) {
+	s, err := newServer(Config{})
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	if err := s.importState(StateBundle{Version: stateBundleVersion + 1}); err == nil {
+		t.Fatal("expected an error for an unsupported bundle version")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+)
+
+func testStateKey(t *testing.T, fill byte) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = fill
+	}
+	return key
+}
+
+func TestEncryptDecryptStateBytesRoundTrip(t *testing.T) {
+	key := testStateKey(t, 0x01)
+	plaintext := []byte(`{"version":1}`)
+
+	ciphertext, err := encryptStateBytes([][]byte{key}, plaintext)
+	if err != nil {
+		t.Fatalf("encryptStateBytes() error = %v", err)
+	}
+	if string(ciphertext) == string(plaintext) {
+		t.Fatal("expected ciphertext to differ from plaintext")
+	}
+
+	got, err := decryptStateBytes([][]byte{key}, ciphertext)
+	if err != nil {
+		t.Fatalf("decryptStateBytes() error = %v", err)
+	}
+	if string(got) != string(plaintext) {
+		t.Errorf("decryptStateBytes() = %q, want %q", got, plaintext)
+	}
+}
+
+func TestDecryptStateBytesTriesEveryKeyForRotation(t *testing.T) {
+	oldKey := testStateKey(t, 0x02)
+	newKey := testStateKey(t, 0x03)
+
+	ciphertext, err := encryptStateBytes([][]byte{oldKey}, []byte("hello"))
+	if err != nil {
+		t.Fatalf("encryptStateBytes() error = %v", err)
+	}
+
+	got, err := decryptStateBytes([][]byte{newKey, oldKey}, ciphertext)
+	if err != nil {
+		t.Fatalf("decryptStateBytes() with rotated keys error = %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("decryptStateBytes() = %q, want %q", got, "hello")
+	}
+}
+
+func TestDecryptStateBytesFailsWithWrongKey(t *testing.T) {
+	ciphertext, err := encryptStateBytes([][]byte{testStateKey(t, 0x04)}, []byte("hello"))
+	if err != nil {
+		t.Fatalf("encryptStateBytes() error = %v", err)
+	}
+
+	if _, err := decryptStateBytes([][]byte{testStateKey(t, 0x05)}, ciphertext); err == nil {
+		t.Fatal("expected an error decrypting with the wrong key")
+	}
+}
+
+func TestParseStateEncryptionKeysRejectsWrongLength(t *testing.T) {
+	if _, err := parseStateEncryptionKeys([]string{hex.EncodeToString([]byte("too-short"))}); err == nil {
+		t.Fatal("expected an error for a key that isn't 32 bytes")
+	}
+}
+
+func TestEncryptedFileStateStoreSaveLoadRoundTrip(t *testing.T) {
+	key := testStateKey(t, 0x06)
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := newEncryptedFileStateStore(path, [][]byte{key})
+
+	bundle := StateBundle{Version: stateBundleVersion, ImportedRepos: []string{"bjatkin/golf-engine"}}
+	if err := store.Save(bundle); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got.ImportedRepos) != 1 || got.ImportedRepos[0] != "bjatkin/golf-engine" {
+		t.Errorf("Load() ImportedRepos = %+v, want [bjatkin/golf-engine]", got.ImportedRepos)
+	}
+
+	if _, err := newFileStateStore(path).Load(); err == nil {
+		t.Error("expected reading an encrypted state file without a key to fail")
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// StateStore persists a StateBundle across process restarts, so
+// short links, REST hook subscriptions, disabled/imported repos, and
+// detected renames aren't lost when the service is redeployed.
+//
+// The only implementation in this build is fileStateStore, a local
+// JSON file, selected by StateBackend being "" or "file". A SQLite or
+// Postgres-backed StateStore is a natural extension of this interface
+// for a multi-instance deployment sharing one store, but isn't
+// implemented here: this build doesn't vendor a database driver (the
+// sandbox this was written in has no network access to fetch one), so
+// adding either would mean shipping code that can't actually be built
+// or tested. Configuring state_backend as "sqlite" or "postgres" fails
+// loudly at startup instead of silently falling back to the file store
+// (see validateStateBackend).
+type StateStore interface {
+	Save(bundle StateBundle) error
+	Load() (StateBundle, error)
+}
+
+// stateBackendFile is the only StateBackend this build implements.
+const stateBackendFile = "file"
+
+// validateStateBackend rejects an unknown or unimplemented
+// state_backend value at startup, rather than silently using the file
+// store when an operator asked for a shared database-backed one.
+// "sqlite" and "postgres" are accepted by the config schema but not
+// actually usable yet: this build has no network access to vendor a
+// database driver, and this repo's policy is to never fake a
+// dependency it doesn't have.
+func validateStateBackend(backend string) error {
+	switch backend {
+	case "", stateBackendFile:
+		return nil
+	case "sqlite", "postgres":
+		return fmt.Errorf("state_backend: %q is not implemented in this build (no database driver is vendored); leave state_backend unset, or set it to %q, to use the local JSON file store instead", backend, stateBackendFile)
+	default:
+		return fmt.Errorf("state_backend: unknown backend %q", backend)
+	}
+}
+
+// fileStateStore persists a StateBundle as a single JSON file on local
+// disk.
+type fileStateStore struct {
+	path string
+
+	// encryptionKeys, when non-empty, encrypts the file at rest with
+	// AES-256-GCM: keys[0] encrypts new writes, and every key is tried
+	// to decrypt, so a leaked backup or disk snapshot doesn't expose
+	// the tokens and subscription URLs the bundle contains, and a key
+	// can be rotated by prepending a new one ahead of the old. Mutually
+	// exclusive with kms.
+	encryptionKeys [][]byte
+
+	// kms, when set, envelope-encrypts the file at rest: each save
+	// generates a random one-time data key, encrypts the bundle with
+	// it, and has kms wrap the data key itself, so the bulk of the data
+	// never has to be sent to the key management service. Mutually
+	// exclusive with encryptionKeys.
+	kms KMSProvider
+}
+
+func newFileStateStore(path string) *fileStateStore {
+	return &fileStateStore{path: path}
+}
+
+// newEncryptedFileStateStore is newFileStateStore with encryption at
+// rest enabled. keys must be non-empty, each a 32-byte AES-256 key.
+func newEncryptedFileStateStore(path string, keys [][]byte) *fileStateStore {
+	return &fileStateStore{path: path, encryptionKeys: keys}
+}
+
+// newEnvelopeFileStateStore is newFileStateStore with envelope
+// encryption via kms enabled.
+func newEnvelopeFileStateStore(path string, kms KMSProvider) *fileStateStore {
+	return &fileStateStore{path: path, kms: kms}
+}
+
+// stateEnvelope is the on-disk shape of an envelope-encrypted state
+// file: bundle encrypted under a random data key, and that data key
+// wrapped by the configured KMSProvider.
+type stateEnvelope struct {
+	WrappedKey []byte `json:"wrapped_key"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Save writes bundle to disk, via a temp file plus rename so a crash
+// mid-write can't leave a truncated, unreadable state file behind.
+func (f *fileStateStore) Save(bundle StateBundle) error {
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("encode state bundle: %w", err)
+	}
+
+	switch {
+	case f.kms != nil:
+		data, err = f.envelopeEncrypt(data)
+		if err != nil {
+			return fmt.Errorf("envelope-encrypt state file: %w", err)
+		}
+	case len(f.encryptionKeys) > 0:
+		data, err = encryptStateBytes(f.encryptionKeys, data)
+		if err != nil {
+			return fmt.Errorf("encrypt state file: %w", err)
+		}
+	}
+
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("replace state file: %w", err)
+	}
+	return nil
+}
+
+// Load reads back a bundle saved by Save, migrating it forward to
+// stateBundleVersion if it was written by an older build. A missing
+// file (e.g. the service's first run) isn't an error: it returns a
+// zero-value bundle, since there's nothing yet to restore.
+func (f *fileStateStore) Load() (StateBundle, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return StateBundle{Version: stateBundleVersion}, nil
+	}
+	if err != nil {
+		return StateBundle{}, fmt.Errorf("read state file: %w", err)
+	}
+
+	switch {
+	case f.kms != nil:
+		data, err = f.envelopeDecrypt(data)
+		if err != nil {
+			return StateBundle{}, fmt.Errorf("envelope-decrypt state file: %w", err)
+		}
+	case len(f.encryptionKeys) > 0:
+		data, err = decryptStateBytes(f.encryptionKeys, data)
+		if err != nil {
+			return StateBundle{}, fmt.Errorf("decrypt state file: %w", err)
+		}
+	}
+
+	var bundle StateBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return StateBundle{}, fmt.Errorf("parse state file: %w", err)
+	}
+
+	bundle, err = migrateStateBundle(bundle)
+	if err != nil {
+		return StateBundle{}, fmt.Errorf("migrate state file: %w", err)
+	}
+	return bundle, nil
+}
+
+// envelopeEncrypt encrypts data under a fresh random data key, wraps
+// that key with f.kms, and returns the JSON-encoded stateEnvelope.
+func (f *fileStateStore) envelopeEncrypt(data []byte) ([]byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+
+	ciphertext, err := encryptStateBytes([][]byte{dataKey}, data)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt with data key: %w", err)
+	}
+
+	wrappedKey, err := f.kms.EncryptDataKey(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key: %w", err)
+	}
+
+	return json.Marshal(stateEnvelope{WrappedKey: wrappedKey, Ciphertext: ciphertext})
+}
+
+// envelopeDecrypt is the inverse of envelopeEncrypt.
+func (f *fileStateStore) envelopeDecrypt(data []byte) ([]byte, error) {
+	var envelope stateEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("parse state envelope: %w", err)
+	}
+
+	dataKey, err := f.kms.DecryptDataKey(envelope.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+
+	return decryptStateBytes([][]byte{dataKey}, envelope.Ciphertext)
+}
+
+// ensureStateDir creates the parent directory of path if needed, so a
+// fresh deployment's state_persist_path doesn't have to pre-exist.
+func ensureStateDir(path string) error {
+	dir := filepath.Dir(path)
+	if dir == "." || dir == "" {
+		return nil
+	}
+	return os.MkdirAll(dir, 0o755)
+}
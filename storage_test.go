@@ -0,0 +1,96 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStateStoreSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := newFileStateStore(path)
+
+	bundle := StateBundle{
+		Version:       stateBundleVersion,
+		ShortLinks:    []ShortLinkExport{{ID: "abc123", URL: "https://github.com/bjatkin/golf-engine", Hits: 3}},
+		ImportedRepos: []string{"bjatkin/golf-engine"},
+	}
+
+	if err := store.Save(bundle); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got.ShortLinks) != 1 || got.ShortLinks[0].ID != "abc123" {
+		t.Errorf("Load() ShortLinks = %+v, want a single abc123 entry", got.ShortLinks)
+	}
+	if len(got.ImportedRepos) != 1 || got.ImportedRepos[0] != "bjatkin/golf-engine" {
+		t.Errorf("Load() ImportedRepos = %+v, want [bjatkin/golf-engine]", got.ImportedRepos)
+	}
+}
+
+func TestEnvelopeFileStateStoreSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	kms, err := newKMSProvider("static", Config{KMSStaticKey: strings.Repeat("cd", 32)})
+	if err != nil {
+		t.Fatalf("newKMSProvider() error = %v", err)
+	}
+	store := newEnvelopeFileStateStore(path, kms)
+
+	bundle := StateBundle{Version: stateBundleVersion, ImportedRepos: []string{"bjatkin/golf-engine"}}
+	if err := store.Save(bundle); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got.ImportedRepos) != 1 || got.ImportedRepos[0] != "bjatkin/golf-engine" {
+		t.Errorf("Load() ImportedRepos = %+v, want [bjatkin/golf-engine]", got.ImportedRepos)
+	}
+
+	if _, err := newFileStateStore(path).Load(); err == nil {
+		t.Error("expected reading an envelope-encrypted state file as plain JSON to fail")
+	}
+}
+
+func TestValidateStateBackendAcceptsFileAndEmpty(t *testing.T) {
+	for _, backend := range []string{"", "file"} {
+		if err := validateStateBackend(backend); err != nil {
+			t.Errorf("validateStateBackend(%q): %v", backend, err)
+		}
+	}
+}
+
+func TestValidateStateBackendRejectsUnimplementedDatabases(t *testing.T) {
+	for _, backend := range []string{"sqlite", "postgres"} {
+		if err := validateStateBackend(backend); err == nil {
+			t.Errorf("expected an error selecting the unavailable %q backend", backend)
+		}
+	}
+}
+
+func TestValidateStateBackendRejectsUnknown(t *testing.T) {
+	if err := validateStateBackend("mysql"); err == nil {
+		t.Error("expected an error for an unrecognized backend")
+	}
+}
+
+func TestFileStateStoreLoadMissingFileReturnsZeroValue(t *testing.T) {
+	store := newFileStateStore(filepath.Join(t.TempDir(), "does-not-exist.json"))
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.Version != stateBundleVersion {
+		t.Errorf("Load() Version = %d, want %d", got.Version, stateBundleVersion)
+	}
+	if len(got.ShortLinks) != 0 {
+		t.Errorf("Load() ShortLinks = %+v, want none", got.ShortLinks)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ctagsBinary is the universal-ctags executable this service shells out
+// to, same as "git" is assumed to be on PATH for cloning. Symbol search
+// is unavailable (repoSymbols returns an error) if it isn't installed.
+const ctagsBinary = "ctags"
+
+// symbolCacheTTL is how long a repo's extracted symbols are kept before
+// a repeat lookup re-runs ctags, balancing freshness against not
+// re-parsing every file in a large repo on every request.
+const symbolCacheTTL = 1 * time.Hour
+
+// Symbol is one function, type, class, or other named definition found
+// by ctags in a repo's local clone.
+type Symbol struct {
+	Repo string `json:"repo"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	Line int    `json:"line"`
+}
+
+// symbolCache holds a repo's extracted symbols in memory, since a full
+// ctags run over a large repo isn't cheap enough to redo on every
+// request.
+type symbolCache struct {
+	mu      sync.Mutex
+	entries map[string]symbolCacheEntry
+}
+
+type symbolCacheEntry struct {
+	symbols   []Symbol
+	expiresAt time.Time
+}
+
+func newSymbolCache() *symbolCache {
+	return &symbolCache{entries: map[string]symbolCacheEntry{}}
+}
+
+func (c *symbolCache) get(repo string) ([]Symbol, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[repo]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, repo)
+		return nil, false
+	}
+	return entry.symbols, true
+}
+
+func (c *symbolCache) put(repo string, symbols []Symbol) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[repo] = symbolCacheEntry{symbols: symbols, expiresAt: time.Now().Add(symbolCacheTTL)}
+}
+
+// ctagsTag is one line of universal-ctags' --output-format=json output.
+// Only the fields symbol search needs are declared; ctags emits more
+// (pattern, scope, etc.) that we don't care about here.
+type ctagsTag struct {
+	Type string `json:"_type"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	Line int    `json:"line"`
+}
+
+// parseCtagsOutput turns ctags' newline-delimited JSON output into
+// Symbols, making each one's Path relative to dir the way every other
+// local-index result's Path is relative to the repo root. Lines that
+// aren't a "tag" record (ctags also emits a leading "ptag" schema
+// record) or fail to parse are skipped rather than failing the whole
+// extraction.
+func parseCtagsOutput(repo, dir string, output []byte) []Symbol {
+	var symbols []Symbol
+	for _, line := range bytes.Split(output, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var tag ctagsTag
+		if err := json.Unmarshal(line, &tag); err != nil || tag.Type != "tag" {
+			continue
+		}
+
+		path := tag.Path
+		if rel, err := filepath.Rel(dir, tag.Path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+
+		symbols = append(symbols, Symbol{
+			Repo: repo,
+			Name: tag.Name,
+			Kind: tag.Kind,
+			Path: path,
+			Line: tag.Line,
+		})
+	}
+	return symbols
+}
+
+// repoSymbols extracts every symbol ctags can find in repo's local
+// clone, caching the result for symbolCacheTTL.
+func (idx *localIndex) repoSymbols(repo string) ([]Symbol, error) {
+	if symbols, ok := idx.symbols.get(repo); ok {
+		return symbols, nil
+	}
+
+	dir, err := idx.ensureClone(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := idx.sandbox.run(ctagsBinary, "-R", "--fields=+n", "--output-format=json", "-f", "-", dir)
+	if err != nil {
+		return nil, fmt.Errorf("extract symbols for %s: %w: %s", repo, err, out)
+	}
+
+	symbols := parseCtagsOutput(repo, dir, out)
+	idx.symbols.put(repo, symbols)
+	return symbols, nil
+}
+
+// SearchSymbols looks up query as a case-insensitive substring of a
+// symbol's name across every configured repo's local clone. Code search
+// is often really symbol lookup ("where is UserService defined"), which
+// the GitHub code search API can't answer directly.
+func (s *searcher) SearchSymbols(query string) ([]Symbol, error) {
+	needle := strings.ToLower(query)
+	var matches []Symbol
+	for _, repo := range s.cfg.Repos {
+		if s.disabled.IsDisabled(repo) {
+			continue
+		}
+		symbols, err := s.local.repoSymbols(repo)
+		if err != nil {
+			return nil, err
+		}
+		for _, sym := range symbols {
+			if strings.Contains(strings.ToLower(sym.Name), needle) {
+				matches = append(matches, sym)
+			}
+		}
+	}
+	return matches, nil
+}
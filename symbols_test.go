@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCtagsOutput(t *testing.T) {
+	dir := "/repo"
+	output := []byte(`{"_type":"tag","name":"NewServer","path":"/repo/server.go","kind":"function","line":12}
+{"_type":"ptag","name":"TAG_FILE_FORMAT","path":"unused"}
+
+{"_type":"tag","name":"Server","path":"/repo/server.go","kind":"struct","line":5}
+not json
+`)
+
+	symbols := parseCtagsOutput("owner/repo", dir, output)
+	if len(symbols) != 2 {
+		t.Fatalf("expected 2 symbols, got %d: %+v", len(symbols), symbols)
+	}
+	if symbols[0].Path != "server.go" {
+		t.Errorf("Path = %q, want %q (relative to dir)", symbols[0].Path, "server.go")
+	}
+	if symbols[0].Name != "NewServer" || symbols[0].Kind != "function" || symbols[0].Line != 12 {
+		t.Errorf("unexpected symbol: %+v", symbols[0])
+	}
+	if symbols[0].Repo != "owner/repo" {
+		t.Errorf("Repo = %q, want owner/repo", symbols[0].Repo)
+	}
+}
+
+func TestParseCtagsOutputSkipsMalformedLines(t *testing.T) {
+	symbols := parseCtagsOutput("owner/repo", "/repo", []byte("not json\n{}\n"))
+	if len(symbols) != 0 {
+		t.Errorf("expected no symbols from malformed input, got %+v", symbols)
+	}
+}
+
+func TestSymbolCacheGetPut(t *testing.T) {
+	c := newSymbolCache()
+	if _, ok := c.get("owner/repo"); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+	want := []Symbol{{Repo: "owner/repo", Name: "Foo"}}
+	c.put("owner/repo", want)
+	got, ok := c.get("owner/repo")
+	if !ok || len(got) != 1 || got[0].Name != "Foo" {
+		t.Errorf("get after put = %+v, %v, want %+v, true", got, ok, want)
+	}
+}
+
+func TestSearchSymbolsMatchesCaseInsensitiveSubstring(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	idx.symbols.put("owner/repo", []Symbol{
+		{Repo: "owner/repo", Name: "NewServer", Kind: "function"},
+		{Repo: "owner/repo", Name: "Client", Kind: "struct"},
+	})
+
+	s := &searcher{cfg: Config{Repos: []string{"owner/repo"}}, local: idx, disabled: newRepoDisableStore()}
+	matches, err := s.SearchSymbols("server")
+	if err != nil {
+		t.Fatalf("SearchSymbols: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Name != "NewServer" {
+		t.Errorf("matches = %+v, want [NewServer]", matches)
+	}
+}
+
+func TestRepoSymbolsExtractsRealCtagsOutput(t *testing.T) {
+	if _, err := exec.LookPath(ctagsBinary); err != nil {
+		t.Skip("ctags not available")
+	}
+
+	dataDir := t.TempDir()
+	repo := "owner/repo"
+	dir := filepath.Join(dataDir, repo, ".git")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := "package main\n\nfunc NewServer() {}\n"
+	if err := os.WriteFile(filepath.Join(dataDir, repo, "server.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	symbols, err := idx.repoSymbols(repo)
+	if err != nil {
+		t.Fatalf("repoSymbols: %v", err)
+	}
+	found := false
+	for _, sym := range symbols {
+		if sym.Name == "NewServer" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected to find NewServer in %+v", symbols)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import "fmt"
+
+// teamsActivity is the subset of a Bot Framework Activity this service
+// reads from an incoming Teams message.
+// https://learn.microsoft.com/en-us/microsoftteams/platform/bots/how-to/conversations/conversation-basics
+type teamsActivity struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// teamsActivityResponse is the Activity sent back to Teams, carrying the
+// results as an Adaptive Card attachment.
+type teamsActivityResponse struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string            `json:"contentType"`
+	Content     teamsAdaptiveCard `json:"content"`
+}
+
+// teamsAdaptiveCard is a minimal Adaptive Card: a title TextBlock
+// followed by one TextBlock per result.
+type teamsAdaptiveCard struct {
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Schema  string           `json:"$schema"`
+	Body    []teamsCardBlock `json:"body"`
+}
+
+type teamsCardBlock struct {
+	Type   string `json:"type"`
+	Text   string `json:"text"`
+	Weight string `json:"weight,omitempty"`
+	Wrap   bool   `json:"wrap,omitempty"`
+}
+
+// teamsMaxResultBlocks caps how many result blocks an Adaptive Card
+// carries, so a large result set doesn't exceed Teams' card size limit.
+const teamsMaxResultBlocks = 10
+
+// buildTeamsResponse formats results as an Activity carrying a single
+// Adaptive Card attachment.
+func buildTeamsResponse(term string, results []Result) teamsActivityResponse {
+	body := []teamsCardBlock{
+		{Type: "TextBlock", Text: fmt.Sprintf("%d result(s) for \"%s\"", len(results), term), Weight: "Bolder", Wrap: true},
+	}
+
+	for i, r := range results {
+		if i >= teamsMaxResultBlocks {
+			body = append(body, teamsCardBlock{
+				Type: "TextBlock",
+				Text: fmt.Sprintf("and %d more...", len(results)-teamsMaxResultBlocks),
+				Wrap: true,
+			})
+			break
+		}
+
+		body = append(body, teamsCardBlock{
+			Type: "TextBlock",
+			Text: fmt.Sprintf("%s/%s: %s", r.Repo, r.Path, r.URL),
+			Wrap: true,
+		})
+	}
+
+	return teamsActivityResponse{
+		Type: "message",
+		Attachments: []teamsAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content: teamsAdaptiveCard{
+					Type:    "AdaptiveCard",
+					Version: "1.4",
+					Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+					Body:    body,
+				},
+			},
+		},
+	}
+}
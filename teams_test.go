@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestBuildTeamsResponseSummary(t *testing.T) {
+	results := []Result{
+		{Repo: "o/r", Path: "a.go", URL: "https://github.com/o/r/blob/main/a.go"},
+	}
+
+	resp := buildTeamsResponse("foo", results)
+	if len(resp.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(resp.Attachments))
+	}
+	card := resp.Attachments[0].Content
+	if len(card.Body) != 2 {
+		t.Fatalf("expected 2 card blocks (summary + 1 result), got %d", len(card.Body))
+	}
+}
+
+func TestBuildTeamsResponseCapsBlocks(t *testing.T) {
+	var results []Result
+	for i := 0; i < teamsMaxResultBlocks+5; i++ {
+		results = append(results, Result{Repo: "o/r", Path: "a.go", URL: "https://x"})
+	}
+
+	resp := buildTeamsResponse("foo", results)
+	card := resp.Attachments[0].Content
+	if len(card.Body) != teamsMaxResultBlocks+2 {
+		t.Fatalf("expected %d card blocks, got %d", teamsMaxResultBlocks+2, len(card.Body))
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// defaultTelemetryInterval is how often an opted-in instance sends a
+// telemetry report, when TelemetryInterval isn't set.
+const defaultTelemetryInterval = 24 * time.Hour
+
+// TelemetryReport is the payload POSTed to Config.TelemetryEndpoint: an
+// aggregate, anonymized snapshot with nothing that identifies this
+// deployment or the repos/queries it serves.
+type TelemetryReport struct {
+	Version           string   `json:"version"`
+	BackendKinds      []string `json:"backend_kinds"`
+	QueryVolumeBucket string   `json:"query_volume_bucket"`
+}
+
+// queryVolumeBucket maps a raw search count into a coarse range, so the
+// reported number can't be used to fingerprint a specific deployment by
+// its exact traffic level.
+func queryVolumeBucket(count int) string {
+	switch {
+	case count == 0:
+		return "0"
+	case count <= 10:
+		return "1-10"
+	case count <= 100:
+		return "11-100"
+	case count <= 1000:
+		return "101-1000"
+	case count <= 10000:
+		return "1001-10000"
+	default:
+		return "10000+"
+	}
+}
+
+// backendKinds returns the distinct backend kinds cfg searches against:
+// always "github" (every config has the default backend), plus each
+// distinct RepoBackends.Kind.
+func backendKinds(cfg Config) []string {
+	seen := map[string]bool{backendGithub: true}
+	kinds := []string{backendGithub}
+	for _, rb := range cfg.RepoBackends {
+		kind := rb.Kind
+		if kind == "" {
+			kind = backendGithub
+		}
+		if seen[kind] {
+			continue
+		}
+		seen[kind] = true
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// buildTelemetryReport assembles the report for cfg, bucketing
+// totalSearches (a raw count) before it's included, so the raw number
+// never leaves the process.
+func buildTelemetryReport(cfg Config, totalSearches int) TelemetryReport {
+	return TelemetryReport{
+		Version:           appVersion,
+		BackendKinds:      backendKinds(cfg),
+		QueryVolumeBucket: queryVolumeBucket(totalSearches),
+	}
+}
+
+// sendTelemetryReport POSTs report as JSON to endpoint. Errors are
+// logged, not returned: a telemetry failure must never affect serving
+// search requests.
+func sendTelemetryReport(endpoint string, report TelemetryReport) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("telemetry: encode report: %v", err)
+		return
+	}
+
+	resp, err := http.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("telemetry: send report: %v", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// runTelemetry sends a telemetry report to cfg.TelemetryEndpoint every
+// interval, computing the search volume from usage's recorded activity.
+// It never returns; run it in its own goroutine. Does nothing if
+// cfg.TelemetryEnabled is false or cfg.TelemetryEndpoint is empty, so
+// it's always safe to call.
+func runTelemetry(cfg Config, usage *usageTracker, interval time.Duration) {
+	if !cfg.TelemetryEnabled || cfg.TelemetryEndpoint == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultTelemetryInterval
+	}
+
+	for range time.Tick(interval) {
+		total := 0
+		for _, day := range usage.Report().Daily {
+			total += day.Searches
+		}
+		sendTelemetryReport(cfg.TelemetryEndpoint, buildTelemetryReport(cfg, total))
+	}
+}
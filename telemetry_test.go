@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestQueryVolumeBucket(t *testing.T) {
+	cases := []struct {
+		count int
+		want  string
+	}{
+		{0, "0"},
+		{1, "1-10"},
+		{10, "1-10"},
+		{11, "11-100"},
+		{1000, "101-1000"},
+		{1001, "1001-10000"},
+		{10001, "10000+"},
+	}
+	for _, c := range cases {
+		if got := queryVolumeBucket(c.count); got != c.want {
+			t.Errorf("queryVolumeBucket(%d) = %q, want %q", c.count, got, c.want)
+		}
+	}
+}
+
+func TestBackendKindsAlwaysIncludesGithub(t *testing.T) {
+	kinds := backendKinds(Config{})
+	if len(kinds) != 1 || kinds[0] != backendGithub {
+		t.Errorf("backendKinds(empty config) = %v, want [%q]", kinds, backendGithub)
+	}
+}
+
+func TestBackendKindsDedupsOverrides(t *testing.T) {
+	cfg := Config{RepoBackends: []RepoBackend{
+		{Repo: "bjatkin/a", Kind: backendGitlab},
+		{Repo: "bjatkin/b", Kind: backendGitlab},
+	}}
+
+	kinds := backendKinds(cfg)
+	if len(kinds) != 2 || kinds[0] != backendGithub || kinds[1] != backendGitlab {
+		t.Errorf("backendKinds() = %v, want [%q %q]", kinds, backendGithub, backendGitlab)
+	}
+}
+
+func TestSendTelemetryReportPostsJSON(t *testing.T) {
+	var got TelemetryReport
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	report := buildTelemetryReport(Config{}, 42)
+	sendTelemetryReport(srv.URL, report)
+
+	if !reflect.DeepEqual(got, report) {
+		t.Errorf("posted report = %+v, want %+v", got, report)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// trigramIndexDirName is the subdirectory of a localIndex's dataDir that
+// holds one persisted trigramIndex file per repo, mirroring how ".refs"
+// holds per-ref worktrees alongside the main clones.
+const trigramIndexDirName = ".trigram-index"
+
+// trigramIndex maps every 3-byte substring ("trigram") that occurs
+// anywhere in repo's indexed files to the set of files it occurs in, so
+// searchText can narrow a term down to a small candidate set instead of
+// reading every file on every request (zoekt/codesearch's approach).
+// Archive members aren't indexed; matchFile still opens and scans an
+// archive on every search that reaches it, same as before this index
+// existed.
+type trigramIndex struct {
+	Repo     string              `json:"repo"`
+	Postings map[string][]string `json:"postings"` // trigram -> sorted relative paths
+}
+
+// trigramIndexPath returns the on-disk location repo's persisted index is
+// (or will be) written to.
+func (idx *localIndex) trigramIndexPath(repo string) string {
+	return filepath.Join(idx.dataDir, trigramIndexDirName, repo+".json")
+}
+
+// trigramCandidates resolves repo's index (loading it from disk or
+// building it fresh from dir, whichever is available) and returns the
+// set of relative paths whose content could possibly contain term. The
+// second return value is false when the index can't narrow the search
+// at all (term is too short to have a trigram, or the index couldn't be
+// built), in which case the caller should fall back to scanning every
+// file.
+func (idx *localIndex) trigramCandidates(repo, dir, term string) (map[string]bool, bool) {
+	if len(term) < 3 {
+		return nil, false
+	}
+
+	index, err := idx.loadOrBuildTrigramIndex(repo, dir)
+	if err != nil {
+		return nil, false
+	}
+
+	var candidates map[string]bool
+	for trigram := range extractTrigrams(strings.ToLower(term)) {
+		files := index.Postings[trigram]
+		if candidates == nil {
+			candidates = make(map[string]bool, len(files))
+			for _, f := range files {
+				candidates[f] = true
+			}
+			continue
+		}
+		next := make(map[string]bool, len(candidates))
+		for _, f := range files {
+			if candidates[f] {
+				next[f] = true
+			}
+		}
+		candidates = next
+	}
+	return candidates, true
+}
+
+// loadOrBuildTrigramIndex returns repo's trigram index, from the
+// in-memory cache if present, else from disk, else by building it fresh
+// from dir and persisting the result so the next search (or the next
+// process's startup, see loadPersistedTrigramIndexes) can reuse it.
+func (idx *localIndex) loadOrBuildTrigramIndex(repo, dir string) (*trigramIndex, error) {
+	idx.trigramMu.Lock()
+	if cached, ok := idx.trigramIndexes[repo]; ok {
+		idx.trigramMu.Unlock()
+		return cached, nil
+	}
+	idx.trigramMu.Unlock()
+
+	if index, err := idx.readTrigramIndex(repo); err == nil {
+		idx.trigramMu.Lock()
+		idx.trigramIndexes[repo] = index
+		idx.trigramMu.Unlock()
+		return index, nil
+	}
+
+	index, err := buildTrigramIndex(repo, dir, idx.archiveExtensions)
+	if err != nil {
+		return nil, err
+	}
+	if err := idx.writeTrigramIndex(index); err != nil {
+		return nil, err
+	}
+
+	idx.trigramMu.Lock()
+	idx.trigramIndexes[repo] = index
+	idx.trigramMu.Unlock()
+	return index, nil
+}
+
+// loadPersistedTrigramIndexes reads every trigram index already on disk
+// under dataDir into the in-memory cache, so a restarted process serves
+// fast local searches immediately instead of rebuilding every repo's
+// index on its first query. Unreadable entries are skipped rather than
+// failing startup; a skipped repo's index is simply rebuilt on its next
+// search.
+func (idx *localIndex) loadPersistedTrigramIndexes() {
+	root := filepath.Join(idx.dataDir, trigramIndexDirName)
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		repo := strings.TrimSuffix(filepath.ToSlash(rel), ".json")
+
+		index, err := idx.readTrigramIndex(repo)
+		if err != nil {
+			return nil
+		}
+		idx.trigramMu.Lock()
+		idx.trigramIndexes[repo] = index
+		idx.trigramMu.Unlock()
+		return nil
+	})
+}
+
+// readTrigramIndex loads repo's persisted index from disk without
+// touching the in-memory cache.
+func (idx *localIndex) readTrigramIndex(repo string) (*trigramIndex, error) {
+	data, err := os.ReadFile(idx.trigramIndexPath(repo))
+	if err != nil {
+		return nil, err
+	}
+	var index trigramIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// writeTrigramIndex persists index to disk, creating its parent
+// directory as needed.
+func (idx *localIndex) writeTrigramIndex(index *trigramIndex) error {
+	path := idx.trigramIndexPath(index.Repo)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// buildTrigramIndex walks dir (a working tree of repo) and computes a
+// fresh trigramIndex from its plain-text files, skipping binaries and
+// archive members exactly like searchDirText does.
+func buildTrigramIndex(repo, dir string, archiveExtensions []string) (*trigramIndex, error) {
+	postings := map[string]map[string]bool{}
+	seen := newCaseFolder()
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if seen.collides(rel) || isArchive(rel, archiveExtensions) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(data, 0) != -1 {
+			return nil // unreadable or binary
+		}
+
+		for trigram := range extractTrigrams(strings.ToLower(string(data))) {
+			files, ok := postings[trigram]
+			if !ok {
+				files = map[string]bool{}
+				postings[trigram] = files
+			}
+			files[rel] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make(map[string][]string, len(postings))
+	for trigram, files := range postings {
+		list := make([]string, 0, len(files))
+		for f := range files {
+			list = append(list, f)
+		}
+		sort.Strings(list)
+		sorted[trigram] = list
+	}
+
+	return &trigramIndex{Repo: repo, Postings: sorted}, nil
+}
+
+// extractTrigrams returns the set of every 3-byte substring of s.
+// Shorter than 3 bytes, s has none.
+func extractTrigrams(s string) map[string]bool {
+	trigrams := map[string]bool{}
+	for i := 0; i+3 <= len(s); i++ {
+		trigrams[s[i:i+3]] = true
+	}
+	return trigrams
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractTrigrams(t *testing.T) {
+	got := extractTrigrams("abcd")
+	want := []string{"abc", "bcd"}
+	for _, tri := range want {
+		if !got[tri] {
+			t.Errorf("expected trigram %q in %v", tri, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("len(got) = %d, want %d", len(got), len(want))
+	}
+}
+
+func TestExtractTrigramsTooShort(t *testing.T) {
+	if got := extractTrigrams("ab"); len(got) != 0 {
+		t.Errorf("expected no trigrams for a 2-byte string, got %v", got)
+	}
+}
+
+func TestBuildTrigramIndexFindsFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("hello world"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := buildTrigramIndex("owner/repo", dir, defaultArchiveExtensions)
+	if err != nil {
+		t.Fatalf("buildTrigramIndex: %v", err)
+	}
+	if files := index.Postings["hel"]; len(files) != 1 || files[0] != "main.go" {
+		t.Errorf("Postings[\"hel\"] = %v, want [main.go]", files)
+	}
+	if files := index.Postings["xyz"]; len(files) != 0 {
+		t.Errorf("Postings[\"xyz\"] = %v, want none", files)
+	}
+}
+
+func TestTrigramIndexPersistsAndReloads(t *testing.T) {
+	dataDir := t.TempDir()
+	repoDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("hello world"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	index, err := idx.loadOrBuildTrigramIndex("owner/repo", repoDir)
+	if err != nil {
+		t.Fatalf("loadOrBuildTrigramIndex: %v", err)
+	}
+	if len(index.Postings["hel"]) != 1 {
+		t.Fatalf("expected the freshly built index to have a posting for \"hel\"")
+	}
+
+	reloaded := newLocalIndex(dataDir)
+	if len(reloaded.trigramIndexes) != 1 {
+		t.Fatalf("expected loadPersistedTrigramIndexes to restore 1 index, got %d", len(reloaded.trigramIndexes))
+	}
+	if files := reloaded.trigramIndexes["owner/repo"].Postings["hel"]; len(files) != 1 {
+		t.Errorf("reloaded index missing posting for \"hel\": %v", files)
+	}
+}
+
+func TestTrigramCandidatesNarrowsToMatchingFiles(t *testing.T) {
+	dataDir := t.TempDir()
+	repoDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoDir, "match.go"), []byte("needle in a haystack"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "nomatch.go"), []byte("nothing interesting here"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newLocalIndex(dataDir)
+	candidates, ok := idx.trigramCandidates("owner/repo", repoDir, "needle")
+	if !ok {
+		t.Fatal("expected trigramCandidates to narrow the search")
+	}
+	if !candidates["match.go"] {
+		t.Errorf("expected match.go to be a candidate: %v", candidates)
+	}
+	if candidates["nomatch.go"] {
+		t.Errorf("did not expect nomatch.go to be a candidate: %v", candidates)
+	}
+}
+
+func TestTrigramCandidatesFallsBackForShortTerm(t *testing.T) {
+	idx := newLocalIndex(t.TempDir())
+	if _, ok := idx.trigramCandidates("owner/repo", t.TempDir(), "ab"); ok {
+		t.Error("expected a 2-character term to fall back to a full scan")
+	}
+}
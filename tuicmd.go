@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// tuiPerPage bounds how many results a single tui query prints, so a
+// broad query doesn't scroll the whole screen off.
+const tuiPerPage = 10
+
+// runTUI is the "git_search tui <config_file>" subcommand: a standalone
+// terminal REPL over the same searcher the HTTP server uses, for
+// running searches without a browser or a running service. It has no
+// key-driven, redrawing screen (this repo has no TUI framework
+// dependency available to build one on) - each query prints a numbered
+// results list with a snippet preview, and entering a number opens that
+// result in $EDITOR (or prints its URL if $EDITOR is unset).
+func runTUI(args []string) {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "usage: git_search tui <config_file>")
+		os.Exit(1)
+	}
+
+	cfg, err := LoadConfig(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	s, err := newSearcher(cfg, nil, nil, nil)
+	if err != nil {
+		log.Fatalf("init searcher: %v", err)
+	}
+
+	runTUILoop(os.Stdin, os.Stdout, s)
+}
+
+// tuiSearcher is the subset of *searcher runTUILoop needs, so tests can
+// drive it against a stub instead of a fully wired backend searcher.
+type tuiSearcher interface {
+	Search(term string, contextLines int, explain bool, page, perPage int, apiKey, group string, excludeForks, excludeArchived bool, lang, pathFilter string) ([]Result, []RepoRename, SearchPage, SearchCost, error)
+}
+
+// runTUILoop drives the query/preview/open cycle against in and out, so
+// it can be exercised in tests without a real terminal.
+func runTUILoop(in io.Reader, out io.Writer, s tuiSearcher) {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "query> ")
+		if !scanner.Scan() {
+			return
+		}
+		term := strings.TrimSpace(scanner.Text())
+		if term == "" {
+			continue
+		}
+		if term == ":q" || term == ":quit" {
+			return
+		}
+
+		results, _, page, _, err := s.Search(term, 2, false, 1, tuiPerPage, "", "", false, false, "", "")
+		if err != nil {
+			fmt.Fprintf(out, "search failed: %v\n", err)
+			continue
+		}
+		if len(results) == 0 {
+			fmt.Fprintln(out, "no matches")
+			continue
+		}
+		for i, r := range results {
+			fmt.Fprintf(out, "[%d] %s %s\n", i+1, r.Repo, r.Path)
+			for _, line := range r.Lines {
+				fmt.Fprintf(out, "      %d: %s\n", line.Number, line.Text)
+			}
+		}
+		fmt.Fprintf(out, "%d of %d result(s)\n", len(results), page.TotalCount)
+
+		fmt.Fprint(out, "open # (or press enter to search again)> ")
+		if !scanner.Scan() {
+			return
+		}
+		choice := strings.TrimSpace(scanner.Text())
+		if choice == "" {
+			continue
+		}
+		n, err := strconv.Atoi(choice)
+		if err != nil || n < 1 || n > len(results) {
+			fmt.Fprintln(out, "not a valid result number")
+			continue
+		}
+		openTUIResult(out, results[n-1])
+	}
+}
+
+// openTUIResult opens result's file in $EDITOR, or prints its URL when
+// $EDITOR is unset (e.g. running against a remote-only backend where
+// Path isn't a local file at all).
+func openTUIResult(out io.Writer, result Result) {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		fmt.Fprintln(out, result.URL)
+		return
+	}
+
+	cmd := exec.Command(editor, result.Path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(out, "open in editor: %v\n", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+type stubTUISearcher struct {
+	results []Result
+}
+
+func (s stubTUISearcher) Search(term string, contextLines int, explain bool, page, perPage int, apiKey, group string, excludeForks, excludeArchived bool, lang, pathFilter string) ([]Result, []RepoRename, SearchPage, SearchCost, error) {
+	if term == "nothing" {
+		return nil, nil, SearchPage{}, SearchCost{}, nil
+	}
+	return s.results, nil, SearchPage{TotalCount: len(s.results)}, SearchCost{}, nil
+}
+
+func TestRunTUILoopPrintsResultsAndURLOnOpen(t *testing.T) {
+	s := stubTUISearcher{results: []Result{
+		{Repo: "owner/repo", Path: "main.go", URL: "https://example.com/main.go", Lines: []Line{{Number: 3, Text: "func main() {"}}},
+	}}
+
+	in := strings.NewReader("main\n1\n:q\n")
+	var out strings.Builder
+	runTUILoop(in, &out, s)
+
+	got := out.String()
+	if !strings.Contains(got, "owner/repo main.go") {
+		t.Errorf("output missing result line: %s", got)
+	}
+	if !strings.Contains(got, "func main() {") {
+		t.Errorf("output missing snippet: %s", got)
+	}
+	if !strings.Contains(got, "https://example.com/main.go") {
+		t.Errorf("output missing opened URL (EDITOR unset): %s", got)
+	}
+}
+
+func TestRunTUILoopReportsNoMatches(t *testing.T) {
+	s := stubTUISearcher{}
+	in := strings.NewReader("nothing\n:q\n")
+	var out strings.Builder
+	runTUILoop(in, &out, s)
+
+	if !strings.Contains(out.String(), "no matches") {
+		t.Errorf("expected a no matches message, got: %s", out.String())
+	}
+}
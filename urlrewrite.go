@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// URLRewriteRule rewrites a result's URL, e.g. to point at an internal
+// GitHub Enterprise web host or a code-review tool instead of
+// github.com.
+type URLRewriteRule struct {
+	// Match is a regexp tested against the result URL.
+	Match string `yaml:"match"`
+
+	// Replace is the replacement text, applied with
+	// regexp.ReplaceAllString so it may reference capture groups from
+	// Match (e.g. "$1").
+	Replace string `yaml:"replace"`
+}
+
+// compiledURLRewrite is a URLRewriteRule with its Match pattern
+// precompiled, so rewriting doesn't re-parse the pattern per result.
+type compiledURLRewrite struct {
+	re      *regexp.Regexp
+	replace string
+}
+
+// compileURLRewriteRules validates and precompiles rules, returning a
+// clear error at startup if any pattern is invalid rather than failing
+// silently mid-search.
+func compileURLRewriteRules(rules []URLRewriteRule) ([]compiledURLRewrite, error) {
+	var compiled []compiledURLRewrite
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url_rewrite_rules match %q: %w", rule.Match, err)
+		}
+		compiled = append(compiled, compiledURLRewrite{re: re, replace: rule.Replace})
+	}
+	return compiled, nil
+}
+
+// rewriteURL applies rules to url in order, so a later rule can further
+// rewrite an earlier rule's output.
+func rewriteURL(url string, rules []compiledURLRewrite) string {
+	for _, rule := range rules {
+		url = rule.re.ReplaceAllString(url, rule.replace)
+	}
+	return url
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestRewriteURLAppliesRule(t *testing.T) {
+	rules, err := compileURLRewriteRules([]URLRewriteRule{
+		{Match: `^https://github\.com/(.+)$`, Replace: "https://github.internal.example.com/$1"},
+	})
+	if err != nil {
+		t.Fatalf("compileURLRewriteRules: %v", err)
+	}
+
+	got := rewriteURL("https://github.com/bjatkin/golf-engine/blob/main/main.go", rules)
+	want := "https://github.internal.example.com/bjatkin/golf-engine/blob/main/main.go"
+	if got != want {
+		t.Errorf("rewriteURL = %q, want %q", got, want)
+	}
+}
+
+func TestRewriteURLNoRulesReturnsInput(t *testing.T) {
+	got := rewriteURL("https://github.com/bjatkin/golf-engine", nil)
+	if got != "https://github.com/bjatkin/golf-engine" {
+		t.Errorf("rewriteURL = %q, want input unchanged", got)
+	}
+}
+
+func TestCompileURLRewriteRulesRejectsInvalidPattern(t *testing.T) {
+	_, err := compileURLRewriteRules([]URLRewriteRule{{Match: "("}})
+	if err == nil {
+		t.Fatal("expected an error for an invalid regexp")
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// apiKeyHeader and apiKeyParam are where a caller identifies itself for
+// usage tracking. This service has no separate authentication of its
+// own for these keys (see PublicAPI, SlackSigningSecret for the actual
+// auth it does enforce) — an API key here is purely a caller-supplied
+// label for chargeback/showback, not a credential.
+const (
+	apiKeyHeader = "X-API-Key"
+	apiKeyParam  = "api_key"
+)
+
+// apiKeyFromRequest returns the caller-supplied API key for r, or "" if
+// none was sent, which usageTracker groups together as unlabeled usage.
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	return r.URL.Query().Get(apiKeyParam)
+}
+
+// usageCount accumulates one API key's activity for a single day.
+type usageCount struct {
+	Searches   int `json:"searches"`
+	QuotaSpent int `json:"quota_spent"`
+}
+
+// usageTracker records search volume and GitHub rate-limit consumption
+// per API key, bucketed by UTC day, so /admin/usage can report
+// daily/monthly totals and the top consumers for chargeback/showback in
+// a shared deployment. It's in-memory only and resets on restart; it
+// isn't part of the /admin/export state bundle, since usage history is
+// informational rather than state a restart should have to restore.
+type usageTracker struct {
+	mu            sync.Mutex
+	byDay         map[string]map[string]usageCount
+	retentionDays int
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{byDay: map[string]map[string]usageCount{}}
+}
+
+// Reconfigure applies cfg.Retention.UsageDays, pruning anything already
+// past the new retention window immediately. It's called on every config
+// reload, same as abExperiment.Reconfigure, so a tightened retention
+// setting takes effect without a restart.
+func (u *usageTracker) Reconfigure(cfg Config) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.retentionDays = cfg.Retention.UsageDays
+	u.pruneExpiredLocked()
+}
+
+// Record counts one search against apiKey for the current UTC day,
+// adding quotaSpent (e.g. SearchCost.GithubRateLimitSpent) to its
+// running quota consumption.
+func (u *usageTracker) Record(apiKey string, quotaSpent int) {
+	day := time.Now().UTC().Format("2006-01-02")
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pruneExpiredLocked()
+	if u.byDay[day] == nil {
+		u.byDay[day] = map[string]usageCount{}
+	}
+	c := u.byDay[day][apiKey]
+	c.Searches++
+	c.QuotaSpent += quotaSpent
+	u.byDay[day][apiKey] = c
+}
+
+// pruneExpiredLocked drops every day older than retentionDays, relative
+// to the current UTC day. The caller must hold u.mu. A non-positive
+// retentionDays leaves history untouched.
+func (u *usageTracker) pruneExpiredLocked() {
+	if u.retentionDays <= 0 {
+		return
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -u.retentionDays).Format("2006-01-02")
+	for day := range u.byDay {
+		if day < cutoff {
+			delete(u.byDay, day)
+		}
+	}
+}
+
+// Purge deletes usage history on demand: apiKey, if non-empty, restricts
+// the purge to that key; before, if non-zero, restricts it to days
+// strictly before that time. At least one must be set. It returns how
+// many (day, apiKey) entries were removed.
+func (u *usageTracker) Purge(apiKey string, before time.Time) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var beforeDay string
+	if !before.IsZero() {
+		beforeDay = before.UTC().Format("2006-01-02")
+	}
+
+	var purged int
+	for day, byKey := range u.byDay {
+		if beforeDay != "" && day >= beforeDay {
+			continue
+		}
+		if apiKey == "" {
+			purged += len(byKey)
+			delete(u.byDay, day)
+			continue
+		}
+		if _, ok := byKey[apiKey]; ok {
+			delete(byKey, apiKey)
+			purged++
+			if len(byKey) == 0 {
+				delete(u.byDay, day)
+			}
+		}
+	}
+	return purged
+}
+
+// DailyUsage is one day's totals across every API key.
+type DailyUsage struct {
+	Day        string `json:"day"`
+	Searches   int    `json:"searches"`
+	QuotaSpent int    `json:"quota_spent"`
+}
+
+// MonthlyUsage is one calendar month's totals, folded from its DailyUsage
+// entries.
+type MonthlyUsage struct {
+	Month      string `json:"month"`
+	Searches   int    `json:"searches"`
+	QuotaSpent int    `json:"quota_spent"`
+}
+
+// KeyUsage is one API key's all-time totals. APIKey is "" for callers
+// that never sent one.
+type KeyUsage struct {
+	APIKey     string `json:"api_key"`
+	Searches   int    `json:"searches"`
+	QuotaSpent int    `json:"quota_spent"`
+}
+
+// UsageReport is the /admin/usage response body.
+type UsageReport struct {
+	Daily   []DailyUsage   `json:"daily"`
+	Monthly []MonthlyUsage `json:"monthly"`
+	TopKeys []KeyUsage     `json:"top_keys"`
+}
+
+// Report builds a UsageReport of everything recorded so far. Daily and
+// Monthly are sorted oldest first; TopKeys is sorted by Searches
+// descending (ties broken by APIKey) so the biggest consumers sort to
+// the top.
+func (u *usageTracker) Report() UsageReport {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pruneExpiredLocked()
+
+	var report UsageReport
+	monthTotals := map[string]MonthlyUsage{}
+	keyTotals := map[string]KeyUsage{}
+
+	for day, byKey := range u.byDay {
+		var dayTotal DailyUsage
+		dayTotal.Day = day
+		for apiKey, c := range byKey {
+			dayTotal.Searches += c.Searches
+			dayTotal.QuotaSpent += c.QuotaSpent
+
+			total := keyTotals[apiKey]
+			total.APIKey = apiKey
+			total.Searches += c.Searches
+			total.QuotaSpent += c.QuotaSpent
+			keyTotals[apiKey] = total
+		}
+		report.Daily = append(report.Daily, dayTotal)
+
+		month := monthTotals[day[:7]]
+		month.Month = day[:7]
+		month.Searches += dayTotal.Searches
+		month.QuotaSpent += dayTotal.QuotaSpent
+		monthTotals[day[:7]] = month
+	}
+
+	for _, month := range monthTotals {
+		report.Monthly = append(report.Monthly, month)
+	}
+	for _, key := range keyTotals {
+		report.TopKeys = append(report.TopKeys, key)
+	}
+
+	sort.Slice(report.Daily, func(i, j int) bool { return report.Daily[i].Day < report.Daily[j].Day })
+	sort.Slice(report.Monthly, func(i, j int) bool { return report.Monthly[i].Month < report.Monthly[j].Month })
+	sort.Slice(report.TopKeys, func(i, j int) bool {
+		if report.TopKeys[i].Searches != report.TopKeys[j].Searches {
+			return report.TopKeys[i].Searches > report.TopKeys[j].Searches
+		}
+		return report.TopKeys[i].APIKey < report.TopKeys[j].APIKey
+	})
+
+	return report
+}
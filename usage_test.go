@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUsageTrackerReportAggregatesAcrossKeys(t *testing.T) {
+	u := newUsageTracker()
+	u.Record("team-a", 2)
+	u.Record("team-a", 3)
+	u.Record("team-b", 0)
+	u.Record("", 1)
+
+	report := u.Report()
+
+	if len(report.Daily) != 1 {
+		t.Fatalf("Daily = %+v, want a single entry for today", report.Daily)
+	}
+	if report.Daily[0].Searches != 4 || report.Daily[0].QuotaSpent != 6 {
+		t.Errorf("Daily[0] = %+v, want Searches=4 QuotaSpent=6", report.Daily[0])
+	}
+
+	if len(report.Monthly) != 1 || report.Monthly[0].Searches != 4 {
+		t.Errorf("Monthly = %+v, want a single entry with Searches=4", report.Monthly)
+	}
+
+	if len(report.TopKeys) != 3 {
+		t.Fatalf("TopKeys = %+v, want 3 distinct keys", report.TopKeys)
+	}
+	if report.TopKeys[0].APIKey != "team-a" || report.TopKeys[0].Searches != 2 || report.TopKeys[0].QuotaSpent != 5 {
+		t.Errorf("TopKeys[0] = %+v, want the busiest key (team-a) first", report.TopKeys[0])
+	}
+}
+
+func TestUsageTrackerPurgeByAPIKey(t *testing.T) {
+	u := newUsageTracker()
+	u.Record("team-a", 1)
+	u.Record("team-b", 1)
+
+	purged := u.Purge("team-a", time.Time{})
+	if purged != 1 {
+		t.Fatalf("Purge = %d, want 1", purged)
+	}
+
+	report := u.Report()
+	if len(report.TopKeys) != 1 || report.TopKeys[0].APIKey != "team-b" {
+		t.Errorf("TopKeys = %+v, want only team-b left", report.TopKeys)
+	}
+}
+
+func TestUsageTrackerPurgeBeforeCutoff(t *testing.T) {
+	u := newUsageTracker()
+	u.byDay["2020-01-01"] = map[string]usageCount{"team-a": {Searches: 1}}
+	u.Record("team-a", 0) // today
+
+	purged := u.Purge("", time.Now().UTC().AddDate(0, 0, -1))
+	if purged != 1 {
+		t.Fatalf("Purge = %d, want 1", purged)
+	}
+	if _, ok := u.byDay["2020-01-01"]; ok {
+		t.Error("expected the old day to be purged")
+	}
+	if len(u.byDay) != 1 {
+		t.Errorf("byDay = %+v, want today's entry left untouched", u.byDay)
+	}
+}
+
+func TestUsageTrackerReconfigurePrunesPastRetention(t *testing.T) {
+	u := newUsageTracker()
+	u.byDay["2020-01-01"] = map[string]usageCount{"team-a": {Searches: 1}}
+	u.Record("team-a", 0) // today
+
+	u.Reconfigure(Config{Retention: RetentionConfig{UsageDays: 7}})
+
+	if _, ok := u.byDay["2020-01-01"]; ok {
+		t.Error("expected the stale day to be pruned once retention is configured")
+	}
+	if len(u.byDay) != 1 {
+		t.Errorf("byDay = %+v, want today's entry left untouched", u.byDay)
+	}
+}
+
+func TestApiKeyFromRequestPrefersHeaderOverQueryParam(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/search?q=x&api_key=from-query", nil)
+	req.Header.Set(apiKeyHeader, "from-header")
+
+	if got := apiKeyFromRequest(req); got != "from-header" {
+		t.Errorf("apiKeyFromRequest() = %q, want %q", got, "from-header")
+	}
+}
+
+func TestApiKeyFromRequestFallsBackToQueryParam(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/search?q=x&api_key=from-query", nil)
+
+	if got := apiKeyFromRequest(req); got != "from-query" {
+		t.Errorf("apiKeyFromRequest() = %q, want %q", got, "from-query")
+	}
+}
+
+func TestApiKeyFromRequestEmptyWhenNeitherSent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/search?q=x", nil)
+
+	if got := apiKeyFromRequest(req); got != "" {
+		t.Errorf("apiKeyFromRequest() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSearchTermLen bounds how large a query term the service will
+// forward to a backend, well under GitHub's own query length limit.
+const maxSearchTermLen = 256
+
+// defaultSearchPerPage and maxSearchPerPage mirror GitHub's own code
+// search defaults and limits: 30 results per page unless the caller asks
+// for more, capped at 100.
+const (
+	defaultSearchPerPage = 30
+	maxSearchPerPage     = 100
+)
+
+// validateSearchTerm rejects malformed or abusive query input before it
+// is ever built into an outbound backend request, so we return a 400
+// instead of forwarding garbage (or oversized payloads) upstream.
+func validateSearchTerm(term string) error {
+	if term == "" {
+		return fmt.Errorf("search term must not be empty")
+	}
+	if len(term) > maxSearchTermLen {
+		return fmt.Errorf("search term exceeds maximum length of %d bytes", maxSearchTermLen)
+	}
+	if !utf8.ValidString(term) {
+		return fmt.Errorf("search term must be valid UTF-8")
+	}
+	for _, r := range term {
+		if r == '\n' || r == '\r' || r == 0 {
+			return fmt.Errorf("search term must not contain control characters")
+		}
+	}
+	return nil
+}
+
+// validateSearchPage rejects out-of-range page and per_page values before
+// they're forwarded to a backend's code search API.
+func validateSearchPage(page, perPage int) error {
+	if page < 1 {
+		return fmt.Errorf("page must be 1 or greater")
+	}
+	if perPage < 1 || perPage > maxSearchPerPage {
+		return fmt.Errorf("per_page must be between 1 and %d", maxSearchPerPage)
+	}
+	return nil
+}
+
+// validateRepoName rejects anything that isn't a plausible "owner/repo"
+// name before it's added to the configured repo list, e.g. from a bulk
+// import file with malformed or empty rows.
+func validateRepoName(repo string) error {
+	if repo == "" {
+		return fmt.Errorf("repo must not be empty")
+	}
+	if !utf8.ValidString(repo) {
+		return fmt.Errorf("repo must be valid UTF-8")
+	}
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("repo %q must be in owner/name form", repo)
+	}
+	return nil
+}
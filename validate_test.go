@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestValidateSearchTerm(t *testing.T) {
+	cases := []struct {
+		term    string
+		wantErr bool
+	}{
+		{"foo", false},
+		{"", true},
+		{string(make([]byte, maxSearchTermLen+1)), true},
+		{"foo\nbar", true},
+		{"foo\x00bar", true},
+	}
+
+	for _, c := range cases {
+		err := validateSearchTerm(c.term)
+		if (err != nil) != c.wantErr {
+			t.Errorf("validateSearchTerm(%q) error = %v, wantErr %v", c.term, err, c.wantErr)
+		}
+	}
+}
+
+func TestValidateSearchPage(t *testing.T) {
+	cases := []struct {
+		page, perPage int
+		wantErr       bool
+	}{
+		{1, 30, false},
+		{1, maxSearchPerPage, false},
+		{0, 30, true},
+		{-1, 30, true},
+		{1, 0, true},
+		{1, maxSearchPerPage + 1, true},
+	}
+
+	for _, c := range cases {
+		err := validateSearchPage(c.page, c.perPage)
+		if (err != nil) != c.wantErr {
+			t.Errorf("validateSearchPage(%d, %d) error = %v, wantErr %v", c.page, c.perPage, err, c.wantErr)
+		}
+	}
+}
+
+func TestValidateRepoName(t *testing.T) {
+	cases := []struct {
+		repo    string
+		wantErr bool
+	}{
+		{"bjatkin/gitSearch", false},
+		{"", true},
+		{"gitSearch", true},
+		{"/gitSearch", true},
+		{"bjatkin/", true},
+		{"bjatkin/nested/repo", true},
+	}
+
+	for _, c := range cases {
+		err := validateRepoName(c.repo)
+		if (err != nil) != c.wantErr {
+			t.Errorf("validateRepoName(%q) error = %v, wantErr %v", c.repo, err, c.wantErr)
+		}
+	}
+}
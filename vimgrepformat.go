@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// writeVimgrepFormat writes results as grep/vimgrep-style lines
+// ("file:line:col:text"), one per matched line, so the output can be
+// loaded straight into Vim's quickfix list (:cgetexpr or piped through
+// `vim -q`) or any other tool expecting grep's own output format.
+// Column is 1 unless the line has a recorded match offset, in which
+// case it's that offset's first matched rune, 1-indexed.
+func writeVimgrepFormat(w io.Writer, results []Result) {
+	for _, result := range results {
+		for _, line := range result.Lines {
+			col := 1
+			if len(line.Offsets) > 0 {
+				col = line.Offsets[0].RuneStart + 1
+			}
+			fmt.Fprintf(w, "%s:%d:%d:%s\n", result.Path, line.Number, col, line.Text)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteVimgrepFormat(t *testing.T) {
+	results := []Result{
+		{
+			Path: "src/main.go",
+			Lines: []Line{
+				{Number: 10, Text: "needle here", Offsets: []Offset{{RuneStart: 0}}},
+				{Number: 20, Text: "another needle"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	writeVimgrepFormat(&buf, results)
+
+	out := buf.String()
+	if !strings.Contains(out, "src/main.go:10:1:needle here") {
+		t.Errorf("output missing expected line for the offset match, got: %s", out)
+	}
+	if !strings.Contains(out, "src/main.go:20:1:another needle") {
+		t.Errorf("output missing expected line for the no-offset default column, got: %s", out)
+	}
+}
+
+func TestWriteVimgrepFormatEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	writeVimgrepFormat(&buf, nil)
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output for no results, got: %s", buf.String())
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// VSCodeResult is one search result shaped for a thin VS Code extension:
+// FileURI, when set, points at the file inside the caller's local
+// checkout instead of the remote GitHub URL, so the extension can open it
+// directly with vscode.open rather than a browser.
+type VSCodeResult struct {
+	Repo    string `json:"repo"`
+	Path    string `json:"path"`
+	URL     string `json:"url"`
+	FileURI string `json:"file_uri,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// parseWorkspaceMapping parses repeated workspace=owner/repo=/local/path
+// query parameter values into a repo -> local folder map: the client's
+// declaration of where each configured repo is checked out locally.
+func parseWorkspaceMapping(values []string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, v := range values {
+		repo, folder, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid workspace mapping %q: want repo=/local/path", v)
+		}
+		mapping[repo] = folder
+	}
+	return mapping, nil
+}
+
+// buildVSCodeResults converts results into the shape a VS Code extension
+// expects, resolving each result's repo/path into a file:// URI under its
+// mapped local workspace folder. A result whose repo has no entry in
+// workspace keeps FileURI empty, so the extension falls back to URL.
+func buildVSCodeResults(results []Result, workspace map[string]string) []VSCodeResult {
+	var out []VSCodeResult
+	for _, r := range results {
+		vr := VSCodeResult{Repo: r.Repo, Path: r.Path, URL: r.URL}
+		if len(r.Lines) > 0 {
+			vr.Line = r.Lines[0].Number
+		}
+		if folder, ok := workspace[r.Repo]; ok {
+			vr.FileURI = (&url.URL{Scheme: "file", Path: path.Join(folder, r.Path)}).String()
+		}
+		out = append(out, vr)
+	}
+	return out
+}
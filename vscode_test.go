@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestParseWorkspaceMapping(t *testing.T) {
+	mapping, err := parseWorkspaceMapping([]string{"bjatkin/golf-engine=/home/dev/golf-engine"})
+	if err != nil {
+		t.Fatalf("parseWorkspaceMapping: %v", err)
+	}
+	if mapping["bjatkin/golf-engine"] != "/home/dev/golf-engine" {
+		t.Errorf("mapping = %+v, want bjatkin/golf-engine -> /home/dev/golf-engine", mapping)
+	}
+}
+
+func TestParseWorkspaceMappingInvalid(t *testing.T) {
+	if _, err := parseWorkspaceMapping([]string{"bjatkin/golf-engine"}); err == nil {
+		t.Error("expected an error for a mapping missing '='")
+	}
+}
+
+func TestBuildVSCodeResultsResolvesFileURI(t *testing.T) {
+	results := []Result{
+		{Repo: "bjatkin/golf-engine", Path: "main.go", URL: "https://github.com/bjatkin/golf-engine/blob/main/main.go", Lines: []Line{{Number: 12}}},
+	}
+	workspace := map[string]string{"bjatkin/golf-engine": "/home/dev/golf-engine"}
+
+	out := buildVSCodeResults(results, workspace)
+	if len(out) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(out))
+	}
+	if want := "file:///home/dev/golf-engine/main.go"; out[0].FileURI != want {
+		t.Errorf("FileURI = %q, want %q", out[0].FileURI, want)
+	}
+	if out[0].Line != 12 {
+		t.Errorf("Line = %d, want 12", out[0].Line)
+	}
+}
+
+func TestBuildVSCodeResultsLeavesFileURIEmptyForUnmappedRepo(t *testing.T) {
+	results := []Result{{Repo: "bjatkin/other", Path: "a.go", URL: "https://github.com/bjatkin/other/blob/main/a.go"}}
+
+	out := buildVSCodeResults(results, map[string]string{})
+	if out[0].FileURI != "" {
+		t.Errorf("FileURI = %q, want empty for an unmapped repo", out[0].FileURI)
+	}
+}
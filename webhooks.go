@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// verifyGithubWebhookSignature checks a GitHub webhook delivery against
+// its X-Hub-Signature-256 header, which GitHub computes as
+// "sha256=" + hex(HMAC-SHA256(secret, body)):
+// https://docs.github.com/webhooks/using-webhooks/validating-webhook-deliveries
+func verifyGithubWebhookSignature(secret, signature string, body []byte) error {
+	const prefix = "sha256="
+	if !strings.HasPrefix(signature, prefix) {
+		return fmt.Errorf("missing or malformed X-Hub-Signature-256 header")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	want := prefix + hex.EncodeToString(mac.Sum(nil))
+
+	if subtle.ConstantTimeCompare([]byte(want), []byte(signature)) != 1 {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// WebhookDelivery is one webhook payload the service has received, kept
+// around so a missed or failed delivery can be replayed into the index
+// pipeline without waiting for the sender to retry it.
+type WebhookDelivery struct {
+	ID         string          `json:"id"`
+	Repo       string          `json:"repo"`
+	ReceivedAt time.Time       `json:"received_at"`
+	Payload    json.RawMessage `json:"payload"`
+	Processed  bool            `json:"processed"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// webhookInbox records every webhook delivery received, in order, so
+// operators can inspect and replay them from /admin/webhooks.
+type webhookInbox struct {
+	mu         sync.Mutex
+	deliveries []*WebhookDelivery
+}
+
+func newWebhookInbox() *webhookInbox {
+	return &webhookInbox{}
+}
+
+// githubPushPayload is the subset of a GitHub push webhook payload the
+// inbox needs to know which repo to index.
+type githubPushPayload struct {
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// Receive records a webhook delivery for repo (parsed from payload) and
+// returns it, unprocessed.
+func (inbox *webhookInbox) Receive(payload []byte) (*WebhookDelivery, error) {
+	var parsed githubPushPayload
+	if err := json.Unmarshal(payload, &parsed); err != nil {
+		return nil, fmt.Errorf("parse webhook payload: %w", err)
+	}
+	if parsed.Repository.FullName == "" {
+		return nil, fmt.Errorf("webhook payload missing repository.full_name")
+	}
+
+	id, err := newWebhookID()
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &WebhookDelivery{
+		ID:         id,
+		Repo:       parsed.Repository.FullName,
+		ReceivedAt: time.Now(),
+		Payload:    json.RawMessage(payload),
+	}
+
+	inbox.mu.Lock()
+	inbox.deliveries = append(inbox.deliveries, delivery)
+	inbox.mu.Unlock()
+
+	return delivery, nil
+}
+
+// List returns every recorded delivery, oldest first.
+func (inbox *webhookInbox) List() []WebhookDelivery {
+	inbox.mu.Lock()
+	defer inbox.mu.Unlock()
+
+	out := make([]WebhookDelivery, len(inbox.deliveries))
+	for i, d := range inbox.deliveries {
+		out[i] = *d
+	}
+	return out
+}
+
+// Get returns the delivery with the given id, if any.
+func (inbox *webhookInbox) Get(id string) (*WebhookDelivery, bool) {
+	inbox.mu.Lock()
+	defer inbox.mu.Unlock()
+
+	for _, d := range inbox.deliveries {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// MarkProcessed records the outcome of indexing a delivery.
+func (inbox *webhookInbox) MarkProcessed(id string, err error) {
+	inbox.mu.Lock()
+	defer inbox.mu.Unlock()
+
+	for _, d := range inbox.deliveries {
+		if d.ID == id {
+			d.Processed = err == nil
+			if err != nil {
+				d.Error = err.Error()
+			} else {
+				d.Error = ""
+			}
+			return
+		}
+	}
+}
+
+// newWebhookID generates a random hex delivery identifier.
+func newWebhookID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate webhook id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestWebhookInboxReceive(t *testing.T) {
+	inbox := newWebhookInbox()
+
+	delivery, err := inbox.Receive([]byte(`{"repository":{"full_name":"owner/repo"}}`))
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if delivery.Repo != "owner/repo" {
+		t.Errorf("Repo = %q, want owner/repo", delivery.Repo)
+	}
+	if delivery.Processed {
+		t.Error("expected a freshly received delivery to be unprocessed")
+	}
+
+	found, ok := inbox.Get(delivery.ID)
+	if !ok || found.ID != delivery.ID {
+		t.Fatal("expected Get to find the received delivery")
+	}
+
+	list := inbox.List()
+	if len(list) != 1 {
+		t.Fatalf("expected 1 recorded delivery, got %d", len(list))
+	}
+}
+
+func TestWebhookInboxReceiveMissingRepo(t *testing.T) {
+	inbox := newWebhookInbox()
+
+	if _, err := inbox.Receive([]byte(`{}`)); err == nil {
+		t.Error("expected an error for a payload missing repository.full_name")
+	}
+}
+
+func TestVerifyGithubWebhookSignatureAcceptsValidSignature(t *testing.T) {
+	secret := "s3cret"
+	body := []byte(`{"repository":{"full_name":"owner/repo"}}`)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if err := verifyGithubWebhookSignature(secret, signature, body); err != nil {
+		t.Fatalf("verifyGithubWebhookSignature: %v", err)
+	}
+}
+
+func TestVerifyGithubWebhookSignatureRejectsBadSignature(t *testing.T) {
+	body := []byte(`{"repository":{"full_name":"owner/repo"}}`)
+	if err := verifyGithubWebhookSignature("s3cret", "sha256=deadbeef", body); err == nil {
+		t.Error("expected an error for a bad signature")
+	}
+}
+
+func TestVerifyGithubWebhookSignatureRejectsMissingPrefix(t *testing.T) {
+	body := []byte(`{"repository":{"full_name":"owner/repo"}}`)
+	if err := verifyGithubWebhookSignature("s3cret", "deadbeef", body); err == nil {
+		t.Error("expected an error for a signature missing the sha256= prefix")
+	}
+}
+
+func TestWebhookInboxMarkProcessed(t *testing.T) {
+	inbox := newWebhookInbox()
+	delivery, err := inbox.Receive([]byte(`{"repository":{"full_name":"owner/repo"}}`))
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	inbox.MarkProcessed(delivery.ID, nil)
+
+	found, _ := inbox.Get(delivery.ID)
+	if !found.Processed {
+		t.Error("expected delivery to be marked processed")
+	}
+	if found.Error != "" {
+		t.Errorf("Error = %q, want empty", found.Error)
+	}
+}